@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/devops-kung-fu/common/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/devops-kung-fu/kissbom/lib"
+	"github.com/devops-kung-fu/kissbom/models"
 )
 
 var (
@@ -17,12 +19,16 @@ var (
 
 	selectedFormat string
 	outputFolder   string
+	canonical      bool
+	strict         bool
+	queryExpr      string
 	convertCmd     = &cobra.Command{
-		Use:   "convert",
-		Short: "Converts a provided CycloneDX file to a KISSBOM format",
+		Use:     "convert",
+		Short:   "Converts the provided CycloneDX or SPDX files, lockfiles, requirements files, Maven dependency lists, Go modules or Go binaries to a KISSBOM format",
+		Example: "  kissbom convert 'sboms/*.cdx.json' app.spdx.json --workers 8",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			if len(args) < 1 {
-				util.PrintErr(errors.New("Please specify a file to convert"))
+				util.PrintErr(errors.New("Please specify the files to convert"))
 				os.Exit(1)
 			}
 		},
@@ -30,16 +36,49 @@ var (
 			converter := lib.NewConverter()
 			converter.OutputFormat = selectedFormat
 			converter.OutputFolder = outputFolder
+			converter.Canonical = canonical
+			converter.Strict = strict
+			applyDependencyFlags(converter)
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+			converter.Workers = workers
+			loadVEX(converter)
+			addEnrichers(converter)
 
-			log.Println("starting conversion")
-			err := converter.Convert(args[0])
-			if err != nil {
-				util.PrintErr(err)
-				os.Exit(1)
+			if queryExpr != "" {
+				query, err := lib.ParseQuery(queryExpr)
+				if err != nil {
+					util.PrintErr(err)
+					os.Exit(1)
+				}
+				converter.Query = query
 			}
 
+			log.Println("starting conversion")
+			conversions := converter.ConvertAll(args)
 			log.Println("finished")
-			util.PrintInfof("Saved KISSBOM as: %v\n", converter.OutputFileName)
+
+			if len(conversions) == 1 {
+				conversion := conversions[0]
+				if conversion.Err != nil {
+					util.PrintErr(conversion.Err)
+					os.Exit(1)
+				}
+				if conversion.Enriched > 0 {
+					util.PrintInfof("Enriched %v packages from local package metadata\n", conversion.Enriched)
+				}
+				printCategorySummary(conversion.Categories)
+				if conversion.Duplicates > 0 {
+					util.PrintInfof("Collapsed %v duplicate packages\n", conversion.Duplicates)
+				}
+				util.PrintInfof("Saved KISSBOM as: %v\n", conversion.OutputFileName)
+				util.PrintSuccess("DONE!")
+				os.Exit(0)
+			}
+
+			if !printConversionSummary(conversions) {
+				os.Exit(1)
+			}
 			util.PrintSuccess("DONE!")
 			os.Exit(0)
 		},
@@ -50,6 +89,43 @@ func init() {
 	rootCmd.AddCommand(convertCmd)
 	convertCmd.Flags().StringVarP(&selectedFormat, "format", "f", "json", fmt.Sprintf("select one of the valid options: %s", outputFormats))
 	convertCmd.Flags().StringVarP(&outputFolder, "output-folder", "o", ".", "the output folder for the converted file")
+	convertCmd.Flags().BoolVar(&canonical, "canonical", false, "sort packages and use canonical encoding so identical content yields identical files")
+	convertCmd.Flags().BoolVar(&strict, "strict", false, "only write the purl, license, copyright and notes fields of the KISSBOM specification")
+	addFilterFlags(convertCmd)
+	addDependencyFlags(convertCmd)
+	addVEXFlag(convertCmd)
+	addEnrichFlags(convertCmd)
+	convertCmd.Flags().IntVar(&workers, "workers", 4, "maximum number of files converted at the same time")
+	convertCmd.Flags().StringVarP(&queryExpr, "query", "q", "", "only keep packages that match this query expression (see: kissbom query --help)")
 	_ = rootCmd.Flags().SetAnnotation("format", cobra.BashCompOneRequiredFlag, []string{"true"})
 
 }
+
+// printConversionSummary prints the outcome of each conversion, then the number of
+// files converted and failed. Returns false if any conversion failed.
+func printConversionSummary(conversions []lib.Conversion) bool {
+	failed := 0
+	for _, conversion := range conversions {
+		if conversion.Err != nil {
+			failed++
+			util.PrintErr(fmt.Errorf("%s: %w", conversion.Filename, conversion.Err))
+			continue
+		}
+		util.PrintSuccessf("%s: saved KISSBOM as: %v\n", conversion.Filename, conversion.OutputFileName)
+	}
+	util.PrintInfof("Converted %v of %v files, %v failed\n", len(conversions)-failed, len(conversions), failed)
+	return failed == 0
+}
+
+// printCategorySummary prints the number of packages in each license category
+func printCategorySummary(categories map[string]int) {
+	summary := []string{}
+	for _, category := range models.LicenseCategories {
+		if categories[category] > 0 {
+			summary = append(summary, fmt.Sprintf("%v %s", categories[category], category))
+		}
+	}
+	if len(summary) > 0 {
+		util.PrintInfof("License categories: %s\n", strings.Join(summary, ", "))
+	}
+}
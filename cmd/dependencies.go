@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	depth      bool
+	directOnly bool
+)
+
+// addDependencyFlags registers the flags that use the dependency graph
+func addDependencyFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&depth, "depth", false, "record the depth of each package in the dependency graph (1 for direct dependencies)")
+	cmd.Flags().BoolVar(&directOnly, "direct", false, "only keep the direct dependencies of the subject")
+}
+
+// applyDependencyFlags configures the converter from the dependency flags
+func applyDependencyFlags(converter *lib.Converter) {
+	converter.Depth = depth
+	converter.DirectOnly = directOnly
+}
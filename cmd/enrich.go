@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	enrichSources        []string
+	enrichURL            string
+	enrichLicenseField   string
+	enrichCopyrightField string
+	enrichCache          string
+	enrichRate           float64
+	enrichConcurrency    int
+)
+
+// addEnrichFlags registers the flags that fill in missing licenses and copyrights
+func addEnrichFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&enrichSources, "enrich", nil, "fill in missing licenses and copyrights from these node_modules, Go module cache, site-packages or Maven repository directories")
+	cmd.Flags().StringVar(&enrichURL, "enrich-url", "", "fill in missing licenses and copyrights from this HTTP service URL template (ex: https://mirror/definitions/{type}/npmjs/{namespace}/{name}/{version})")
+	cmd.Flags().StringVar(&enrichLicenseField, "enrich-license-field", "license", "dot separated path of the license in the HTTP service response")
+	cmd.Flags().StringVar(&enrichCopyrightField, "enrich-copyright-field", "copyright", "dot separated path of the copyright in the HTTP service response")
+	cmd.Flags().StringVar(&enrichCache, "enrich-cache", "", "directory in which HTTP service responses are cached")
+	cmd.Flags().Float64Var(&enrichRate, "enrich-rate", 10, "maximum number of HTTP service requests per second, 0 for unlimited")
+	cmd.Flags().IntVar(&enrichConcurrency, "enrich-concurrency", 4, "maximum number of packages enriched at the same time")
+}
+
+// addEnrichers configures the converter with the enrichers selected by the enrich flags.
+// Local package metadata takes precedence over the HTTP service.
+func addEnrichers(converter *lib.Converter) {
+	converter.Concurrency = enrichConcurrency
+	if len(enrichSources) > 0 {
+		converter.Enrichers = append(converter.Enrichers, lib.NewLocalEnricher(converter.Afs, enrichSources...))
+	}
+	if enrichURL != "" {
+		enricher := lib.NewHTTPEnricher(converter.Afs, enrichURL, enrichRate, enrichConcurrency)
+		enricher.LicenseField = enrichLicenseField
+		enricher.CopyrightField = enrichCopyrightField
+		enricher.CacheDir = enrichCache
+		converter.Enrichers = append(converter.Enrichers, enricher)
+	}
+}
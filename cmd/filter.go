@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+var (
+	filter      models.Filter
+	goBuildList bool
+)
+
+// addFilterFlags registers the flags that determine which packages are kept
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&filter.IncludeTypes, "include-type", nil, "only keep packages with these PURL types (ex: pkg:npm,pkg:pypi)")
+	cmd.Flags().StringSliceVar(&filter.ExcludeScopes, "exclude-scope", nil, "drop components with these scopes, including dev for lockfile development dependencies, Maven scopes and Gradle configurations (ex: optional,excluded,dev,test)")
+	cmd.Flags().StringSliceVar(&filter.ComponentTypes, "component-type", nil, "only keep components of these types (ex: library,framework)")
+	cmd.Flags().StringSliceVar(&filter.Names, "name", nil, "only keep packages whose name matches one of these glob patterns")
+	cmd.Flags().StringSliceVar(&filter.Namespaces, "namespace", nil, "only keep packages whose namespace matches one of these glob patterns")
+	cmd.Flags().BoolVar(&goBuildList, "build-list", false, "only keep the modules of the build list when reading a Go module")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	graphFormat    string
+	graphFile      string
+	graphMaxDepth  int
+	graphHighlight string
+	graphCmd       = &cobra.Command{
+		Use:     "graph",
+		Short:   "Renders the dependency graph of a CycloneDX, SPDX or KISSBOM file as Graphviz DOT or Mermaid",
+		Example: "  kissbom graph test.cyclonedx.json --format mermaid --max-depth 2 --highlight 'category ~ \"copyleft\"'",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				util.PrintErr(errors.New("Please specify a file to graph"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+
+			var highlight *lib.Query
+			if graphHighlight != "" {
+				query, err := lib.ParseQuery(graphHighlight)
+				if err != nil {
+					util.PrintErr(err)
+					os.Exit(1)
+				}
+				highlight = query
+			}
+
+			log.Println("building graph")
+			graph, err := converter.Graph(args[0], graphMaxDepth, highlight)
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+			render := func() ([]byte, error) { return graph.Render(graphFormat) }
+
+			if graphFile == "" {
+				data, err := render()
+				if err != nil {
+					util.PrintErr(err)
+					os.Exit(1)
+				}
+				fmt.Println(string(data))
+			}
+			writeReport(converter.Afs, graphFile, render)
+
+			util.PrintInfof("%v packages and %v dependencies graphed\n", len(graph.Nodes), len(graph.Edges))
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", lib.GraphDOT, fmt.Sprintf("select one of the valid options: %s", lib.GraphFormats))
+	graphCmd.Flags().StringVarP(&graphFile, "output", "o", "", "save the graph to this file instead of printing it")
+	graphCmd.Flags().IntVar(&graphMaxDepth, "max-depth", 0, "leave out packages deeper than this in the dependency graph (0 for no limit)")
+	graphCmd.Flags().StringVar(&graphHighlight, "highlight", "", "highlight the packages that match this query expression (see: kissbom query --help)")
+	addFilterFlags(graphCmd)
+}
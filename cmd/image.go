@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	scanImageCmd = &cobra.Command{
+		Use:     "scan-image",
+		Short:   "Creates a KISSBOM of the OS packages and lockfiles of an image archive written by docker save or holding an OCI image layout",
+		Example: "  kissbom scan-image image.tar",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				util.PrintErr(errors.New("Please specify an image archive to scan"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.OutputFormat = selectedFormat
+			converter.OutputFolder = outputFolder
+			converter.Canonical = canonical
+			converter.Strict = strict
+			applyDependencyFlags(converter)
+			converter.Filter = filter
+			loadVEX(converter)
+
+			log.Println("starting scan")
+			err := converter.ScanImage(args[0])
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			log.Println("finished")
+			printCategorySummary(converter.Categories)
+			if converter.Duplicates > 0 {
+				util.PrintInfof("Collapsed %v duplicate packages\n", converter.Duplicates)
+			}
+			util.PrintInfof("Saved KISSBOM as: %v\n", converter.OutputFileName)
+			util.PrintSuccess("DONE!")
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(scanImageCmd)
+	scanImageCmd.Flags().StringVarP(&selectedFormat, "format", "f", "json", fmt.Sprintf("select one of the valid options: %s", outputFormats))
+	scanImageCmd.Flags().StringVarP(&outputFolder, "output-folder", "o", ".", "the output folder for the generated file")
+	scanImageCmd.Flags().BoolVar(&canonical, "canonical", false, "sort packages and use canonical encoding so identical content yields identical files")
+	scanImageCmd.Flags().BoolVar(&strict, "strict", false, "only write the purl, license, copyright and notes fields of the KISSBOM specification")
+	addFilterFlags(scanImageCmd)
+	addDependencyFlags(scanImageCmd)
+	addVEXFlag(scanImageCmd)
+}
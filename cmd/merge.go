@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+var (
+	mergeStrategy string
+	mergeCmd      = &cobra.Command{
+		Use:     "merge",
+		Short:   "Merges several CycloneDX, SPDX or KISSBOM files into a single KISSBOM",
+		Example: "  kissbom merge a.json b.cdx.json",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 2 {
+				util.PrintErr(errors.New("Please specify at least two files to merge"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.OutputFormat = selectedFormat
+			converter.OutputFolder = outputFolder
+			converter.Canonical = canonical
+			converter.Strict = strict
+			applyDependencyFlags(converter)
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+			converter.MergeStrategy = mergeStrategy
+
+			log.Println("starting merge")
+			err := converter.Merge(args)
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			log.Println("finished")
+			util.PrintInfof("Saved KISSBOM as: %v\n", converter.OutputFileName)
+			util.PrintSuccess("DONE!")
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().StringVarP(&selectedFormat, "format", "f", "json", fmt.Sprintf("select one of the valid options: %s", outputFormats))
+	mergeCmd.Flags().StringVarP(&outputFolder, "output-folder", "o", ".", "the output folder for the merged file")
+	mergeCmd.Flags().BoolVar(&canonical, "canonical", false, "sort packages and use canonical encoding so identical content yields identical files")
+	mergeCmd.Flags().BoolVar(&strict, "strict", false, "only write the purl, license, copyright and notes fields of the KISSBOM specification")
+	addFilterFlags(mergeCmd)
+	addDependencyFlags(mergeCmd)
+	mergeCmd.Flags().StringVarP(&mergeStrategy, "strategy", "s", models.MergeFirstWins, fmt.Sprintf("how conflicting values are reconciled, one of: %s", models.MergeStrategies))
+}
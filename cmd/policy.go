@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	policyFile     string
+	failOn         string
+	jsonReportFile string
+	junitFile      string
+	policyCmd      = &cobra.Command{
+		Use:   "policy",
+		Short: "Enforces license policies against CycloneDX, SPDX or KISSBOM files",
+	}
+	policyCheckCmd = &cobra.Command{
+		Use:     "check",
+		Short:   "Checks the packages of a CycloneDX, SPDX or KISSBOM file against a YAML license policy",
+		Example: "  kissbom policy check --policy policy.yaml test.cyclonedx.json --junit policy.xml",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				util.PrintErr(errors.New("Please specify a file to check"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+
+			log.Println("starting policy check")
+			report, err := converter.CheckPolicy(args[0], policyFile, failOn)
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			for _, violation := range report.Violations() {
+				message := fmt.Sprintf("%s %s: %s", strings.ToUpper(violation.Decision), violation.Purl, strings.Join(violation.Reasons, "; "))
+				if violation.Decision == lib.DecisionDeny {
+					util.PrintErr(errors.New(message))
+				} else {
+					util.PrintWarning(message)
+				}
+			}
+
+			writeReport(converter.Afs, jsonReportFile, report.JSON)
+			writeReport(converter.Afs, junitFile, report.JUnit)
+
+			util.PrintInfof("%v allowed, %v need review, %v denied\n", report.Allowed, report.Review, report.Denied)
+			if !report.Passed {
+				util.PrintErr(fmt.Errorf("policy check failed"))
+				os.Exit(1)
+			}
+			util.PrintSuccess("Policy check passed")
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyCheckCmd)
+	policyCheckCmd.Flags().StringVarP(&policyFile, "policy", "p", "kissbom-policy.yaml", "the YAML license policy file")
+	policyCheckCmd.Flags().StringVar(&failOn, "fail-on", lib.DecisionDeny, "fail when a package receives this decision or worse (review or deny)")
+	policyCheckCmd.Flags().StringVar(&jsonReportFile, "json", "", "save the results as JSON to this file")
+	policyCheckCmd.Flags().StringVar(&junitFile, "junit", "", "save the results as JUnit XML to this file")
+	addFilterFlags(policyCheckCmd)
+}
+
+// writeReport saves the output of encode to filename, if a filename was provided
+func writeReport(afs *afero.Afero, filename string, encode func() ([]byte, error)) {
+	if filename == "" {
+		return
+	}
+	data, err := encode()
+	if err == nil {
+		err = afs.WriteFile(filename, data, 0644)
+	}
+	if err != nil {
+		util.PrintErr(err)
+		os.Exit(1)
+	}
+	util.PrintInfof("Saved report as: %v\n", filename)
+}
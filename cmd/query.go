@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	queryCmd = &cobra.Command{
+		Use:     "query",
+		Short:   "Prints the packages of a CycloneDX, SPDX or KISSBOM file that match a query expression",
+		Example: "  kissbom query test.cyclonedx.json 'type == \"npm\" && license ~ \"GPL\" && copyright == \"\"'",
+		Long: `Prints the packages of a CycloneDX, SPDX or KISSBOM file that match a query expression.
+
+Expressions compare package fields with double quoted strings using == (equals),
+!= (not equals), ~ (matches regular expression) and !~ (does not match regular
+expression), and combine comparisons with &&, || and ! and parentheses.
+
+Valid fields are: ` + fmt.Sprint(lib.QueryFields),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 2 {
+				util.PrintErr(errors.New("Please specify a file and a query expression"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.OutputFormat = selectedFormat
+			converter.Canonical = canonical
+			converter.Strict = strict
+			applyDependencyFlags(converter)
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+
+			kissbom, err := converter.Select(args[0], args[1])
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			data, _, err := converter.Encode(kissbom)
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(data))
+			util.PrintInfof("%v packages matched\n", len(kissbom.Packages))
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.Flags().StringVarP(&selectedFormat, "format", "f", "json", fmt.Sprintf("select one of the valid options: %s", outputFormats))
+	queryCmd.Flags().BoolVar(&canonical, "canonical", false, "sort packages and use canonical encoding so identical content yields identical files")
+	queryCmd.Flags().BoolVar(&strict, "strict", false, "only write the purl, license, copyright and notes fields of the KISSBOM specification")
+	addFilterFlags(queryCmd)
+	addDependencyFlags(queryCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+var (
+	include    []string
+	exclude    []string
+	perProject bool
+	workers    int
+	scanCmd    = &cobra.Command{
+		Use:     "scan",
+		Short:   "Creates a KISSBOM of every lockfile, go.mod file and CycloneDX or SPDX SBOM found in a directory tree, such as a monorepo",
+		Example: "  kissbom scan . --exclude 'examples/*' --per-project",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				util.PrintErr(errors.New("Please specify the directory to scan"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.OutputFormat = selectedFormat
+			converter.OutputFolder = outputFolder
+			converter.Canonical = canonical
+			converter.Strict = strict
+			applyDependencyFlags(converter)
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+			converter.MergeStrategy = mergeStrategy
+			converter.Include = include
+			converter.Exclude = exclude
+			converter.PerProject = perProject
+			converter.Workers = workers
+			loadVEX(converter)
+			addEnrichers(converter)
+
+			log.Println("starting scan")
+			err := converter.ScanDirectory(args[0])
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			log.Println("finished")
+			if converter.Enriched > 0 {
+				util.PrintInfof("Enriched %v packages from local package metadata\n", converter.Enriched)
+			}
+			printCategorySummary(converter.Categories)
+			if converter.Duplicates > 0 {
+				util.PrintInfof("Collapsed %v duplicate packages\n", converter.Duplicates)
+			}
+			if perProject {
+				util.PrintInfof("Saved %v KISSBOMs in: %v\n", len(converter.Projects), outputFolder)
+			} else {
+				util.PrintInfof("Read %v files\n", len(converter.Projects))
+				util.PrintInfof("Saved KISSBOM as: %v\n", converter.OutputFileName)
+			}
+			util.PrintSuccess("DONE!")
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+	scanCmd.Flags().StringVarP(&selectedFormat, "format", "f", "json", fmt.Sprintf("select one of the valid options: %s", outputFormats))
+	scanCmd.Flags().StringVarP(&outputFolder, "output-folder", "o", ".", "the output folder for the generated files")
+	scanCmd.Flags().BoolVar(&canonical, "canonical", false, "sort packages and use canonical encoding so identical content yields identical files")
+	scanCmd.Flags().BoolVar(&strict, "strict", false, "only write the purl, license, copyright and notes fields of the KISSBOM specification")
+	scanCmd.Flags().StringSliceVar(&include, "include", nil, "only read the files whose path, relative to the directory, or name matches one of these glob patterns")
+	scanCmd.Flags().StringSliceVar(&exclude, "exclude", nil, "skip the files whose path, relative to the directory, or name matches one of these glob patterns")
+	scanCmd.Flags().BoolVar(&perProject, "per-project", false, "save a KISSBOM for each file found rather than merging them")
+	scanCmd.Flags().IntVar(&workers, "workers", 4, "maximum number of files read at the same time")
+	scanCmd.Flags().StringVarP(&mergeStrategy, "strategy", "s", models.MergeFirstWins, fmt.Sprintf("how conflicting values are reconciled, one of: %s", models.MergeStrategies))
+	addFilterFlags(scanCmd)
+	addDependencyFlags(scanCmd)
+	addVEXFlag(scanCmd)
+	addEnrichFlags(scanCmd)
+}
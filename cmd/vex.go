@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	vexFiles       []string
+	statementsFile string
+	vexCmd         = &cobra.Command{
+		Use:   "vex",
+		Short: "Produces VEX documents describing the exploitability of vulnerabilities in KISSBOM packages",
+	}
+	vexExportCmd = &cobra.Command{
+		Use:     "export",
+		Short:   "Saves an OpenVEX document for the packages of a CycloneDX, SPDX or KISSBOM file from a YAML statements file",
+		Example: "  kissbom vex export --statements vex.yaml test.cyclonedx.json",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				util.PrintErr(errors.New("Please specify a file to export VEX statements for"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.OutputFolder = outputFolder
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+
+			log.Println("starting vex export")
+			err := converter.ExportVEX(args[0], statementsFile)
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			util.PrintInfof("Saved OpenVEX document as: %v\n", converter.OutputFileName)
+			util.PrintSuccess("DONE!")
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(vexCmd)
+	vexCmd.AddCommand(vexExportCmd)
+	vexExportCmd.Flags().StringVarP(&statementsFile, "statements", "s", "kissbom-vex.yaml", "the YAML file containing the VEX statements")
+	vexExportCmd.Flags().StringVarP(&outputFolder, "output-folder", "o", ".", "the output folder for the OpenVEX document")
+	addFilterFlags(vexExportCmd)
+}
+
+// addVEXFlag registers the flag that attaches VEX statements to packages
+func addVEXFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&vexFiles, "vex", nil, "CycloneDX VEX, OpenVEX or YAML statements files whose statuses are attached to matching packages")
+}
+
+// loadVEX loads the VEX files provided with the --vex flag into the converter
+func loadVEX(converter *lib.Converter) {
+	vex, err := lib.LoadVEX(converter.Afs, vexFiles...)
+	if err != nil {
+		util.PrintErr(err)
+		os.Exit(1)
+	}
+	converter.VEX = vex
+}
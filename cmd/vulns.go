@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/devops-kung-fu/common/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/devops-kung-fu/kissbom/lib"
+)
+
+var (
+	vulnDatabases []string
+	sarifFile     string
+	vulnsCmd      = &cobra.Command{
+		Use:     "vulns",
+		Short:   "Matches the packages of a CycloneDX, SPDX or KISSBOM file against a local OSV vulnerability database",
+		Example: "  kissbom vulns --db npm.zip --db PyPI.zip test.cyclonedx.json --sarif vulns.sarif",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				util.PrintErr(errors.New("Please specify a file to scan"))
+				os.Exit(1)
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			converter := lib.NewConverter()
+			converter.Filter = filter
+			converter.GoBuildList = goBuildList
+			loadVEX(converter)
+
+			log.Println("starting vulnerability scan")
+			report, err := converter.ScanVulnerabilities(args[0], vulnDatabases)
+			if err != nil {
+				util.PrintErr(err)
+				os.Exit(1)
+			}
+
+			if len(report.Vulnerabilities) > 0 {
+				table, _ := report.Table()
+				fmt.Println(string(table))
+			}
+
+			writeReport(converter.Afs, jsonReportFile, report.JSON)
+			writeReport(converter.Afs, sarifFile, report.SARIF)
+
+			if report.Skipped > 0 {
+				util.PrintWarning(fmt.Sprintf("Skipped %v packages without a version or with an unsupported PURL type", report.Skipped))
+			}
+			util.PrintInfof("%v vulnerabilities found in %v of %v packages\n", len(report.Vulnerabilities), report.Vulnerable, report.Packages)
+			util.PrintSuccess("DONE!")
+			os.Exit(0)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(vulnsCmd)
+	vulnsCmd.Flags().StringSliceVar(&vulnDatabases, "db", nil, "OSV export zip files to match against (ex: npm.zip,PyPI.zip)")
+	vulnsCmd.Flags().StringVar(&jsonReportFile, "json", "", "save the results as JSON to this file")
+	vulnsCmd.Flags().StringVar(&sarifFile, "sarif", "", "save the results as SARIF to this file")
+	addFilterFlags(vulnsCmd)
+	addVEXFlag(vulnsCmd)
+}
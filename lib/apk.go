@@ -0,0 +1,104 @@
+package lib
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+)
+
+// apkInstalled is the database of the packages installed by apk, relative to the root
+// file system.
+const apkInstalled = "lib/apk/db/installed"
+
+// readApk reads the packages installed by apk in a root file system. Returns nil if
+// there is no apk database.
+func readApk(afs *afero.Afero, root string) (*lockfile, error) {
+	source, err := afs.ReadFile(filepath.Join(root, apkInstalled))
+	if err != nil {
+		return nil, nil
+	}
+	return parseApkInstalled(source), nil
+}
+
+// parseApkInstalled parses the database of the packages installed by apk, a paragraph
+// of single letter fields for each package. Packages are keyed by name, the architecture
+// becomes a qualifier of their PURL and the SHA-1 checksum of the package a hash.
+// Dependencies are resolved to the installed package of that name, or that provides it
+// (ex: so:libc.musl-x86_64.so.1, cmd:sh).
+func parseApkInstalled(source []byte) *lockfile {
+	lock := newLockfile(packageurl.TypeApk)
+	provides := map[string]string{}
+	dependencies := map[string][]string{}
+	var p *lockPackage
+	for _, line := range strings.Split(strings.ReplaceAll(string(source), "\r\n", "\n"), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			p = nil
+			continue
+		}
+		if p == nil {
+			p = &lockPackage{}
+		}
+		switch key {
+		case "P":
+			p.Name = value
+			lock.Packages[value] = p
+			provides[value] = value
+		case "V":
+			p.Version = value
+		case "A":
+			p.Qualifiers = map[string]string{"arch": value}
+		case "L":
+			p.License = value
+		case "C":
+			p.Hashes = apkChecksum(value)
+		case "D":
+			dependencies[p.Name] = strings.Fields(value)
+		case "p":
+			for _, provided := range strings.Fields(value) {
+				provides[apkName(provided)] = p.Name
+			}
+		}
+	}
+
+	for id, names := range dependencies {
+		for _, name := range names {
+			if strings.HasPrefix(name, "!") {
+				continue // A conflict.
+			}
+			if dependency, ok := provides[apkName(name)]; ok && dependency != id {
+				lock.Dependencies[id] = appendUnique(lock.Dependencies[id], dependency)
+			}
+		}
+	}
+	return lock
+}
+
+// apkName returns the name of a dependency or of a provided name of an apk package,
+// without its version constraint (ex: so:libcrypto.so.3=3.1.4-r5).
+func apkName(value string) string {
+	if i := strings.IndexAny(value, "=<>~"); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
+
+// apkChecksum converts the checksum of an apk package, Q1 followed by the base64 SHA-1
+// digest of its control data, to a hash keyed by CycloneDX algorithm name. Returns nil if
+// the checksum is in another format.
+func apkChecksum(checksum string) map[string]string {
+	digest, ok := strings.CutPrefix(checksum, "Q1")
+	if !ok {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(digest)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{string(cyclonedx.HashAlgoSHA1): hex.EncodeToString(decoded)}
+}
@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const testApkInstalled = `C:Q1qUr1Sg6f8bLcPL9z19CQrkNCeG8=
+P:musl
+V:1.2.4-r2
+A:x86_64
+S:383152
+I:622592
+T:the musl c library (libc) implementation
+U:https://musl.libc.org/
+L:MIT
+o:musl
+p:so:libc.musl-x86_64.so.1=1
+
+C:Q1Rt4Nmc7o8PBdiRy2DLWDKMjKNaQ=
+P:busybox
+V:1.36.1-r15
+A:x86_64
+L:GPL-2.0-only
+D:so:libc.musl-x86_64.so.1 !busybox-static
+p:cmd:sh=1.36.1-r15 /bin/sh
+
+C:Q1+m2sUfDm9VRVbZuzcJIHAe0kdFE=
+P:ssl_client
+V:1.36.1-r15
+A:x86_64
+L:GPL-2.0-only
+D:so:libc.musl-x86_64.so.1 cmd:sh>=1.36 missing
+`
+
+func TestParseApkInstalled(t *testing.T) {
+	lock := parseApkInstalled([]byte(testApkInstalled))
+	assert.Equal(t, map[string]*lockPackage{
+		"musl": {
+			Name:       "musl",
+			Version:    "1.2.4-r2",
+			License:    "MIT",
+			Hashes:     map[string]string{"SHA-1": "a94af54a0e9ff1b2dc3cbf73d7d090ae4342786f"},
+			Qualifiers: map[string]string{"arch": "x86_64"},
+		},
+		"busybox": {
+			Name:       "busybox",
+			Version:    "1.36.1-r15",
+			License:    "GPL-2.0-only",
+			Hashes:     map[string]string{"SHA-1": "46de0d99cee8f0f05d891cb60cb58328c8ca35a4"},
+			Qualifiers: map[string]string{"arch": "x86_64"},
+		},
+		"ssl_client": {
+			Name:       "ssl_client",
+			Version:    "1.36.1-r15",
+			License:    "GPL-2.0-only",
+			Hashes:     map[string]string{"SHA-1": "fa6dac51f0e6f554556d9bb370920701ed247451"},
+			Qualifiers: map[string]string{"arch": "x86_64"},
+		},
+	}, lock.Packages)
+	assert.Equal(t, map[string][]string{
+		"busybox":    {"musl"},
+		"ssl_client": {"musl", "busybox"},
+	}, lock.Dependencies)
+}
+
+func TestApkChecksum(t *testing.T) {
+	assert.Equal(t, map[string]string{"SHA-1": "a94af54a0e9ff1b2dc3cbf73d7d090ae4342786f"}, apkChecksum("Q1qUr1Sg6f8bLcPL9z19CQrkNCeG8="))
+	assert.Nil(t, apkChecksum("a94af54a0e9ff1b2dc3cbf73d7d090ae4342786f"))
+	assert.Nil(t, apkChecksum("Q1!!!"))
+}
+
+func TestReadApk(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	lock, err := readApk(afs, "rootfs")
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+
+	assert.NoError(t, afs.WriteFile("rootfs/lib/apk/db/installed", []byte(testApkInstalled), 0644))
+	lock, err = readApk(afs, "rootfs")
+	assert.NoError(t, err)
+	assert.Len(t, lock.Packages, 3)
+}
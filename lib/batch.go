@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// Conversion is the outcome of the conversion of a file by ConvertAll.
+type Conversion struct {
+	Filename       string         // The converted file.
+	OutputFileName string         // The saved KissBOM, empty if the conversion failed.
+	Duplicates     int            // Number of duplicate packages collapsed.
+	Enriched       int            // Number of packages enriched.
+	Categories     map[string]int // Number of packages in each license category.
+	Err            error          // Why the conversion failed, nil on success.
+}
+
+// ConvertAll converts every file matching the provided file names or glob patterns
+// (ex: "sboms/*.cdx.json"), using up to Workers files at a time. Each file is converted
+// by a copy of the Converter, so that the state of one conversion, such as its output
+// file name, does not leak into the others, and a failure does not stop the others.
+// A pattern that matches no file is reported as a failed conversion.
+//
+// Returns the conversions in the order of the patterns, then of the matching files.
+func (c *Converter) ConvertAll(patterns []string) []Conversion {
+	conversions := []Conversion{}
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		filenames := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			matches, err := afero.Glob(c.Afs, pattern)
+			if err == nil && len(matches) == 0 {
+				err = fmt.Errorf("no files match the pattern")
+			}
+			if err != nil {
+				conversions = append(conversions, Conversion{Filename: pattern, Err: err})
+				continue
+			}
+			filenames = matches
+		}
+		for _, filename := range filenames {
+			if !seen[filename] {
+				seen[filename] = true
+				conversions = append(conversions, Conversion{Filename: filename})
+			}
+		}
+	}
+
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, workers)
+	for i := range conversions {
+		if conversions[i].Err != nil {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(conversion *Conversion) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			converter := *c
+			if conversion.Err = converter.Convert(conversion.Filename); conversion.Err != nil {
+				log.Printf("failed: %v: %v", conversion.Filename, conversion.Err)
+				return
+			}
+			conversion.OutputFileName = converter.OutputFileName
+			conversion.Duplicates = converter.Duplicates
+			conversion.Enriched = converter.Enriched
+			conversion.Categories = converter.Categories
+		}(&conversions[i])
+	}
+	wg.Wait()
+
+	return conversions
+}
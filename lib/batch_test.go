@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+func TestConverter_ConvertAll(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("store/a.cdx.json", []byte(mergeCycloneDX), 0644))
+	assert.NoError(t, afs.WriteFile("store/b.cdx.json", []byte(mergeCycloneDX), 0644))
+	assert.NoError(t, afs.WriteFile("store/c.cdx.json", []byte("{"), 0644))
+	assert.NoError(t, afs.WriteFile("api/requirements.txt", []byte("flask==3.0.2\n"), 0644))
+
+	converter := &Converter{Afs: afs, OutputFolder: "out", OutputFormat: models.OptionJSON, Workers: 2}
+	conversions := converter.ConvertAll([]string{"store/*.cdx.json", "store/a.cdx.json", "api/requirements.txt", "missing/*.json", "missing.json"})
+	assert.Len(t, conversions, 6)
+	assert.Empty(t, converter.OutputFileName)
+
+	assert.Equal(t, Conversion{
+		Filename:       "store/a.cdx.json",
+		OutputFileName: "out/store/a.cdx.json.json",
+		Categories:     map[string]int{models.CategoryPermissive: 1, models.CategoryUnknown: 1},
+	}, conversions[0])
+	assert.Equal(t, "out/store/b.cdx.json.json", conversions[1].OutputFileName)
+	assert.Equal(t, "store/c.cdx.json", conversions[2].Filename)
+	assert.Error(t, conversions[2].Err)
+	assert.Equal(t, "out/api/requirements.txt.json", conversions[3].OutputFileName)
+	assert.EqualError(t, conversions[4].Err, "no files match the pattern")
+	assert.Error(t, conversions[5].Err)
+
+	exists, err := afs.Exists("out/store/b.cdx.json.json")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
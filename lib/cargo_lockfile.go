@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+)
+
+// cargoLock contains the parts of a Cargo.lock file used to list packages.
+type cargoLock struct {
+	Packages []cargoPackage    `toml:"package"`
+	Metadata map[string]string `toml:"metadata"` // Checksums of the packages, before version 2.
+}
+
+// cargoPackage is a package of a Cargo.lock file.
+type cargoPackage struct {
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	Source       string   `toml:"source"`
+	Checksum     string   `toml:"checksum"`
+	Dependencies []string `toml:"dependencies"`
+}
+
+// cargoManifest contains the parts of a Cargo.toml file used to read Cargo.lock files.
+type cargoManifest struct {
+	Package struct {
+		Name    string `toml:"name"`
+		Version any    `toml:"version"` // A string, or a table inheriting the version of the workspace.
+	} `toml:"package"`
+	Dependencies      map[string]any `toml:"dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+}
+
+// parseCargoLock parses a Cargo.lock file. Packages are keyed by name and version, as
+// Cargo may resolve several versions of a crate, and packages without a source are the
+// members of the workspace, which are looked through. The Cargo.toml file, when
+// provided, names the member that is the project; its direct dependencies only listed
+// in [dev-dependencies], and the packages only reachable from them, are development
+// packages. Without it, or for a virtual workspace, every member is a direct
+// dependency. Checksums, recorded in each package since version 2 and in the metadata
+// before, are SHA-256 hashes.
+func parseCargoLock(source []byte, manifest []byte) (*lockfile, error) {
+	var document cargoLock
+	if err := toml.Unmarshal(source, &document); err != nil {
+		return nil, err
+	}
+
+	lock := newLockfile(packageurl.TypeCargo)
+	versions := map[string][]string{}
+	for _, entry := range document.Packages {
+		versions[entry.Name] = append(versions[entry.Name], entry.Version)
+	}
+	// Dependencies are written as "name", "name version" or "name version (source)".
+	resolve := func(dependency string) string {
+		name, rest, _ := strings.Cut(strings.TrimSpace(dependency), " ")
+		version, _, _ := strings.Cut(rest, " ")
+		if version == "" && len(versions[name]) == 1 {
+			version = versions[name][0]
+		}
+		return name + "@" + version
+	}
+
+	for _, entry := range document.Packages {
+		id := entry.Name + "@" + entry.Version
+		p := &lockPackage{Name: entry.Name, Version: entry.Version, Local: entry.Source == ""}
+		checksum := entry.Checksum
+		if checksum == "" {
+			checksum = document.Metadata[fmt.Sprintf("checksum %s %s (%s)", entry.Name, entry.Version, entry.Source)]
+		}
+		if checksum != "" {
+			p.Hashes = map[string]string{string(cyclonedx.HashAlgoSHA256): checksum}
+		}
+		lock.Packages[id] = p
+		for _, dependency := range entry.Dependencies {
+			lock.Dependencies[id] = appendUnique(lock.Dependencies[id], resolve(dependency))
+		}
+	}
+
+	var cargo cargoManifest
+	if manifest != nil {
+		if err := toml.Unmarshal(manifest, &cargo); err != nil {
+			return nil, fmt.Errorf("Cargo.toml: %w", err)
+		}
+	}
+	lock.Name = cargo.Package.Name
+	lock.Version, _ = cargo.Package.Version.(string)
+	root := resolve(lock.Name)
+	if p, ok := lock.Packages[root]; !ok || !p.Local {
+		// A virtual workspace, or a lockfile without its manifest.
+		for id, p := range lock.Packages {
+			if p.Local {
+				lock.addDirect(id, false)
+			}
+		}
+		return lock, nil
+	}
+
+	regular := map[string]bool{}
+	for _, table := range []map[string]any{cargo.Dependencies, cargo.BuildDependencies} {
+		for _, name := range cargoDependencyNames(table) {
+			regular[name] = true
+		}
+	}
+	dev := map[string]bool{}
+	for _, name := range cargoDependencyNames(cargo.DevDependencies) {
+		dev[name] = !regular[name]
+	}
+	for _, id := range lock.Dependencies[root] {
+		if p, ok := lock.Packages[id]; ok {
+			lock.addDirect(id, dev[p.Name])
+		}
+	}
+	lock.markDev()
+	return lock, nil
+}
+
+// cargoDependencyNames returns the names of the crates of a dependency table of
+// Cargo.toml, following the package key of renamed dependencies.
+func cargoDependencyNames(table map[string]any) (names []string) {
+	for key, value := range table {
+		name := key
+		if spec, ok := value.(map[string]any); ok {
+			if renamed, ok := spec["package"].(string); ok && renamed != "" {
+				name = renamed
+			}
+		}
+		names = append(names, name)
+	}
+	return
+}
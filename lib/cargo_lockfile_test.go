@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const testCargoLock = `# This file is automatically @generated by Cargo.
+# It is not intended for manual editing.
+version = 3
+
+[[package]]
+name = "anyhow"
+version = "1.0.80"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "5ad32ce52e4161730f7098c077cd2ed6229b5804ccf99e5366be1ab72a98b4e1"
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "anyhow",
+ "core",
+ "proptest",
+]
+
+[[package]]
+name = "bitflags"
+version = "1.3.2"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "bitflags"
+version = "2.4.2"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "core"
+version = "0.1.0"
+dependencies = [
+ "bitflags 2.4.2",
+]
+
+[[package]]
+name = "proptest"
+version = "1.4.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = [
+ "bitflags 1.3.2 (registry+https://github.com/rust-lang/crates.io-index)",
+]
+`
+
+const testCargoToml = `[package]
+name = "app"
+version = "0.1.0"
+edition = "2021"
+
+[dependencies]
+anyhow = "1.0"
+core = { path = "core" }
+
+[dev-dependencies]
+proptest = "1.4"
+`
+
+func TestParseCargoLock(t *testing.T) {
+	l, err := parseCargoLock([]byte(testCargoLock), []byte(testCargoToml))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "app", l.Name)
+	assert.Len(t, l.Packages, 6)
+	assert.Equal(t, &lockPackage{
+		Name:    "anyhow",
+		Version: "1.0.80",
+		Hashes:  map[string]string{"SHA-256": "5ad32ce52e4161730f7098c077cd2ed6229b5804ccf99e5366be1ab72a98b4e1"},
+	}, l.Packages["anyhow@1.0.80"])
+	assert.True(t, l.Packages["core@0.1.0"].Local)
+	assert.Equal(t, []string{"bitflags@1.3.2"}, l.Dependencies["proptest@1.4.0"])
+	assert.Equal(t, []string{"anyhow@1.0.80", "core@0.1.0", "proptest@1.4.0"}, l.Dependencies[""])
+
+	assert.True(t, l.Packages["proptest@1.4.0"].Dev)
+	assert.True(t, l.Packages["bitflags@1.3.2"].Dev)
+	assert.False(t, l.Packages["bitflags@2.4.2"].Dev)
+}
+
+func TestParseCargoLock_WithoutManifest(t *testing.T) {
+	l, err := parseCargoLock([]byte(testCargoLock), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, l.Name)
+	assert.ElementsMatch(t, []string{"app@0.1.0", "core@0.1.0"}, l.Dependencies[""])
+	assert.False(t, l.Packages["proptest@1.4.0"].Dev)
+}
+
+func TestParseCargoLock_WorkspaceVersion(t *testing.T) {
+	l, err := parseCargoLock([]byte(testCargoLock), []byte("[package]\nname = \"app\"\nversion.workspace = true\n\n[dev-dependencies]\nproptest = { version = \"1.4\", package = \"proptest\" }\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app", l.Name)
+	assert.Empty(t, l.Version)
+	assert.True(t, l.Packages["proptest@1.4.0"].Dev)
+}
+
+func TestParseCargoLock_V1(t *testing.T) {
+	l, err := parseCargoLock([]byte(`[[package]]
+name = "anyhow"
+version = "1.0.80"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[metadata]
+"checksum anyhow 1.0.80 (registry+https://github.com/rust-lang/crates.io-index)" = "5ad3"
+`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"SHA-256": "5ad3"}, l.Packages["anyhow@1.0.80"].Hashes)
+}
+
+func TestParseCargoLock_Errors(t *testing.T) {
+	_, err := parseCargoLock([]byte("version = \n"), nil)
+	assert.Error(t, err)
+
+	_, err = parseCargoLock([]byte(testCargoLock), []byte("[package\n"))
+	assert.EqualError(t, err, `Cargo.toml: toml: line 2: expected '.' or ']' to end table name, but got '\n' instead`)
+}
+
+func TestReadLockfile_Cargo(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("app/Cargo.lock", []byte(testCargoLock), 0644))
+	assert.NoError(t, afs.WriteFile("app/Cargo.toml", []byte(testCargoToml), 0644))
+
+	kissbom, err := ReadLockfile(afs, "app/Cargo.lock", models.Filter{ExcludeScopes: []string{ScopeDev}})
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg:cargo/app@0.1.0", kissbom.Metadata.Subject)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:cargo/anyhow@1.0.80", Hashes: map[string]string{"SHA-256": "5ad32ce52e4161730f7098c077cd2ed6229b5804ccf99e5366be1ab72a98b4e1"}},
+		{Purl: "pkg:cargo/bitflags@2.4.2"},
+	}, kissbom.Packages)
+	assert.Equal(t, []string{"pkg:cargo/anyhow@1.0.80", "pkg:cargo/bitflags@2.4.2"}, kissbom.Dependencies["pkg:cargo/app@0.1.0"])
+}
@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+)
+
+// composerLock contains the parts of a composer.lock file used to list packages.
+type composerLock struct {
+	Packages    []composerPackage `json:"packages"`
+	PackagesDev []composerPackage `json:"packages-dev"`
+}
+
+// composerPackage is a package of a composer.lock file, or the project of a
+// composer.json file.
+type composerPackage struct {
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	License    []string          `json:"license"`
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+	Dist       struct {
+		Shasum string `json:"shasum"`
+	} `json:"dist"`
+}
+
+// parseComposerLock parses a composer.lock file. Packages are keyed by name, as
+// Composer resolves a single version of each, and those of packages-dev are development
+// packages. Licenses are read from the lockfile, a list of licenses being a choice
+// between them, and the SHA-1 checksum of the archive, when known, becomes a hash. The
+// composer.json file, when provided, names the project and lists its direct dependencies.
+func parseComposerLock(source []byte, manifest []byte) (*lockfile, error) {
+	var document composerLock
+	if err := json.Unmarshal(source, &document); err != nil {
+		return nil, err
+	}
+
+	lock := newLockfile(packageurl.TypeComposer)
+	for i, section := range [][]composerPackage{document.Packages, document.PackagesDev} {
+		for _, entry := range section {
+			id := strings.ToLower(entry.Name)
+			p := &lockPackage{
+				Name:    id,
+				Version: entry.Version,
+				License: strings.Join(entry.License, " OR "),
+				Dev:     i == 1,
+			}
+			if entry.Dist.Shasum != "" {
+				p.Hashes = map[string]string{string(cyclonedx.HashAlgoSHA1): entry.Dist.Shasum}
+			}
+			lock.Packages[id] = p
+			for name := range entry.Require {
+				lock.Dependencies[id] = append(lock.Dependencies[id], strings.ToLower(name))
+			}
+		}
+	}
+
+	if manifest != nil {
+		var project composerPackage
+		if err := json.Unmarshal(manifest, &project); err != nil {
+			return nil, fmt.Errorf("composer.json: %w", err)
+		}
+		lock.Name, lock.Version = strings.ToLower(project.Name), project.Version
+		for name := range project.Require {
+			lock.addDirect(strings.ToLower(name), false)
+		}
+		for name := range project.RequireDev {
+			lock.addDirect(strings.ToLower(name), true)
+		}
+	}
+	return lock, nil
+}
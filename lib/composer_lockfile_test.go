@@ -0,0 +1,66 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testComposerLock = `{
+    "_readme": ["This file locks the dependencies of your project to a known state"],
+    "content-hash": "abc",
+    "packages": [
+        {
+            "name": "monolog/monolog",
+            "version": "3.5.0",
+            "dist": {"type": "zip", "url": "https://api.github.com/repos/Seldaek/monolog/zipball/c915e2", "reference": "c915e2", "shasum": ""},
+            "require": {"php": ">=8.1", "psr/log": "^2.0 || ^3.0"},
+            "license": ["MIT"]
+        },
+        {
+            "name": "psr/log",
+            "version": "3.0.0",
+            "dist": {"type": "zip", "shasum": "0c2a1d5b2f8e2d3a3b8c1e5f7a9b0c1d2e3f4a5b"},
+            "license": ["MIT", "Apache-2.0"]
+        }
+    ],
+    "packages-dev": [
+        {
+            "name": "phpunit/phpunit",
+            "version": "10.5.11",
+            "require": {"ext-dom": "*", "php": ">=8.1"},
+            "license": ["BSD-3-Clause"]
+        }
+    ]
+}`
+
+func TestParseComposerLock(t *testing.T) {
+	manifest := []byte(`{"name": "Acme/App", "require": {"php": ">=8.1", "monolog/monolog": "^3.5"}, "require-dev": {"phpunit/phpunit": "^10.5"}}`)
+	l, err := parseComposerLock([]byte(testComposerLock), manifest)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "acme/app", l.Name)
+	assert.Equal(t, &lockPackage{Name: "monolog/monolog", Version: "3.5.0", License: "MIT"}, l.Packages["monolog/monolog"])
+	assert.Equal(t, &lockPackage{
+		Name:    "psr/log",
+		Version: "3.0.0",
+		License: "MIT OR Apache-2.0",
+		Hashes:  map[string]string{"SHA-1": "0c2a1d5b2f8e2d3a3b8c1e5f7a9b0c1d2e3f4a5b"},
+	}, l.Packages["psr/log"])
+	assert.True(t, l.Packages["phpunit/phpunit"].Dev)
+	assert.ElementsMatch(t, []string{"php", "psr/log"}, l.Dependencies["monolog/monolog"])
+	assert.ElementsMatch(t, []string{"monolog/monolog", "phpunit/phpunit"}, l.Dependencies[""])
+
+	kissbom := l.kissBOM(nil)
+	assert.Equal(t, "pkg:composer/acme/app", kissbom.Metadata.Subject)
+	assert.Equal(t, "pkg:composer/monolog/monolog@3.5.0", kissbom.Packages[0].Purl)
+	assert.Equal(t, []string{"pkg:composer/psr/log@3.0.0"}, kissbom.Dependencies["pkg:composer/monolog/monolog@3.5.0"])
+}
+
+func TestParseComposerLock_Errors(t *testing.T) {
+	_, err := parseComposerLock([]byte("{"), nil)
+	assert.Error(t, err)
+
+	_, err = parseComposerLock([]byte(testComposerLock), []byte("{"))
+	assert.ErrorContains(t, err, "composer.json: ")
+}
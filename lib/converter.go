@@ -3,6 +3,7 @@ package lib
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
 	"path"
@@ -16,10 +17,29 @@ import (
 
 // Converter represents a utility for file conversion.
 type Converter struct {
-	Afs            *afero.Afero // Afero file system abstraction for file operations.
-	OutputFileName string       // Name of the output file.
-	OutputFolder   string       //The folder in which to save the generated file.
-	OutputFormat   string       // Desired output format.
+	Afs            *afero.Afero   // Afero file system abstraction for file operations.
+	OutputFileName string         // Name of the output file.
+	OutputFolder   string         //The folder in which to save the generated file.
+	OutputFormat   string         // Desired output format.
+	MergeStrategy  string         // Strategy used to reconcile packages that share a PURL when merging.
+	Duplicates     int            // Number of duplicate packages collapsed during the last conversion.
+	Canonical      bool           // Produce byte-identical output for identical content.
+	Strict         bool           // Only write the purl, license, copyright and notes fields of the specification.
+	Filter         models.Filter  // Determines which packages are kept.
+	Query          *Query         // Optional query that packages must satisfy to be kept.
+	Categories     map[string]int // Number of packages in each license category after the last conversion.
+	VEX            models.VEX     // Exploitability statements recorded in the notes of matching packages.
+	Enrichers      []Enricher     // Fill in missing licenses and copyrights, in order.
+	Concurrency    int            // Maximum number of packages enriched at the same time.
+	Enriched       int            // Number of packages enriched during the last conversion.
+	Depth          bool           // Record the depth of each package in the dependency graph.
+	DirectOnly     bool           // Only keep the direct dependencies of the subject.
+	GoBuildList    bool           // Only include the modules of the build list when reading Go modules.
+	Workers        int            // Maximum number of files read at the same time.
+	Include        []string       // Glob patterns of the files read when scanning a directory, all when empty.
+	Exclude        []string       // Glob patterns of the files skipped when scanning a directory.
+	PerProject     bool           // Save a KissBOM for each file found when scanning a directory, rather than merging them.
+	Projects       []string       // Files read by the last directory scan, relative to its root.
 }
 
 // NewConverter creates a new instance of the Converter with default settings.
@@ -29,14 +49,19 @@ type Converter struct {
 //   - A pointer to the newly created Converter instance.
 func NewConverter() *Converter {
 	return &Converter{
-		Afs: &afero.Afero{Fs: afero.NewOsFs()},
+		Afs:           &afero.Afero{Fs: afero.NewOsFs()},
+		MergeStrategy: models.MergeFirstWins,
 	}
 }
 
-// Convert executes the conversion of the provided CycloneDX file to a KissBOM
+// Convert executes the conversion of the provided CycloneDX or SPDX file, lockfile, Go module, Go binary or Maven dependency list, to a KissBOM
 func (c *Converter) Convert(filename string) error {
 	log.Printf("converting: %v", filename)
 
+	if IsGoModule(c.Afs, filename) || IsLockfile(filename) {
+		return c.convertProject(filename)
+	}
+
 	source, err := c.Afs.ReadFile(filename)
 	if err != nil {
 		return err
@@ -53,22 +78,98 @@ func (c *Converter) Convert(filename string) error {
 	return c.writeToFile(kissbom)
 }
 
-// transform takes a byte slice representing a CycloneDX Bill of Materials (BOM) in JSON format,
-// decodes it into a CycloneDX BOM object, and then transforms it into a KissBOM object along
+// convertProject converts a Go module or a lockfile, read by load, to a KissBOM saved
+// under the name of its go.mod file or lockfile.
+func (c *Converter) convertProject(filename string) error {
+	kissbom, err := c.load(filename)
+	if err != nil {
+		return err
+	}
+	kissbom = c.process(kissbom)
+	if IsGoModule(c.Afs, filename) {
+		filename = goModPath(filename)
+	}
+	c.OutputFileName = path.Join(c.OutputFolder, filename)
+	return c.writeToFile(kissbom)
+}
+
+// transform takes a byte slice representing a CycloneDX Bill of Materials (BOM) or an SPDX
+// document in JSON format, a Go binary or the output of the Maven dependency plugin, decodes it, and then transforms it into a KissBOM object along
 // with a filename. Any decoding errors are returned as an error.
 func (c *Converter) transform(source []byte) (kissbom models.KissBOM, err error) {
-	var cdx cyclonedx.BOM
-
-	err = cyclonedx.NewBOMDecoder(bytes.NewReader(source), cyclonedx.BOMFileFormatJSON).Decode(&cdx)
-	if err != nil {
-		return
+	switch {
+	case isExecutable(source):
+		if kissbom, err = ReadGoBinary(source, c.Filter); err != nil {
+			return
+		}
+	case isMavenDependencyOutput(source):
+		if kissbom, err = ReadMavenDependencies(source, c.Filter); err != nil {
+			return
+		}
+	case isSPDX(source):
+		var spdx models.SPDXDocument
+		if err = json.Unmarshal(source, &spdx); err != nil {
+			return
+		}
+		c.OutputFileName = c.buildSPDXOutputFilename(&spdx)
+		kissbom = models.NewKissBOMFromSPDX(&spdx, c.Filter)
+	default:
+		var cdx cyclonedx.BOM
+		err = cyclonedx.NewBOMDecoder(bytes.NewReader(source), cyclonedx.BOMFileFormatJSON).Decode(&cdx)
+		if err != nil {
+			return
+		}
+		c.OutputFileName = c.buildOutputFilename(&cdx)
+		kissbom = models.NewKissBOMFromCycloneDX(&cdx, c.Filter)
 	}
+	kissbom.Metadata = withDigest(kissbom.Metadata, source)
 
 	log.Println("transformed to kissbom")
 
-	c.OutputFileName = c.buildOutputFilename(&cdx)
+	return c.process(kissbom), nil
+}
+
+// process collapses duplicates, enriches, applies dependency options, queries and VEX
+// statements to a KissBOM read from a source document, as configured. Enrichment is
+// best-effort: packages that could not be enriched are logged and left as they are.
+func (c *Converter) process(kissbom models.KissBOM) models.KissBOM {
+	c.Duplicates = kissbom.Deduplicate()
+	log.Printf("collapsed %v duplicate packages", c.Duplicates)
+
+	if len(c.Enrichers) > 0 {
+		var err error
+		if c.Enriched, err = EnrichKissBOM(&kissbom, c.Concurrency, c.Enrichers...); err != nil {
+			log.Printf("enrichment failed: %v", err)
+		}
+		log.Printf("enriched %v packages", c.Enriched)
+	}
+
+	c.applyDependencies(&kissbom)
+
+	if c.Query != nil {
+		kissbom = c.Query.Apply(kissbom)
+		log.Printf("%v packages match query: %v", len(kissbom.Packages), c.Query.Expression)
+	}
+	kissbom.PruneDependencies()
+
+	if len(c.VEX.Statements) > 0 {
+		log.Printf("%v packages have VEX statements", kissbom.ApplyVEX(c.VEX))
+	}
+
+	c.Categories = kissbom.CategorySummary()
+
+	return kissbom
+}
 
-	return models.NewKissBOMFromCycloneDX(&cdx), nil
+// applyDependencies records the depth of each package and removes indirect
+// dependencies, as configured.
+func (c *Converter) applyDependencies(kissbom *models.KissBOM) {
+	if c.Depth {
+		log.Printf("%v packages have a depth", kissbom.SetDepths())
+	}
+	if c.DirectOnly {
+		log.Printf("removed %v indirect dependencies", kissbom.DirectOnly())
+	}
 }
 
 // buildOutputFilename builds the output filename from the provided CycloneDX BOM
@@ -87,37 +188,84 @@ func (c *Converter) buildOutputFilename(cdx *cyclonedx.BOM) string {
 	return fmt.Sprint(t.Format("20060102150405"))
 }
 
-// Function to write the KissBOM to a file based on the specified output format
-func (c *Converter) writeToFile(kissbom models.KissBOM) error {
-	var outputData []byte
-	var err error
+// buildSPDXOutputFilename builds the output filename from the name, creator and
+// creation timestamp of the provided SPDX document, following buildOutputFilename.
+func (c *Converter) buildSPDXOutputFilename(spdx *models.SPDXDocument) string {
+	if spdx.Name != "" && spdx.CreationInfo != nil {
+		creator := ""
+		if len(spdx.CreationInfo.Creators) > 0 {
+			creator = spdx.CreationInfo.Creators[0]
+		}
+		return fmt.Sprintf("%s_%s_%s", spdx.Name, creator, spdx.CreationInfo.Created)
+	}
+	t := time.Now()
+	return fmt.Sprint(t.Format("20060102150405"))
+}
+
+// withDigest records the digest of the source document in the metadata, creating the
+// metadata if the source document had none.
+func withDigest(metadata *models.Metadata, source []byte) *models.Metadata {
+	if metadata == nil {
+		metadata = &models.Metadata{}
+	}
+	metadata.Digest = models.DocumentDigest(source)
+	return metadata
+}
+
+// isSPDX returns true if the source is an SPDX JSON document.
+func isSPDX(source []byte) bool {
+	var probe struct {
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	return json.Unmarshal(source, &probe) == nil && probe.SPDXVersion != ""
+}
+
+// Encode converts the KissBOM to the output format of the Converter.
+//
+// Returns:
+//   - The encoded KissBOM.
+//   - The file extension for the output format.
+//   - An error if the output format is not supported or encoding fails.
+func (c *Converter) Encode(kissbom models.KissBOM) (data []byte, extension string, err error) {
+	kissbom.Canonical = c.Canonical
+	kissbom.Strict = c.Strict
 
 	switch c.OutputFormat {
 	case models.OptionJSON:
-		outputData, err = kissbom.JSON()
-		c.OutputFileName += ".json"
+		data, err = kissbom.JSON()
+		extension = ".json"
 	case models.OptionYAML:
-		outputData, err = kissbom.YAML()
-		c.OutputFileName += ".yaml"
+		data, err = kissbom.YAML()
+		extension = ".yaml"
 	case models.OptionCSV:
-		outputData, err = kissbom.CSV()
-		c.OutputFileName += ".csv"
+		data, err = kissbom.CSV()
+		extension = ".csv"
 	case models.OptionMinimal:
-		outputData, err = kissbom.Minimal()
-		c.OutputFileName += ".json"
+		data, err = kissbom.Minimal()
+		extension = ".json"
 	case models.OptionCompatible:
-		outputData, err = kissbom.Compatible()
-		c.OutputFileName += ".cyclonedx.json"
+		data, err = kissbom.Compatible()
+		extension = ".cyclonedx.json"
 	default:
 		err = fmt.Errorf("unsupported output format: %s", c.OutputFormat)
 	}
+	return
+}
 
+// Function to write the KissBOM to a file based on the specified output format
+func (c *Converter) writeToFile(kissbom models.KissBOM) error {
+	outputData, extension, err := c.Encode(kissbom)
 	if err != nil {
 		return err
 	}
+	c.OutputFileName += extension
 
 	log.Printf("final bytes: %v", len(outputData))
 
+	if err = c.Afs.MkdirAll(path.Dir(c.OutputFileName), 0755); err != nil {
+		return err
+	}
+
 	// Use afero to write the output data to the file
 	err = afero.WriteFile(c.Afs, c.OutputFileName, outputData, 0644)
 	log.Printf("saved: %v", c.OutputFileName)
@@ -1,7 +1,9 @@
 package lib
 
 import (
+	"encoding/json"
 	"io"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -141,6 +143,7 @@ func TestTransform_Success(t *testing.T) {
 	assert.NotEmpty(t, converter.OutputFileName, "Expected filename to be not empty")
 	assert.Len(t, kissBom.Packages, 1)
 	assert.Equal(t, kissBom.Packages[0].Purl, "pkg:pypi/requests@2.26.0")
+	assert.Equal(t, &models.Metadata{Digest: models.DocumentDigest([]byte(jsonContent))}, kissBom.Metadata)
 }
 
 func TestTransform_DecodeError(t *testing.T) {
@@ -196,3 +199,142 @@ func TestConverter_writeToFile(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestTransform_Deduplicate(t *testing.T) {
+	jsonContent := `
+	{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.3",
+		"components": [
+			{"type": "library", "purl": "pkg:npm/debug@2.6.9"},
+			{"type": "library", "purl": "pkg:npm/ms@2.0.0"},
+			{"type": "library", "purl": "pkg:npm/debug@2.6.9"}
+		]
+	}`
+
+	converter := NewConverter()
+
+	kissBom, err := converter.transform([]byte(jsonContent))
+
+	assert.NoError(t, err)
+	assert.Len(t, kissBom.Packages, 2)
+	assert.Equal(t, 1, converter.Duplicates)
+}
+
+func TestTransform_SPDX(t *testing.T) {
+	jsonContent := `
+	{
+		"spdxVersion": "SPDX-2.3",
+		"name": "juice-shop",
+		"creationInfo": {"created": "2024-01-02T03:04:05Z", "creators": ["Tool: syft"]},
+		"packages": [
+			{
+				"name": "express",
+				"licenseConcluded": "NOASSERTION",
+				"licenseDeclared": "MIT",
+				"copyrightText": "NOASSERTION",
+				"checksums": [{"algorithm": "SHA256", "checksumValue": "abc123"}],
+				"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/express@4.18.2"}]
+			},
+			{"name": "juice-shop"}
+		]
+	}`
+
+	converter := Converter{Afs: &afero.Afero{Fs: afero.NewMemMapFs()}}
+
+	kissBom, err := converter.transform([]byte(jsonContent))
+	assert.NoError(t, err)
+	assert.Equal(t, "juice-shop_Tool: syft_2024-01-02T03:04:05Z", converter.OutputFileName)
+	assert.Equal(t, &models.Metadata{
+		Name:      "juice-shop",
+		Timestamp: "2024-01-02T03:04:05Z",
+		Digest:    models.DocumentDigest([]byte(jsonContent)),
+		Tool:      "syft",
+	}, kissBom.Metadata)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:npm/express@4.18.2", License: "MIT", Hashes: map[string]string{"SHA-256": "abc123"}},
+	}, kissBom.Packages)
+}
+
+func TestConverter_Encode_Strict(t *testing.T) {
+	kissbom := models.KissBOM{Packages: []models.Package{
+		{Purl: "pkg:npm/express@4.18.2", License: "MIT", Hashes: map[string]string{"SHA-256": "abc123"}},
+	}}
+	converter := Converter{OutputFormat: models.OptionJSON}
+
+	data, _, err := converter.Encode(kissbom)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"SHA-256": "abc123"`)
+
+	converter.Strict = true
+	data, _, err = converter.Encode(kissbom)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "hashes")
+	assert.NotNil(t, kissbom.Packages[0].Hashes)
+}
+
+func TestTransform_Dependencies(t *testing.T) {
+	jsonContent := `
+	{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"metadata": {"component": {"type": "application", "name": "app", "bom-ref": "app", "purl": "pkg:npm/app@1.0.0"}},
+		"components": [
+			{"type": "library", "bom-ref": "express", "purl": "pkg:npm/express@4.18.2"},
+			{"type": "library", "bom-ref": "debug", "purl": "pkg:npm/debug@2.6.9"},
+			{"type": "library", "bom-ref": "ms", "purl": "pkg:npm/ms@2.0.0"}
+		],
+		"dependencies": [
+			{"ref": "app", "dependsOn": ["express"]},
+			{"ref": "express", "dependsOn": ["debug"]},
+			{"ref": "debug", "dependsOn": ["ms"]}
+		]
+	}`
+
+	converter := NewConverter()
+	converter.Depth = true
+	kissbom, err := converter.transform([]byte(jsonContent))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, []int{kissbom.Packages[0].Depth, kissbom.Packages[1].Depth, kissbom.Packages[2].Depth})
+	assert.Len(t, kissbom.Dependencies, 3)
+
+	converter.Filter = models.Filter{Names: []string{"express", "ms"}}
+	kissbom, err = converter.transform([]byte(jsonContent))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, kissbom.Packages[1].Depth)
+	assert.Equal(t, map[string][]string{"pkg:npm/app@1.0.0": {"pkg:npm/express@4.18.2"}}, kissbom.Dependencies)
+
+	converter = NewConverter()
+	converter.DirectOnly = true
+	kissbom, err = converter.transform([]byte(jsonContent))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Package{{Purl: "pkg:npm/express@4.18.2"}}, kissbom.Packages)
+}
+
+func TestConvert_EnrichmentErrors(t *testing.T) {
+	server := httptest.NewServer(&testMirror{})
+	defer server.Close()
+
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter.OutputFormat = models.OptionJSON
+	converter.Enrichers = []Enricher{newTestHTTPEnricher(converter.Afs, server, 2)}
+	assert.NoError(t, converter.Afs.WriteFile("bom.json", []byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.4",
+		"components": [
+			{"type": "library", "purl": "pkg:npm/lodash@4.17.21"},
+			{"type": "library", "purl": "pkg:npm/broken@1.0.0"}
+		]
+	}`), 0644))
+
+	assert.NoError(t, converter.Convert("bom.json"))
+	assert.Equal(t, 1, converter.Enriched)
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Equal(t, "MIT", kissbom.Packages[0].License)
+	assert.Empty(t, kissbom.Packages[1].License)
+}
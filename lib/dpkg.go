@@ -0,0 +1,197 @@
+package lib
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Locations of the dpkg database and of the copyright files of Debian packages, relative
+// to the root file system.
+const (
+	dpkgStatus    = "var/lib/dpkg/status"   // dpkgStatus lists the packages known to dpkg.
+	dpkgStatusDir = "var/lib/dpkg/status.d" // dpkgStatusDir holds one status file for each package in distroless images.
+	dpkgDocDir    = "usr/share/doc"         // dpkgDocDir holds the copyright file of each package.
+)
+
+// readDpkg reads the packages installed by dpkg in a root file system, from the status
+// file or, in distroless images, from the status files of the status.d directory. The
+// copyright file of each package provides its copyright statements and, when written in
+// the machine-readable format, its license. Returns nil if there is no dpkg database.
+func readDpkg(afs *afero.Afero, root string) (*lockfile, error) {
+	sources := [][]byte{}
+	if data, err := afs.ReadFile(filepath.Join(root, dpkgStatus)); err == nil {
+		sources = append(sources, data)
+	}
+	if entries, err := afs.ReadDir(filepath.Join(root, dpkgStatusDir)); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasSuffix(entry.Name(), ".md5sums") {
+				continue
+			}
+			data, err := afs.ReadFile(filepath.Join(root, dpkgStatusDir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, data)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	lock := parseDpkgStatus(sources...)
+	for _, p := range lock.Packages {
+		p.License, p.Copyright = dpkgCopyright(readManifest(afs, filepath.Join(root, dpkgDocDir, p.Name, "copyright")))
+	}
+	return lock, nil
+}
+
+// parseDpkgStatus parses dpkg status files, keeping the packages that are installed.
+// Packages are keyed by name and architecture, as a package may be installed for several
+// architectures, and the architecture becomes a qualifier of their PURL. Pre-Depends and
+// Depends provide the dependencies, resolving each to the first alternative that is
+// installed, or provided by an installed package, preferably of the same architecture.
+func parseDpkgStatus(sources ...[]byte) *lockfile {
+	lock := newLockfile(packageurl.TypeDebian)
+	provides := map[string][]string{}    // Packages by name, including the virtual packages they provide.
+	relations := map[string][][]string{} // Alternatives of each dependency of each package.
+	for _, source := range sources {
+		for _, fields := range controlParagraphs(source) {
+			name, status, arch := fields["Package"], fields["Status"], fields["Architecture"]
+			if name == "" || (status != "" && !strings.HasSuffix(status, " installed")) {
+				continue
+			}
+			id := name + ":" + arch
+			p := &lockPackage{Name: name, Version: fields["Version"]}
+			if arch != "" {
+				p.Qualifiers = map[string]string{"arch": arch}
+			}
+			lock.Packages[id] = p
+			provides[name] = appendUnique(provides[name], id)
+			for _, alternatives := range dpkgRelations(fields["Provides"]) {
+				provides[alternatives[0]] = appendUnique(provides[alternatives[0]], id)
+			}
+			relations[id] = append(dpkgRelations(fields["Pre-Depends"]), dpkgRelations(fields["Depends"])...)
+		}
+	}
+
+	for id, dependencies := range relations {
+		_, arch, _ := strings.Cut(id, ":")
+		for _, alternatives := range dependencies {
+			for _, name := range alternatives {
+				candidates := provides[name]
+				if len(candidates) == 0 {
+					continue
+				}
+				dependency := candidates[0]
+				for _, candidate := range candidates {
+					if strings.HasSuffix(candidate, ":"+arch) {
+						dependency = candidate
+						break
+					}
+				}
+				lock.Dependencies[id] = appendUnique(lock.Dependencies[id], dependency)
+				break
+			}
+		}
+	}
+	return lock
+}
+
+// dpkgRelations parses a relationship field of a Debian package, a comma separated list
+// of dependencies (ex: "libc6 (>= 2.34), libssl3 | libssl1.1"), returning the package
+// names of the alternatives of each, without their version or architecture constraints.
+func dpkgRelations(field string) (relations [][]string) {
+	for _, relation := range strings.Split(field, ",") {
+		alternatives := []string{}
+		for _, alternative := range strings.Split(relation, "|") {
+			name := strings.TrimSpace(alternative)
+			if i := strings.IndexAny(name, " (:["); i >= 0 {
+				name = name[:i]
+			}
+			if name != "" {
+				alternatives = append(alternatives, name)
+			}
+		}
+		if len(alternatives) > 0 {
+			relations = append(relations, alternatives)
+		}
+	}
+	return
+}
+
+// dpkgCopyright returns the license and copyright statements of the copyright file of a
+// Debian package. For a machine-readable copyright file, the license is the one of the
+// files of the package that no other paragraph covers, and the statements of every
+// paragraph but those of the Debian packaging are joined with "; ". Otherwise, only the
+// first copyright statement is found.
+func dpkgCopyright(data []byte) (license string, copyright string) {
+	if data == nil {
+		return
+	}
+	paragraphs := controlParagraphs(data)
+	if len(paragraphs) == 0 || paragraphs[0]["Format"] == "" {
+		return "", models.FindCopyright(string(data))
+	}
+
+	statements := []string{}
+	for _, fields := range paragraphs {
+		files := strings.Fields(fields["Files"])
+		if len(files) > 0 && strings.HasPrefix(files[0], "debian/") {
+			continue
+		}
+		if len(files) == 1 && files[0] == "*" && license == "" {
+			name, _, _ := strings.Cut(fields["License"], "\n")
+			license = models.NormalizeLicense(name)
+		}
+		for _, statement := range strings.Split(fields["Copyright"], "\n") {
+			if statement = strings.TrimSpace(statement); statement != "" {
+				statements = appendUnique(statements, statement)
+			}
+		}
+	}
+	return license, strings.Join(statements, "; ")
+}
+
+// controlParagraphs parses the paragraphs of a Debian control file, such as a dpkg
+// status file or a machine-readable copyright file, into their fields. Continuation
+// lines are appended to the value of their field on a new line, "." standing for an
+// empty line.
+func controlParagraphs(source []byte) (paragraphs []map[string]string) {
+	fields, key := map[string]string{}, ""
+	for _, line := range strings.Split(strings.ReplaceAll(string(source), "\r\n", "\n"), "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(fields) > 0 {
+				paragraphs = append(paragraphs, fields)
+			}
+			fields, key = map[string]string{}, ""
+		case line[0] == ' ' || line[0] == '\t':
+			if key != "" {
+				value := strings.TrimSpace(line)
+				if value == "." {
+					value = ""
+				}
+				fields[key] += "\n" + value
+			}
+		case line[0] == '#':
+			continue
+		default:
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				key = ""
+				continue
+			}
+			key = name
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(fields) > 0 {
+		paragraphs = append(paragraphs, fields)
+	}
+	return
+}
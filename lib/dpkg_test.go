@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDpkgStatus = `Package: libc6
+Status: install ok installed
+Priority: optional
+Architecture: amd64
+Multi-Arch: same
+Version: 2.36-9+deb12u4
+Depends: libgcc-s1
+Description: GNU C Library: Shared libraries
+ Contains the standard libraries that are used by nearly all programs on
+ the system.
+
+Package: libgcc-s1
+Status: install ok installed
+Architecture: amd64
+Source: gcc-12
+Version: 12.2.0-14
+Depends: gcc-12-base (= 12.2.0-14), libc6 (>= 2.35)
+
+Package: gcc-12-base
+Status: install ok installed
+Architecture: amd64
+Version: 12.2.0-14
+
+Package: curl
+Status: install ok installed
+Architecture: amd64
+Version: 7.88.1-10+deb12u5
+Depends: libc6 (>= 2.34), libcurl4 (= 7.88.1-10+deb12u5) | libcurl3, mawk | awk
+Pre-Depends: libc6:amd64
+
+Package: mawk
+Status: install ok installed
+Architecture: amd64
+Version: 1.3.4.20200120-3.1
+Provides: awk
+
+Package: vim
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 2:9.0.1378-2
+`
+
+const testDpkgCopyright = `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: curl
+Source: https://curl.se/
+
+Files: *
+Copyright: 1996-2023, Daniel Stenberg <daniel@haxx.se>
+           2001-2023, Linus Nielsen Feltzing <linus@haxx.se>
+License: curl
+ All rights reserved.
+
+Files: lib/krb5.c
+Copyright: 1995-1999, Kungliga Tekniska Högskolan
+           1996-2023, Daniel Stenberg <daniel@haxx.se>
+License: BSD-3-clause
+
+Files: debian/*
+Copyright: 2012-2023, Debian curl maintainers
+License: curl
+`
+
+func TestParseDpkgStatus(t *testing.T) {
+	lock := parseDpkgStatus([]byte(testDpkgStatus))
+	assert.Equal(t, map[string]*lockPackage{
+		"libc6:amd64":       {Name: "libc6", Version: "2.36-9+deb12u4", Qualifiers: map[string]string{"arch": "amd64"}},
+		"libgcc-s1:amd64":   {Name: "libgcc-s1", Version: "12.2.0-14", Qualifiers: map[string]string{"arch": "amd64"}},
+		"gcc-12-base:amd64": {Name: "gcc-12-base", Version: "12.2.0-14", Qualifiers: map[string]string{"arch": "amd64"}},
+		"curl:amd64":        {Name: "curl", Version: "7.88.1-10+deb12u5", Qualifiers: map[string]string{"arch": "amd64"}},
+		"mawk:amd64":        {Name: "mawk", Version: "1.3.4.20200120-3.1", Qualifiers: map[string]string{"arch": "amd64"}},
+	}, lock.Packages)
+	assert.Equal(t, map[string][]string{
+		"libc6:amd64":     {"libgcc-s1:amd64"},
+		"libgcc-s1:amd64": {"gcc-12-base:amd64", "libc6:amd64"},
+		"curl:amd64":      {"libc6:amd64", "mawk:amd64"},
+	}, lock.Dependencies)
+}
+
+func TestDpkgRelations(t *testing.T) {
+	assert.Equal(t, [][]string{{"libc6"}, {"libssl3", "libssl1.1"}, {"python3"}}, dpkgRelations("libc6 (>= 2.34), libssl3 | libssl1.1 (>= 1.1.1), python3:any"))
+	assert.Nil(t, dpkgRelations(""))
+}
+
+func TestDpkgCopyright(t *testing.T) {
+	license, copyright := dpkgCopyright([]byte(testDpkgCopyright))
+	assert.Equal(t, "curl", license)
+	assert.Equal(t, "1996-2023, Daniel Stenberg <daniel@haxx.se>; 2001-2023, Linus Nielsen Feltzing <linus@haxx.se>; 1995-1999, Kungliga Tekniska Högskolan", copyright)
+
+	license, copyright = dpkgCopyright([]byte("This is the Debian package of zlib.\n\nCopyright (C) 1995-2022 Jean-loup Gailly and Mark Adler\n"))
+	assert.Equal(t, "", license)
+	assert.Equal(t, "Copyright (C) 1995-2022 Jean-loup Gailly and Mark Adler", copyright)
+
+	license, copyright = dpkgCopyright(nil)
+	assert.Equal(t, "", license)
+	assert.Equal(t, "", copyright)
+}
+
+func TestReadDpkg(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	lock, err := readDpkg(afs, "rootfs")
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+
+	// A distroless image, with a status file for each package.
+	assert.NoError(t, afs.WriteFile("rootfs/var/lib/dpkg/status.d/curl", []byte("Package: curl\nArchitecture: amd64\nVersion: 7.88.1-10+deb12u5\n"), 0644))
+	assert.NoError(t, afs.WriteFile("rootfs/var/lib/dpkg/status.d/curl.md5sums", []byte("d41d8cd98f00b204e9800998ecf8427e  usr/bin/curl\n"), 0644))
+	assert.NoError(t, afs.WriteFile("rootfs/usr/share/doc/curl/copyright", []byte(testDpkgCopyright), 0644))
+
+	lock, err = readDpkg(afs, "rootfs")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*lockPackage{
+		"curl:amd64": {
+			Name:       "curl",
+			Version:    "7.88.1-10+deb12u5",
+			License:    "curl",
+			Copyright:  "1996-2023, Daniel Stenberg <daniel@haxx.se>; 2001-2023, Linus Nielsen Feltzing <linus@haxx.se>; 1995-1999, Kungliga Tekniska Högskolan",
+			Qualifiers: map[string]string{"arch": "amd64"},
+		},
+	}, lock.Packages)
+}
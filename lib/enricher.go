@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Enricher provides values for the missing fields of packages, such as licenses
+// looked up in local package metadata or in a remote service.
+type Enricher interface {
+	// Enrich returns the additions for the package. Implementations only return
+	// values for fields that are empty, and must be safe for concurrent use.
+	Enrich(pkg models.Package) (Enrichment, error)
+}
+
+// Enrichment contains the values an Enricher adds to a package.
+type Enrichment struct {
+	License   string // License for a package without one.
+	Copyright string // Copyright for a package without one.
+	Notes     string // Appended to the package notes, typically recording where the values came from.
+}
+
+// IsEmpty returns true if the enrichment adds nothing to a package.
+func (e Enrichment) IsEmpty() bool {
+	return e.License == "" && e.Copyright == "" && e.Notes == ""
+}
+
+// EnrichKissBOM runs every package of the KissBOM through the enrichers, in order,
+// using up to concurrency packages at a time. Existing license and copyright values
+// are never replaced, so earlier enrichers take precedence over later ones.
+//
+// Returns:
+//   - The number of packages that were enriched.
+//   - The errors returned by the enrichers, joined together.
+func EnrichKissBOM(kissbom *models.KissBOM, concurrency int, enrichers ...Enricher) (enriched int, err error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	changed := make([]bool, len(kissbom.Packages))
+	errs := make([]error, len(kissbom.Packages))
+
+	for _, enricher := range enrichers {
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, concurrency)
+		for i := range kissbom.Packages {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(p *models.Package, i int) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				enrichment, err := enricher.Enrich(*p)
+				if err != nil {
+					errs[i] = errors.Join(errs[i], fmt.Errorf("%s: %w", p.Purl, err))
+					return
+				}
+				if applyEnrichment(p, enrichment) {
+					log.Printf("enriched: %s", p.Purl)
+					changed[i] = true
+				}
+			}(&kissbom.Packages[i], i)
+		}
+		wg.Wait()
+	}
+
+	for _, c := range changed {
+		if c {
+			enriched++
+		}
+	}
+	return enriched, errors.Join(errs...)
+}
+
+// applyEnrichment adds the enrichment to the package, returning true if the
+// package changed.
+func applyEnrichment(p *models.Package, enrichment Enrichment) bool {
+	if enrichment.IsEmpty() {
+		return false
+	}
+	if p.License == "" {
+		p.License = enrichment.License
+	}
+	if p.Copyright == "" {
+		p.Copyright = enrichment.Copyright
+	}
+	if enrichment.Notes != "" {
+		p.Notes = strings.TrimSpace(p.Notes + " " + enrichment.Notes)
+	}
+	return true
+}
+
+// enrichmentNote records the enriched fields and where they came from.
+func enrichmentNote(fields string, origin string) string {
+	return fmt.Sprintf("[enriched %s from %s]", fields, origin)
+}
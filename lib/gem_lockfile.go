@@ -0,0 +1,107 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// gemSpec matches a gem of a Gemfile.lock file, its name followed by its version, or
+// by its version requirements for a dependency (ex: "nokogiri (1.16.2-x86_64-linux)").
+var gemSpec = regexp.MustCompile(`^([^\s(!]+)(?: \(([^)]*)\))?!?$`)
+
+// parseGemfileLock parses a Gemfile.lock file. Gems are keyed by name and version,
+// which includes the platform of precompiled gems (ex: 1.16.2-x86_64-linux), and the
+// platform becomes a qualifier of their PURL. Gems of PATH sources are looked through,
+// and the one at the root of the project, the gem it builds, is the project. The
+// DEPENDENCIES section lists the direct dependencies, and the CHECKSUMS section, written
+// by Bundler 2.5 and later, provides hashes.
+func parseGemfileLock(source []byte) (*lockfile, error) {
+	lock := newLockfile(packageurl.TypeGem)
+	ids := map[string][]string{}          // Gems by name, one for each platform.
+	dependencies := map[string][]string{} // Names of the dependencies of each gem.
+	direct := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	section, remote, current := "", "", ""
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		text := strings.TrimLeft(line, " ")
+		indent := len(line) - len(text)
+		if text == "" {
+			continue
+		}
+		if indent == 0 {
+			section, remote, current = text, "", ""
+			continue
+		}
+
+		match := gemSpec.FindStringSubmatch(text)
+		switch section {
+		case "GEM", "GIT", "PATH":
+			switch {
+			case indent == 2 && strings.HasPrefix(text, "remote: "):
+				remote = strings.TrimPrefix(text, "remote: ")
+			case indent == 4 && match != nil:
+				current = match[1] + "@" + match[2]
+				p := &lockPackage{Name: match[1], Version: match[2], Local: section == "PATH"}
+				if version, platform, ok := strings.Cut(match[2], "-"); ok {
+					p.Version, p.Qualifiers = version, map[string]string{"platform": platform}
+				}
+				lock.Packages[current] = p
+				ids[match[1]] = append(ids[match[1]], current)
+				if section == "PATH" && remote == "." && lock.Name == "" {
+					lock.Name, lock.Version = p.Name, p.Version
+				}
+			case indent == 6 && match != nil && current != "":
+				dependencies[current] = append(dependencies[current], match[1])
+			}
+		case "DEPENDENCIES":
+			if indent == 2 && match != nil {
+				direct = append(direct, match[1])
+			}
+		case "CHECKSUMS":
+			spec, checksums, ok := strings.Cut(text, ") ")
+			if p, found := lock.Packages[strings.Replace(spec, " (", "@", 1)]; ok && found {
+				p.Hashes = gemChecksums(checksums)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for id, names := range dependencies {
+		for _, name := range names {
+			lock.Dependencies[id] = append(lock.Dependencies[id], ids[name]...)
+		}
+	}
+	for _, name := range direct {
+		for _, id := range ids[name] {
+			lock.addDirect(id, false)
+		}
+	}
+	return lock, nil
+}
+
+// gemChecksums converts the checksums of a gem, written as a comma separated list of
+// algorithm=hexadecimal values (ex: sha256=1a2b...), to hashes keyed by CycloneDX
+// algorithm name. Returns nil if there are none.
+func gemChecksums(checksums string) map[string]string {
+	hashes := map[string]string{}
+	for _, checksum := range strings.Split(checksums, ",") {
+		if algorithm, digest, ok := strings.Cut(strings.TrimSpace(checksum), "="); ok && digest != "" {
+			hashes[models.SPDXChecksumAlgorithm(strings.ToUpper(algorithm))] = digest
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes
+}
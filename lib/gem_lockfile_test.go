@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGemfileLock = `GIT
+  remote: https://github.com/rails/rails.git
+  revision: 2ba5ad9a4e5b0f4a7c4b9d0c4bd4c67e5b3b0bc7
+  branch: main
+  specs:
+    activesupport (7.2.0.alpha)
+      concurrent-ruby (~> 1.0, >= 1.0.2)
+
+PATH
+  remote: .
+  specs:
+    mygem (0.1.0)
+      nokogiri (>= 1.15)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    concurrent-ruby (1.2.3)
+    nokogiri (1.16.2-arm64-darwin)
+      racc (~> 1.4)
+    nokogiri (1.16.2-x86_64-linux)
+      racc (~> 1.4)
+    racc (1.7.3)
+    rspec (3.13.0)
+
+PLATFORMS
+  arm64-darwin
+  x86_64-linux
+
+DEPENDENCIES
+  activesupport!
+  mygem!
+  rspec (~> 3.0)
+
+CHECKSUMS
+  concurrent-ruby (1.2.3) sha256=82fdd3f8a0816e28d513e637bb2b90a45d7b982bdf4f3a0511722d2e495801e2
+  mygem (0.1.0)
+  racc (1.7.3) sha256=b785ab8a30ec43bce073c51dbbe791fd27000f68d1c996c95da98bf685316905
+
+BUNDLED WITH
+   2.5.6
+`
+
+func TestParseGemfileLock(t *testing.T) {
+	l, err := parseGemfileLock([]byte(testGemfileLock))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "mygem", l.Name)
+	assert.Equal(t, "0.1.0", l.Version)
+	assert.Len(t, l.Packages, 7)
+	assert.True(t, l.Packages["mygem@0.1.0"].Local)
+	assert.Equal(t, &lockPackage{Name: "activesupport", Version: "7.2.0.alpha"}, l.Packages["activesupport@7.2.0.alpha"])
+	assert.Equal(t, &lockPackage{Name: "nokogiri", Version: "1.16.2", Qualifiers: map[string]string{"platform": "x86_64-linux"}}, l.Packages["nokogiri@1.16.2-x86_64-linux"])
+	assert.Equal(t, &lockPackage{
+		Name:    "racc",
+		Version: "1.7.3",
+		Hashes:  map[string]string{"SHA-256": "b785ab8a30ec43bce073c51dbbe791fd27000f68d1c996c95da98bf685316905"},
+	}, l.Packages["racc@1.7.3"])
+
+	assert.Equal(t, []string{"nokogiri@1.16.2-arm64-darwin", "nokogiri@1.16.2-x86_64-linux"}, l.Dependencies["mygem@0.1.0"])
+	assert.Equal(t, []string{"racc@1.7.3"}, l.Dependencies["nokogiri@1.16.2-arm64-darwin"])
+	assert.Equal(t, []string{"activesupport@7.2.0.alpha", "mygem@0.1.0", "rspec@3.13.0"}, l.Dependencies[""])
+
+	kissbom := l.kissBOM(nil)
+	assert.Equal(t, "pkg:gem/mygem@0.1.0", kissbom.Metadata.Subject)
+	assert.Equal(t, "pkg:gem/nokogiri@1.16.2?platform=arm64-darwin", kissbom.Packages[2].Purl)
+}
+
+func TestGemChecksums(t *testing.T) {
+	assert.Equal(t, map[string]string{"SHA-256": "ab", "SHA-512": "cd"}, gemChecksums("sha256=ab,sha512=cd"))
+	assert.Nil(t, gemChecksums(""))
+}
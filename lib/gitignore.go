@@ -0,0 +1,119 @@
+package lib
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// gitignore holds the patterns of the .gitignore files of a directory tree.
+type gitignore struct {
+	patterns []gitignorePattern
+}
+
+// gitignorePattern is a pattern of a .gitignore file.
+type gitignorePattern struct {
+	base    string         // Directory of the .gitignore file, relative to the root of the tree, "." for the root.
+	expr    *regexp.Regexp // Matches the paths relative to base.
+	negate  bool           // True if the pattern re-includes the paths it matches.
+	dirOnly bool           // True if the pattern only matches directories.
+}
+
+// add adds the patterns of the .gitignore file of a directory, given relative to the
+// root of the tree. Blank lines and comments are skipped.
+func (g *gitignore) add(base string, source []byte) {
+	for _, line := range strings.Split(string(source), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := gitignorePattern{base: path.Clean(base)}
+		if strings.HasPrefix(line, "!") {
+			p.negate, line = true, line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// A pattern with a separator other than a trailing one is relative to the
+		// directory of the .gitignore file, otherwise it matches at any depth below it.
+		prefix := "(?:.*/)?"
+		if strings.Contains(line, "/") {
+			prefix, line = "", strings.TrimPrefix(line, "/")
+		}
+		expr, err := regexp.Compile("^" + prefix + gitignoreRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		p.expr = expr
+		g.patterns = append(g.patterns, p)
+	}
+}
+
+// ignored returns true if the path, relative to the root of the tree, is ignored. The
+// last pattern matching the path decides, patterns of deeper .gitignore files coming
+// after those of their parents. The directories of the path are not checked, as the
+// content of ignored directories is expected to be skipped.
+func (g *gitignore) ignored(name string, dir bool) (ignored bool) {
+	name = path.Clean(name)
+	for _, p := range g.patterns {
+		relative := name
+		if p.base != "." {
+			var ok bool
+			if relative, ok = strings.CutPrefix(name, p.base+"/"); !ok {
+				continue
+			}
+		}
+		if (!p.dirOnly || dir) && p.expr.MatchString(relative) {
+			ignored = !p.negate
+		}
+	}
+	return
+}
+
+// gitignoreRegexp converts a gitignore glob to a regular expression: "*" and "?" match
+// within a path segment, "**" matches across segments and brackets are character classes.
+func gitignoreRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitignore_Ignored(t *testing.T) {
+	ignore := &gitignore{}
+	ignore.add(".", []byte("# Build output\n/dist\nbuild/\n*.log\n!keep.log\n\\#notes\ndocs/**/draft-?.md\n\n"))
+	ignore.add("services/api", []byte("fixtures/\n/local.txt\n*.cdx.json\n"))
+
+	tests := []struct {
+		name    string
+		dir     bool
+		ignored bool
+	}{
+		{"dist", true, true},
+		{"services/dist", true, false},
+		{"build", true, true},
+		{"services/build", true, true},
+		{"build", false, false},
+		{"error.log", false, true},
+		{"services/api/error.log", false, true},
+		{"keep.log", false, false},
+		{"#notes", false, true},
+		{"docs/draft-1.md", false, true},
+		{"docs/a/b/draft-2.md", false, true},
+		{"docs/draft-10.md", false, false},
+		{"services/api/fixtures", true, true},
+		{"services/web/fixtures", true, false},
+		{"services/api/local.txt", false, true},
+		{"services/api/v1/local.txt", false, false},
+		{"services/api/sbom.cdx.json", false, true},
+		{"sbom.cdx.json", false, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.ignored, ignore.ignored(test.name, test.dir), test.name)
+	}
+}
+
+func TestGitignoreRegexp(t *testing.T) {
+	assert.Equal(t, `[^/]*\.log`, gitignoreRegexp("*.log"))
+	assert.Equal(t, `(?:.*/)?a/.*`, gitignoreRegexp("**/a/**"))
+	assert.Equal(t, `file[^0-9][^/]`, gitignoreRegexp("file[!0-9]?"))
+	assert.Equal(t, `\[a`, gitignoreRegexp("[a"))
+	assert.Equal(t, `\*`, gitignoreRegexp(`\*`))
+}
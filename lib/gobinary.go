@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// GoStdlib is the module path used for the Go standard library in PURLs, as vulnerability
+// databases do.
+const GoStdlib = "stdlib"
+
+// executableMagic contains the leading bytes of the executable formats Go binaries are
+// read from: ELF, PE and 32/64 bit Mach-O in either byte order, and universal Mach-O.
+var executableMagic = [][]byte{
+	[]byte("\x7fELF"),
+	[]byte("MZ"),
+	{0xfe, 0xed, 0xfa, 0xce},
+	{0xfe, 0xed, 0xfa, 0xcf},
+	{0xce, 0xfa, 0xed, 0xfe},
+	{0xcf, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+}
+
+// isExecutable returns true if the source is an ELF, PE or Mach-O executable.
+func isExecutable(source []byte) bool {
+	for _, magic := range executableMagic {
+		if bytes.HasPrefix(source, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadGoBinary builds a KissBOM from the build information embedded in a Go binary, with
+// a pkg:golang PURL for each module linked into it and for the standard library of the
+// Go toolchain that built it. The main module is the subject of the KissBOM, and the
+// toolchain is recorded as its tool.
+//
+// Parameters:
+//   - source: The content of an ELF, PE or Mach-O executable built by Go.
+//   - filters: Optional filters that determine which modules are kept.
+func ReadGoBinary(source []byte, filters ...models.Filter) (kissbom models.KissBOM, err error) {
+	info, err := buildinfo.Read(bytes.NewReader(source))
+	if err != nil {
+		return kissbom, fmt.Errorf("reading Go build information: %w", err)
+	}
+	return newKissBOMFromBuildInfo(info, filters), nil
+}
+
+// newKissBOMFromBuildInfo converts the build information of a Go binary to a KissBOM,
+// see ReadGoBinary.
+func newKissBOMFromBuildInfo(info *debug.BuildInfo, filters []models.Filter) (kissbom models.KissBOM) {
+	name := models.FirstNonEmpty(info.Main.Path, info.Path)
+	version := info.Main.Version
+	if version == "(devel)" {
+		version = ""
+	}
+	toolchain := ""
+	if fields := strings.Fields(info.GoVersion); len(fields) > 0 && strings.HasPrefix(fields[0], "go1") {
+		toolchain = strings.TrimPrefix(fields[0], "go")
+	}
+	kissbom.Metadata = &models.Metadata{
+		Subject:     goPurl(name, version),
+		Name:        name,
+		Version:     version,
+		Tool:        "go",
+		ToolVersion: toolchain,
+	}
+
+	packages := []models.Package{}
+	if toolchain != "" {
+		packages = append(packages, models.Package{Purl: goPurl(GoStdlib, toolchain)})
+	}
+	for _, d := range info.Deps {
+		var r *goReplacement
+		if d.Replace != nil {
+			r = &goReplacement{Path: d.Replace.Path, Version: d.Replace.Version}
+		}
+		packages = append(packages, goPackage(goModule{Path: d.Path, Version: d.Version}, r))
+	}
+	for _, p := range packages {
+		if allowedPurl(p.Purl, filters) {
+			kissbom.Packages = append(kissbom.Packages, p)
+		}
+	}
+	return
+}
@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// testBinary returns the content of the running test binary, which is a Go binary.
+func testBinary(t *testing.T) []byte {
+	executable, err := os.Executable()
+	assert.NoError(t, err)
+	source, err := os.ReadFile(executable)
+	assert.NoError(t, err)
+	return source
+}
+
+func TestIsExecutable(t *testing.T) {
+	assert.True(t, isExecutable(testBinary(t)))
+	assert.True(t, isExecutable([]byte("MZ\x90\x00")))
+	assert.True(t, isExecutable([]byte{0xcf, 0xfa, 0xed, 0xfe, 0x07}))
+	assert.False(t, isExecutable([]byte(`{"bomFormat": "CycloneDX"}`)))
+	assert.False(t, isExecutable(nil))
+}
+
+func TestNewKissBOMFromBuildInfo(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.22.5 X:boringcrypto",
+		Path:      "github.com/example/app/cmd/app",
+		Main:      debug.Module{Path: "github.com/example/app", Version: "v1.4.0"},
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+			{Path: "github.com/old/lib", Version: "v1.0.0", Replace: &debug.Module{Path: "github.com/new/lib", Version: "v1.2.0"}},
+			{Path: "github.com/spf13/afero", Version: "v1.11.0", Replace: &debug.Module{Path: "../afero"}},
+		},
+	}
+
+	kissbom := newKissBOMFromBuildInfo(info, nil)
+	assert.Equal(t, &models.Metadata{
+		Subject:     "pkg:golang/github.com/example/app@v1.4.0",
+		Name:        "github.com/example/app",
+		Version:     "v1.4.0",
+		Tool:        "go",
+		ToolVersion: "1.22.5",
+	}, kissbom.Metadata)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:golang/stdlib@1.22.5"},
+		{Purl: "pkg:golang/github.com/spf13/cobra@v1.8.0"},
+		{Purl: "pkg:golang/github.com/new/lib@v1.2.0", Notes: "[replaces github.com/old/lib@v1.0.0]"},
+		{Purl: "pkg:golang/github.com/spf13/afero@v1.11.0", Notes: "[replaced by ../afero]"},
+	}, kissbom.Packages)
+}
+
+func TestNewKissBOMFromBuildInfo_Devel(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "devel go1.23-abcdef",
+		Path:      "command-line-arguments",
+		Deps:      []*debug.Module{{Path: "github.com/spf13/cobra", Version: "v1.8.0"}},
+	}
+
+	kissbom := newKissBOMFromBuildInfo(info, []models.Filter{{Names: []string{"afero"}}})
+	assert.Equal(t, "command-line-arguments", kissbom.Metadata.Name)
+	assert.Equal(t, "", kissbom.Metadata.ToolVersion)
+	assert.Empty(t, kissbom.Packages)
+
+	info.Main = debug.Module{Path: "github.com/example/app", Version: "(devel)"}
+	kissbom = newKissBOMFromBuildInfo(info, nil)
+	assert.Equal(t, "pkg:golang/github.com/example/app", kissbom.Metadata.Subject)
+	assert.Equal(t, "", kissbom.Metadata.Version)
+	assert.Len(t, kissbom.Packages, 1)
+}
+
+func TestReadGoBinary(t *testing.T) {
+	kissbom, err := ReadGoBinary(testBinary(t))
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/devops-kung-fu/kissbom", kissbom.Metadata.Name)
+	assert.Condition(t, func() bool {
+		for _, purl := range purls(kissbom) {
+			if strings.HasPrefix(purl, "pkg:golang/github.com/spf13/afero@v") {
+				return true
+			}
+		}
+		return false
+	})
+
+	_, err = ReadGoBinary([]byte("\x7fELF not really"))
+	assert.ErrorContains(t, err, "reading Go build information")
+}
+
+func TestConvert_GoBinary(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter.OutputFormat = models.OptionJSON
+	converter.Filter = models.Filter{Namespaces: []string{"github.com/spf13"}}
+	source := testBinary(t)
+	assert.NoError(t, converter.Afs.WriteFile("app", source, 0755))
+
+	assert.NoError(t, converter.Convert("app"))
+	assert.Equal(t, "app.json", converter.OutputFileName)
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Len(t, kissbom.Packages, 1)
+	assert.True(t, strings.HasPrefix(kissbom.Packages[0].Purl, "pkg:golang/github.com/spf13/afero@v"))
+	assert.Equal(t, models.DocumentDigest(source), kissbom.Metadata.Digest)
+	assert.Equal(t, "go", kissbom.Metadata.Tool)
+}
@@ -0,0 +1,365 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// goModFile contains the parts of a go.mod file used to list modules.
+type goModFile struct {
+	Module   string                   // Path of the main module.
+	Go       string                   // Go version of the main module (ex: 1.21).
+	Require  []goRequirement          // Required modules, in the order they appear.
+	Replace  map[string]goReplacement // Replacements keyed by "path@version", or "path" for every version.
+	Excluded map[string]bool          // Excluded module versions keyed by "path@version".
+}
+
+// goRequirement is a module required by a go.mod file.
+type goRequirement struct {
+	Path     string
+	Version  string
+	Indirect bool // True if the requirement is marked "// indirect".
+}
+
+// goReplacement is the target of a replace directive. Local directories have no version.
+type goReplacement struct {
+	Path    string
+	Version string
+}
+
+// goModule is a module version found in go.mod, go.sum or vendor/modules.txt.
+type goModule struct {
+	Path    string
+	Version string
+}
+
+// IsGoModule returns true if the filename is a go.mod file, or a directory containing one.
+func IsGoModule(afs *afero.Afero, filename string) bool {
+	if filepath.Base(filename) == "go.mod" {
+		return true
+	}
+	if dir, err := afs.IsDir(filename); err != nil || !dir {
+		return false
+	}
+	exists, _ := afs.Exists(filepath.Join(filename, "go.mod"))
+	return exists
+}
+
+// goModPath returns the path of the go.mod file, given the file or its directory.
+func goModPath(filename string) string {
+	if filepath.Base(filename) == "go.mod" {
+		return filename
+	}
+	return filepath.Join(filename, "go.mod")
+}
+
+// ReadGoModule builds a KissBOM with a pkg:golang PURL for each module used by the Go
+// module whose go.mod file, or directory, is provided. Modules are read from go.mod,
+// go.sum and, when present, vendor/modules.txt, with replace and exclude directives
+// applied. The main module is the subject of the KissBOM, and depends on the modules
+// that go.mod does not mark as indirect.
+//
+// Parameters:
+//   - afs: The file system to read from.
+//   - filename: The go.mod file, or the directory containing it.
+//   - buildList: When true, only the modules of the build list are included: the selected
+//     version of each module required by go.mod (or go.sum for modules older than
+//     Go 1.17), or the modules that provide vendored packages. Otherwise every version
+//     of every module referenced by go.mod, go.sum and vendor/modules.txt is included.
+//   - filters: Optional filters that determine which modules are kept.
+func ReadGoModule(afs *afero.Afero, filename string, buildList bool, filters ...models.Filter) (kissbom models.KissBOM, err error) {
+	filename = goModPath(filename)
+	dir := filepath.Dir(filename)
+
+	source, err := afs.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	mod, err := parseGoMod(source)
+	if err != nil {
+		return kissbom, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	var sum, vendored []goModule
+	if data, err := afs.ReadFile(filepath.Join(dir, "go.sum")); err == nil {
+		sum = parseGoSum(data, buildList)
+	}
+	if data, err := afs.ReadFile(filepath.Join(dir, "vendor", "modules.txt")); err == nil {
+		vendored = parseVendorModules(data, buildList)
+	}
+	log.Printf("go modules: %v required, %v in go.sum, %v vendored", len(mod.Require), len(sum), len(vendored))
+
+	modules := []goModule{}
+	for _, r := range mod.Require {
+		modules = append(modules, goModule{Path: r.Path, Version: r.Version})
+	}
+	switch {
+	case buildList && vendored != nil:
+		modules = vendored
+	case buildList && compareSemver(mod.Go, "1.17") < 0:
+		modules = selectVersions(append(modules, sum...))
+	case !buildList:
+		modules = append(append(modules, sum...), vendored...)
+	}
+
+	subject := goPurl(mod.Module, "")
+	kissbom.Metadata = &models.Metadata{Subject: subject, Name: mod.Module, Digest: models.DocumentDigest(source)}
+	seen := map[string]bool{}
+	for _, m := range modules {
+		if mod.Excluded[m.Path+"@"+m.Version] || seen[m.Path+"@"+m.Version] {
+			continue
+		}
+		seen[m.Path+"@"+m.Version] = true
+
+		p := goPackage(m, mod.replacement(m.Path, m.Version))
+		if !allowedPurl(p.Purl, filters) {
+			continue
+		}
+		kissbom.Packages = append(kissbom.Packages, p)
+		if mod.requiredDirectly(m) {
+			kissbom.Dependencies = models.MergeDependencies(kissbom.Dependencies, map[string][]string{subject: {p.Purl}})
+		}
+	}
+	return
+}
+
+// requiredDirectly returns true if go.mod requires the module version without marking
+// it as indirect.
+func (mod goModFile) requiredDirectly(m goModule) bool {
+	for _, r := range mod.Require {
+		if r.Path == m.Path && r.Version == m.Version {
+			return !r.Indirect
+		}
+	}
+	return false
+}
+
+// allowedPurl returns true if the PURL is allowed by every provided filter.
+func allowedPurl(purl string, filters []models.Filter) bool {
+	for _, f := range filters {
+		if !f.Allows(purl, "", "") {
+			return false
+		}
+	}
+	return true
+}
+
+// goPurl returns the pkg:golang PURL of a module path and version.
+func goPurl(path string, version string) string {
+	namespace, name := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		namespace, name = path[:i], path[i+1:]
+	}
+	return packageurl.NewPackageURL(packageurl.TypeGolang, namespace, name, version, nil, "").ToString()
+}
+
+// selectVersions keeps the highest version of each module, as minimal version selection
+// would, in the order each module first appears.
+func selectVersions(modules []goModule) (selected []goModule) {
+	index := map[string]int{}
+	for _, m := range modules {
+		i, ok := index[m.Path]
+		switch {
+		case !ok:
+			index[m.Path] = len(selected)
+			selected = append(selected, m)
+		case compareSemver(m.Version, selected[i].Version) > 0:
+			selected[i] = m
+		}
+	}
+	return
+}
+
+// replacement returns the replacement of a module version, or nil if it is not replaced.
+func (mod goModFile) replacement(path string, version string) *goReplacement {
+	if r, ok := mod.Replace[path+"@"+version]; ok {
+		return &r
+	}
+	if r, ok := mod.Replace[path]; ok {
+		return &r
+	}
+	return nil
+}
+
+// goPackage returns the package of a module version. A module replaced by another module
+// version takes its PURL, noting the module it replaces, while a module replaced by a
+// local directory keeps its PURL, noting the directory.
+func goPackage(m goModule, r *goReplacement) models.Package {
+	p := models.Package{Purl: goPurl(m.Path, m.Version)}
+	switch {
+	case r == nil:
+	case r.Version != "":
+		p.Purl = goPurl(r.Path, r.Version)
+		p.Notes = fmt.Sprintf("[replaces %s@%s]", m.Path, m.Version)
+	default:
+		p.Notes = fmt.Sprintf("[replaced by %s]", r.Path)
+	}
+	return p
+}
+
+// parseGoMod parses the module, go, require, replace and exclude directives of a go.mod
+// file. Other directives are ignored.
+func parseGoMod(data []byte) (mod goModFile, err error) {
+	mod.Replace = map[string]goReplacement{}
+	mod.Excluded = map[string]bool{}
+
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text, comment, _ := strings.Cut(scanner.Text(), "//")
+		fields := goModFields(text)
+		switch {
+		case len(fields) == 0:
+			continue
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block == "" && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		}
+
+		verb := block
+		if verb == "" {
+			verb, fields = fields[0], fields[1:]
+		}
+		indirect := strings.HasPrefix(strings.TrimSpace(comment), "indirect")
+		if err = mod.directive(verb, fields, indirect); err != nil {
+			return mod, fmt.Errorf("line %v: %w", line, err)
+		}
+	}
+	if mod.Module == "" {
+		err = fmt.Errorf("missing module directive")
+	}
+	return mod, err
+}
+
+// directive applies a single go.mod directive.
+func (mod *goModFile) directive(verb string, fields []string, indirect bool) error {
+	switch verb {
+	case "module":
+		if len(fields) != 1 {
+			return fmt.Errorf("usage: module path")
+		}
+		mod.Module = fields[0]
+	case "go":
+		if len(fields) != 1 {
+			return fmt.Errorf("usage: go 1.23")
+		}
+		mod.Go = fields[0]
+	case "require":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: require module/path v1.2.3")
+		}
+		mod.Require = append(mod.Require, goRequirement{Path: fields[0], Version: fields[1], Indirect: indirect})
+	case "exclude":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: exclude module/path v1.2.3")
+		}
+		mod.Excluded[fields[0]+"@"+fields[1]] = true
+	case "replace":
+		arrow := -1
+		for i, f := range fields {
+			if f == "=>" {
+				arrow = i
+			}
+		}
+		if arrow < 1 || arrow > 2 || len(fields)-arrow < 2 || len(fields)-arrow > 3 {
+			return fmt.Errorf("usage: replace module/path [v1.2.3] => other/module v1.4.5 | ../local/directory")
+		}
+		key := fields[0]
+		if arrow == 2 {
+			key += "@" + fields[1]
+		}
+		target := goReplacement{Path: fields[arrow+1]}
+		if len(fields)-arrow == 3 {
+			target.Version = fields[arrow+2]
+		}
+		mod.Replace[key] = target
+	}
+	return nil
+}
+
+// goModFields splits a go.mod line into fields, unquoting quoted fields.
+func goModFields(line string) (fields []string) {
+	for _, f := range strings.Fields(line) {
+		if unquoted, err := strconv.Unquote(f); err == nil {
+			f = unquoted
+		}
+		fields = append(fields, f)
+	}
+	return
+}
+
+// parseGoSum returns the module versions listed in a go.sum file, in sorted order. When
+// buildList is true, versions for which only the go.mod file was checked are skipped,
+// since their packages are not part of the build.
+func parseGoSum(data []byte, buildList bool) (modules []goModule) {
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		version, goModOnly := strings.CutSuffix(fields[1], "/go.mod")
+		if (buildList && goModOnly) || seen[fields[0]+"@"+version] {
+			continue
+		}
+		seen[fields[0]+"@"+version] = true
+		modules = append(modules, goModule{Path: fields[0], Version: version})
+	}
+	sort.SliceStable(modules, func(i, j int) bool {
+		if modules[i].Path != modules[j].Path {
+			return modules[i].Path < modules[j].Path
+		}
+		return compareSemver(modules[i].Version, modules[j].Version) < 0
+	})
+	return
+}
+
+// parseVendorModules returns the modules listed in a vendor/modules.txt file, never
+// nil. When buildList is true, only modules that provide vendored packages are
+// returned. Replacements are left to the replace directives of go.mod, and modules
+// replaced by a local directory for every version have no version.
+func parseVendorModules(data []byte, buildList bool) (modules []goModule) {
+	modules = []goModule{}
+	packages := map[int]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# "))
+			if len(fields) < 2 {
+				continue
+			}
+			m := goModule{Path: fields[0], Version: fields[1]}
+			if m.Version == "=>" {
+				m.Version = ""
+			}
+			modules = append(modules, m)
+		case strings.HasPrefix(line, "#"), strings.TrimSpace(line) == "":
+		case len(modules) > 0:
+			packages[len(modules)-1] = true
+		}
+	}
+	if !buildList {
+		return
+	}
+	used := []goModule{}
+	for i, m := range modules {
+		if packages[i] {
+			used = append(used, m)
+		}
+	}
+	return used
+}
@@ -0,0 +1,241 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const testGoMod = `module github.com/example/app
+
+go 1.21
+
+require (
+	github.com/spf13/cobra v1.8.0
+	"github.com/spf13/afero" v1.11.0
+	golang.org/x/text v0.14.0 // indirect
+)
+
+require github.com/old/lib v1.0.0
+
+exclude golang.org/x/text v0.13.0
+
+replace github.com/old/lib v1.0.0 => github.com/new/lib v1.2.0
+
+replace github.com/spf13/afero => ../afero
+`
+
+const testGoSum = `github.com/spf13/cobra v1.8.0 h1:abc=
+github.com/spf13/cobra v1.8.0/go.mod h1:def=
+github.com/spf13/pflag v1.0.5/go.mod h1:ghi=
+golang.org/x/text v0.13.0/go.mod h1:jkl=
+golang.org/x/text v0.14.0 h1:mno=
+`
+
+const testVendorModules = `# github.com/spf13/cobra v1.8.0
+## explicit; go 1.15
+github.com/spf13/cobra
+# github.com/spf13/pflag v1.0.5
+## explicit; go 1.12
+# github.com/spf13/afero v1.11.0 => ../afero
+github.com/spf13/afero
+`
+
+func newGoModuleFs(t *testing.T, files map[string]string) *afero.Afero {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	for name, content := range files {
+		assert.NoError(t, afs.WriteFile(name, []byte(content), 0644))
+	}
+	return afs
+}
+
+func purls(kissbom models.KissBOM) (values []string) {
+	for _, p := range kissbom.Packages {
+		values = append(values, p.Purl)
+	}
+	return
+}
+
+func TestIsGoModule(t *testing.T) {
+	afs := newGoModuleFs(t, map[string]string{"app/go.mod": testGoMod, "bom.json": "{}"})
+
+	assert.True(t, IsGoModule(afs, "app"))
+	assert.True(t, IsGoModule(afs, "app/go.mod"))
+	assert.False(t, IsGoModule(afs, "bom.json"))
+	assert.False(t, IsGoModule(afs, "missing"))
+}
+
+func TestParseGoMod(t *testing.T) {
+	mod, err := parseGoMod([]byte(testGoMod))
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/example/app", mod.Module)
+	assert.Equal(t, "1.21", mod.Go)
+	assert.Equal(t, []goRequirement{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "github.com/spf13/afero", Version: "v1.11.0"},
+		{Path: "golang.org/x/text", Version: "v0.14.0", Indirect: true},
+		{Path: "github.com/old/lib", Version: "v1.0.0"},
+	}, mod.Require)
+	assert.True(t, mod.Excluded["golang.org/x/text@v0.13.0"])
+	assert.Equal(t, goReplacement{Path: "github.com/new/lib", Version: "v1.2.0"}, mod.Replace["github.com/old/lib@v1.0.0"])
+	assert.Equal(t, goReplacement{Path: "../afero"}, mod.Replace["github.com/spf13/afero"])
+}
+
+func TestParseGoMod_Errors(t *testing.T) {
+	_, err := parseGoMod([]byte("go 1.21\n"))
+	assert.EqualError(t, err, "missing module directive")
+
+	_, err = parseGoMod([]byte("module example.com/app\n\nrequire example.com/lib\n"))
+	assert.EqualError(t, err, "line 3: usage: require module/path v1.2.3")
+
+	_, err = parseGoMod([]byte("module example.com/app\nreplace example.com/lib v1.0.0\n"))
+	assert.ErrorContains(t, err, "line 2: usage: replace")
+}
+
+func TestParseGoSum(t *testing.T) {
+	assert.Equal(t, []goModule{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "github.com/spf13/pflag", Version: "v1.0.5"},
+		{Path: "golang.org/x/text", Version: "v0.13.0"},
+		{Path: "golang.org/x/text", Version: "v0.14.0"},
+	}, parseGoSum([]byte(testGoSum), false))
+
+	assert.Equal(t, []goModule{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "golang.org/x/text", Version: "v0.14.0"},
+	}, parseGoSum([]byte(testGoSum), true))
+}
+
+func TestParseVendorModules(t *testing.T) {
+	assert.Equal(t, []goModule{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "github.com/spf13/pflag", Version: "v1.0.5"},
+		{Path: "github.com/spf13/afero", Version: "v1.11.0"},
+	}, parseVendorModules([]byte(testVendorModules), false))
+
+	assert.Equal(t, []goModule{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "github.com/spf13/afero", Version: "v1.11.0"},
+	}, parseVendorModules([]byte(testVendorModules), true))
+
+	assert.Equal(t, []goModule{}, parseVendorModules(nil, true))
+}
+
+func TestGoPurl(t *testing.T) {
+	assert.Equal(t, "pkg:golang/github.com/spf13/cobra@v1.8.0", goPurl("github.com/spf13/cobra", "v1.8.0"))
+	assert.Equal(t, "pkg:golang/rsc.io/quote@v1.5.2", goPurl("rsc.io/quote", "v1.5.2"))
+	assert.Equal(t, "pkg:golang/example", goPurl("example", ""))
+}
+
+func TestReadGoModule(t *testing.T) {
+	afs := newGoModuleFs(t, map[string]string{"app/go.mod": testGoMod, "app/go.sum": testGoSum})
+
+	kissbom, err := ReadGoModule(afs, "app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"pkg:golang/github.com/spf13/cobra@v1.8.0",
+		"pkg:golang/github.com/spf13/afero@v1.11.0",
+		"pkg:golang/golang.org/x/text@v0.14.0",
+		"pkg:golang/github.com/new/lib@v1.2.0",
+		"pkg:golang/github.com/spf13/pflag@v1.0.5",
+	}, purls(kissbom))
+	assert.Equal(t, "[replaced by ../afero]", kissbom.Packages[1].Notes)
+	assert.Equal(t, "[replaces github.com/old/lib@v1.0.0]", kissbom.Packages[3].Notes)
+
+	assert.Equal(t, "pkg:golang/github.com/example/app", kissbom.Metadata.Subject)
+	assert.Equal(t, "github.com/example/app", kissbom.Metadata.Name)
+	assert.Equal(t, models.DocumentDigest([]byte(testGoMod)), kissbom.Metadata.Digest)
+	assert.Equal(t, map[string][]string{
+		"pkg:golang/github.com/example/app": {
+			"pkg:golang/github.com/new/lib@v1.2.0",
+			"pkg:golang/github.com/spf13/afero@v1.11.0",
+			"pkg:golang/github.com/spf13/cobra@v1.8.0",
+		},
+	}, kissbom.Dependencies)
+}
+
+func TestReadGoModule_BuildList(t *testing.T) {
+	afs := newGoModuleFs(t, map[string]string{"go.mod": testGoMod, "go.sum": testGoSum})
+
+	kissbom, err := ReadGoModule(afs, "go.mod", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"pkg:golang/github.com/spf13/cobra@v1.8.0",
+		"pkg:golang/github.com/spf13/afero@v1.11.0",
+		"pkg:golang/golang.org/x/text@v0.14.0",
+		"pkg:golang/github.com/new/lib@v1.2.0",
+	}, purls(kissbom))
+}
+
+func TestReadGoModule_BuildListBeforeGo117(t *testing.T) {
+	afs := newGoModuleFs(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.16\n\nrequire golang.org/x/text v0.13.0\n",
+		"go.sum": testGoSum,
+	})
+
+	kissbom, err := ReadGoModule(afs, ".", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"pkg:golang/golang.org/x/text@v0.14.0",
+		"pkg:golang/github.com/spf13/cobra@v1.8.0",
+	}, purls(kissbom))
+}
+
+func TestReadGoModule_Vendored(t *testing.T) {
+	afs := newGoModuleFs(t, map[string]string{
+		"go.mod":             testGoMod,
+		"go.sum":             testGoSum,
+		"vendor/modules.txt": testVendorModules,
+	})
+
+	kissbom, err := ReadGoModule(afs, ".", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"pkg:golang/github.com/spf13/cobra@v1.8.0",
+		"pkg:golang/github.com/spf13/afero@v1.11.0",
+	}, purls(kissbom))
+}
+
+func TestReadGoModule_Filter(t *testing.T) {
+	afs := newGoModuleFs(t, map[string]string{"go.mod": testGoMod})
+
+	kissbom, err := ReadGoModule(afs, ".", false, models.Filter{Namespaces: []string{"github.com/spf13"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"pkg:golang/github.com/spf13/cobra@v1.8.0",
+		"pkg:golang/github.com/spf13/afero@v1.11.0",
+	}, purls(kissbom))
+}
+
+func TestReadGoModule_Errors(t *testing.T) {
+	afs := newGoModuleFs(t, map[string]string{"app/go.mod": "require example.com/lib\n"})
+
+	_, err := ReadGoModule(afs, "missing", false)
+	assert.Error(t, err)
+
+	_, err = ReadGoModule(afs, "app", false)
+	assert.EqualError(t, err, "app/go.mod: line 1: usage: require module/path v1.2.3")
+}
+
+func TestConvert_GoModule(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = newGoModuleFs(t, map[string]string{"app/go.mod": testGoMod, "app/go.sum": testGoSum})
+	converter.OutputFormat = models.OptionJSON
+	converter.GoBuildList = true
+	converter.DirectOnly = true
+
+	assert.NoError(t, converter.Convert("app"))
+	assert.Equal(t, "app/go.mod.json", converter.OutputFileName)
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Len(t, kissbom.Packages, 3)
+	assert.Equal(t, "pkg:golang/github.com/example/app", kissbom.Metadata.Subject)
+}
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// parseGradleLock parses a gradle.lockfile or buildscript-gradle.lockfile file, which
+// lists each locked module as group:name:version followed by the configurations that
+// resolve it (ex: com.google.guava:guava:33.0.0-jre=compileClasspath,runtimeClasspath).
+// Packages are keyed by their coordinates, and the configurations become their scopes.
+// The lockfile does not record dependencies, so the project has none.
+func parseGradleLock(source []byte) (*lockfile, error) {
+	lock := newLockfile(packageurl.TypeMaven)
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		coordinates, configurations, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %v: expected = after %s", number, line)
+		}
+		if coordinates == "empty" {
+			continue
+		}
+		parts := strings.Split(coordinates, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("line %v: invalid coordinates %q", number, coordinates)
+		}
+
+		p := &lockPackage{Name: parts[0] + ":" + parts[1], Version: parts[2]}
+		for _, configuration := range strings.Split(configurations, ",") {
+			if configuration = strings.TrimSpace(configuration); configuration != "" {
+				p.Scopes = appendUnique(p.Scopes, configuration)
+			}
+		}
+		lock.Packages[coordinates] = p
+	}
+	return lock, scanner.Err()
+}
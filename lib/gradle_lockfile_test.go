@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const testGradleLock = `# This is a Gradle generated file for dependency locking.
+# Manual edits can break the build and are not advised.
+# This file is expected to be part of source control.
+com.google.guava:guava:33.0.0-jre=compileClasspath,runtimeClasspath,testCompileClasspath,testRuntimeClasspath
+junit:junit:4.13.2=testCompileClasspath,testRuntimeClasspath
+org.hamcrest:hamcrest-core:1.3=testCompileClasspath,testRuntimeClasspath
+empty=annotationProcessor,testAnnotationProcessor
+`
+
+func TestParseGradleLock(t *testing.T) {
+	l, err := parseGradleLock([]byte(testGradleLock))
+	assert.NoError(t, err)
+
+	assert.Len(t, l.Packages, 3)
+	assert.Equal(t, &lockPackage{
+		Name:    "com.google.guava:guava",
+		Version: "33.0.0-jre",
+		Scopes:  []string{"compileClasspath", "runtimeClasspath", "testCompileClasspath", "testRuntimeClasspath"},
+	}, l.Packages["com.google.guava:guava:33.0.0-jre"])
+	assert.Empty(t, l.Dependencies)
+}
+
+func TestParseGradleLock_Errors(t *testing.T) {
+	_, err := parseGradleLock([]byte("# comment\njunit:junit:4.13.2\n"))
+	assert.EqualError(t, err, "line 2: expected = after junit:junit:4.13.2")
+
+	_, err = parseGradleLock([]byte("junit:4.13.2=compileClasspath\n"))
+	assert.EqualError(t, err, `line 1: invalid coordinates "junit:4.13.2"`)
+}
+
+func TestReadLockfile_Gradle(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("service/gradle.lockfile", []byte(testGradleLock), 0644))
+
+	kissbom, err := ReadLockfile(afs, "service/gradle.lockfile", models.Filter{ExcludeScopes: []string{"testCompileClasspath", "testRuntimeClasspath"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Package{{Purl: "pkg:maven/com.google.guava/guava@33.0.0-jre"}}, kissbom.Packages)
+	assert.Equal(t, models.DocumentDigest([]byte(testGradleLock)), kissbom.Metadata.Digest)
+
+	assert.NoError(t, afs.WriteFile("buildscript-gradle.lockfile", []byte("com.example:plugin:1.0=classpath\n"), 0644))
+	kissbom, err = ReadLockfile(afs, "buildscript-gradle.lockfile")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Package{{Purl: "pkg:maven/com.example/plugin@1.0"}}, kissbom.Packages)
+}
@@ -0,0 +1,227 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Enumeration of valid graph output formats.
+const (
+	GraphDOT     = "dot"     // GraphDOT renders the graph in the Graphviz DOT language.
+	GraphMermaid = "mermaid" // GraphMermaid renders the graph as a Mermaid flowchart.
+)
+
+// GraphFormats contains all of the valid graph output formats.
+var GraphFormats = []string{GraphDOT, GraphMermaid}
+
+// categoryColors contains the fill color of the nodes in each license category.
+var categoryColors = map[string]string{
+	models.CategoryPublicDomain:    "#b7e1cd",
+	models.CategoryPermissive:      "#c6efce",
+	models.CategoryWeakCopyleft:    "#ffeb9c",
+	models.CategoryStrongCopyleft:  "#f8cbad",
+	models.CategoryNetworkCopyleft: "#f4b183",
+	models.CategoryProprietary:     "#d9b3ff",
+	models.CategoryUnknown:         "#d9d9d9",
+}
+
+// Colors of the subject node and of the border of highlighted nodes.
+const (
+	subjectColor   = "#ffffff"
+	highlightColor = "#d00000"
+)
+
+// Graph is the dependency graph of a KissBOM, ready to be rendered.
+type Graph struct {
+	Nodes []GraphNode // Nodes, starting with the subject if there is one, in canonical order.
+	Edges []GraphEdge // Edges, in the order of their nodes.
+}
+
+// GraphNode is a package, or the subject of the KissBOM, in a Graph.
+type GraphNode struct {
+	Purl        string // Canonical PURL of the package.
+	Label       string // Name and version of the package (ex: lodash@4.17.21).
+	Category    string // License category of the package, empty for the subject.
+	Depth       int    // Depth of the package in the dependency graph, 0 for the subject or when unknown.
+	Subject     bool   // True if the node is the subject of the KissBOM.
+	Highlighted bool   // True if the package matches the highlight query.
+}
+
+// GraphEdge is a dependency from one node of a Graph to another.
+type GraphEdge struct {
+	From int // Index of the dependent node.
+	To   int // Index of the dependency node.
+}
+
+// NewGraph builds the Graph of the packages of a KissBOM and their dependencies.
+//
+// Parameters:
+//   - kissbom: The KissBOM to graph.
+//   - maxDepth: When greater than 0, packages deeper in the dependency graph are left out.
+//     Ignored if the KissBOM has no dependencies.
+//   - highlight: Optional query selecting the packages to highlight.
+func NewGraph(kissbom models.KissBOM, maxDepth int, highlight *Query) (graph Graph) {
+	kissbom.Packages = append([]models.Package{}, kissbom.Packages...)
+	kissbom.Sort()
+	depths := kissbom.Depths()
+	index := map[string]int{}
+
+	if kissbom.Metadata != nil && kissbom.Metadata.Subject != "" {
+		subject := models.CanonicalPurl(kissbom.Metadata.Subject)
+		index[subject] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Purl:    subject,
+			Label:   nodeLabel(subject),
+			Subject: true,
+		})
+	}
+
+	for _, p := range kissbom.Packages {
+		purl := models.CanonicalPurl(p.Purl)
+		if _, ok := index[purl]; ok {
+			continue
+		}
+		depth := depths[purl]
+		if maxDepth > 0 && len(depths) > 0 && (depth == 0 || depth > maxDepth) {
+			continue
+		}
+		p.Depth = depth
+		index[purl] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Purl:        purl,
+			Label:       nodeLabel(purl),
+			Category:    models.LicenseCategory(p.License),
+			Depth:       depth,
+			Highlighted: highlight != nil && highlight.Match(p),
+		})
+	}
+
+	for purl, dependencies := range kissbom.Dependencies {
+		from, ok := index[models.CanonicalPurl(purl)]
+		if !ok {
+			continue
+		}
+		for _, d := range dependencies {
+			if to, ok := index[models.CanonicalPurl(d)]; ok && to != from {
+				graph.Edges = append(graph.Edges, GraphEdge{From: from, To: to})
+			}
+		}
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return
+}
+
+// nodeLabel returns the name and version of a PURL, or the PURL itself if it cannot
+// be parsed.
+func nodeLabel(purl string) string {
+	p, err := packageurl.FromString(purl)
+	if err != nil {
+		return purl
+	}
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + "@" + p.Version
+}
+
+// Render renders the graph in the provided format.
+func (g Graph) Render(format string) ([]byte, error) {
+	switch format {
+	case GraphDOT:
+		return g.DOT()
+	case GraphMermaid:
+		return g.Mermaid()
+	}
+	return nil, fmt.Errorf("unsupported graph format: %s", format)
+}
+
+// DOT renders the graph in the Graphviz DOT language, filling nodes with the color of
+// their license category and outlining highlighted nodes.
+func (g Graph) DOT() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("digraph kissbom {\n")
+	buf.WriteString("\trankdir=LR;\n")
+	buf.WriteString("\tnode [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		attributes := []string{"label=" + dotString(n.Label)}
+		switch {
+		case n.Subject:
+			attributes = append(attributes, "fillcolor="+dotString(subjectColor), "penwidth=2")
+		default:
+			attributes = append(attributes, "fillcolor="+dotString(categoryColors[n.Category]), "tooltip="+dotString(n.Purl+" ("+n.Category+")"))
+		}
+		if n.Highlighted {
+			attributes = append(attributes, "color="+dotString(highlightColor), "penwidth=3")
+		}
+		fmt.Fprintf(&buf, "\t%s [%s];\n", dotString(n.Purl), strings.Join(attributes, ", "))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "\t%s -> %s;\n", dotString(g.Nodes[e.From].Purl), dotString(g.Nodes[e.To].Purl))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// dotString quotes a value as a DOT string.
+func dotString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, styling nodes with a class for
+// their license category and outlining highlighted nodes.
+func (g Graph) Mermaid() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("graph LR\n")
+	classes := map[string][]string{}
+	for i, n := range g.Nodes {
+		fmt.Fprintf(&buf, "\tn%d[%s]\n", i, mermaidString(n.Label))
+		if !n.Subject {
+			classes[n.Category] = append(classes[n.Category], fmt.Sprintf("n%d", i))
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "\tn%d --> n%d\n", e.From, e.To)
+	}
+	for _, category := range models.LicenseCategories {
+		if len(classes[category]) == 0 {
+			continue
+		}
+		class := strings.ReplaceAll(category, "-", "_")
+		fmt.Fprintf(&buf, "\tclassDef %s fill:%s,stroke:#333333\n", class, categoryColors[category])
+		fmt.Fprintf(&buf, "\tclass %s %s\n", strings.Join(classes[category], ","), class)
+	}
+	for i, n := range g.Nodes {
+		if n.Highlighted {
+			fmt.Fprintf(&buf, "\tstyle n%d stroke:%s,stroke-width:3px\n", i, highlightColor)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// mermaidString quotes a value as a Mermaid node label.
+func mermaidString(value string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(value) + `"`
+}
+
+// Graph reads the provided file and returns the Graph of its packages and their
+// dependencies, see NewGraph.
+func (c *Converter) Graph(filename string, maxDepth int, highlight *Query) (graph Graph, err error) {
+	kissbom, err := c.load(filename)
+	if err != nil {
+		return
+	}
+	c.Duplicates = kissbom.Deduplicate()
+	return NewGraph(kissbom, maxDepth, highlight), nil
+}
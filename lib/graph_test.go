@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const graphCycloneDX = `
+{
+	"bomFormat": "CycloneDX",
+	"specVersion": "1.5",
+	"metadata": {"component": {"type": "application", "name": "app", "bom-ref": "app", "purl": "pkg:npm/app@1.0.0"}},
+	"components": [
+		{"type": "library", "bom-ref": "express", "purl": "pkg:npm/express@4.18.2", "licenses": [{"expression": "MIT"}]},
+		{"type": "library", "bom-ref": "debug", "purl": "pkg:npm/debug@2.6.9", "licenses": [{"expression": "GPL-3.0-only"}]},
+		{"type": "library", "bom-ref": "ms", "purl": "pkg:npm/ms@2.0.0"}
+	],
+	"dependencies": [
+		{"ref": "app", "dependsOn": ["express"]},
+		{"ref": "express", "dependsOn": ["debug"]},
+		{"ref": "debug", "dependsOn": ["ms"]}
+	]
+}`
+
+func TestConverter_Graph(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, converter.Afs.WriteFile("app.cdx.json", []byte(graphCycloneDX), 0644))
+
+	highlight, err := ParseQuery(`category ~ "copyleft"`)
+	assert.NoError(t, err)
+
+	graph, err := converter.Graph("app.cdx.json", 0, highlight)
+	assert.NoError(t, err)
+	assert.Equal(t, []GraphNode{
+		{Purl: "pkg:npm/app@1.0.0", Label: "app@1.0.0", Subject: true},
+		{Purl: "pkg:npm/debug@2.6.9", Label: "debug@2.6.9", Category: models.CategoryStrongCopyleft, Depth: 2, Highlighted: true},
+		{Purl: "pkg:npm/express@4.18.2", Label: "express@4.18.2", Category: models.CategoryPermissive, Depth: 1},
+		{Purl: "pkg:npm/ms@2.0.0", Label: "ms@2.0.0", Category: models.CategoryUnknown, Depth: 3},
+	}, graph.Nodes)
+	assert.Equal(t, []GraphEdge{{From: 0, To: 2}, {From: 1, To: 3}, {From: 2, To: 1}}, graph.Edges)
+
+	graph, err = converter.Graph("app.cdx.json", 2, nil)
+	assert.NoError(t, err)
+	assert.Len(t, graph.Nodes, 3)
+	assert.Equal(t, []GraphEdge{{From: 0, To: 2}, {From: 2, To: 1}}, graph.Edges)
+
+	// depths are computed before filtering, so filtered packages do not shorten paths
+	converter.Filter = models.Filter{Names: []string{"express", "ms"}}
+	graph, err = converter.Graph("app.cdx.json", 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, graph.Nodes[2].Depth)
+	assert.Equal(t, []GraphEdge{{From: 0, To: 1}}, graph.Edges)
+
+	_, err = converter.Graph("missing.json", 0, nil)
+	assert.Error(t, err)
+}
+
+func TestGraph_DOT(t *testing.T) {
+	graph := Graph{
+		Nodes: []GraphNode{
+			{Purl: "pkg:npm/app@1.0.0", Label: "app@1.0.0", Subject: true},
+			{Purl: "pkg:npm/debug@2.6.9", Label: `debug "quoted"`, Category: models.CategoryStrongCopyleft, Highlighted: true},
+		},
+		Edges: []GraphEdge{{From: 0, To: 1}},
+	}
+
+	data, err := graph.Render(GraphDOT)
+	assert.NoError(t, err)
+	assert.Equal(t, `digraph kissbom {
+	rankdir=LR;
+	node [shape=box, style="rounded,filled", fontname="Helvetica"];
+	"pkg:npm/app@1.0.0" [label="app@1.0.0", fillcolor="#ffffff", penwidth=2];
+	"pkg:npm/debug@2.6.9" [label="debug \"quoted\"", fillcolor="#f8cbad", tooltip="pkg:npm/debug@2.6.9 (strong-copyleft)", color="#d00000", penwidth=3];
+	"pkg:npm/app@1.0.0" -> "pkg:npm/debug@2.6.9";
+}
+`, string(data))
+}
+
+func TestGraph_Mermaid(t *testing.T) {
+	graph := Graph{
+		Nodes: []GraphNode{
+			{Purl: "pkg:npm/app@1.0.0", Label: "app@1.0.0", Subject: true},
+			{Purl: "pkg:npm/debug@2.6.9", Label: `debug "quoted"`, Category: models.CategoryStrongCopyleft, Highlighted: true},
+			{Purl: "pkg:npm/ms@2.0.0", Label: "ms@2.0.0", Category: models.CategoryPermissive},
+		},
+		Edges: []GraphEdge{{From: 0, To: 1}, {From: 1, To: 2}},
+	}
+
+	data, err := graph.Render(GraphMermaid)
+	assert.NoError(t, err)
+	assert.Equal(t, `graph LR
+	n0["app@1.0.0"]
+	n1["debug #quot;quoted#quot;"]
+	n2["ms@2.0.0"]
+	n0 --> n1
+	n1 --> n2
+	classDef permissive fill:#c6efce,stroke:#333333
+	class n2 permissive
+	classDef strong_copyleft fill:#f8cbad,stroke:#333333
+	class n1 strong_copyleft
+	style n1 stroke:#d00000,stroke-width:3px
+`, string(data))
+
+	_, err = graph.Render("png")
+	assert.Error(t, err)
+}
+
+func TestNodeLabel(t *testing.T) {
+	assert.Equal(t, "core@1.0.0", nodeLabel("pkg:npm/%40angular/core@1.0.0"))
+	assert.Equal(t, "lodash", nodeLabel("pkg:npm/lodash"))
+	assert.Equal(t, "not a purl", nodeLabel("not a purl"))
+}
@@ -0,0 +1,240 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// HTTPEnricher looks up missing licenses and copyrights in the JSON responses of an
+// HTTP service, such as an internal caching mirror of ClearlyDefined or deps.dev.
+// Responses are cached on disk, and requests are rate limited and bounded in number.
+type HTTPEnricher struct {
+	Afs            *afero.Afero // Afero file system abstraction for the response cache.
+	Client         *http.Client // Client used for requests.
+	URL            string       // URL template, see RequestURL.
+	LicenseField   string       // Dot separated path of the license in the response (ex: licensed.declared).
+	CopyrightField string       // Dot separated path of the copyright in the response (ex: licensed.facets.core.attribution.parties).
+	CacheDir       string       // Directory in which responses are cached, caching is disabled when empty.
+	Name           string       // Name of the service recorded in notes, defaults to the host of the URL.
+
+	requests chan struct{} // Bounds the number of requests in flight.
+	limiter  *rateLimiter  // Spaces requests out over time.
+}
+
+// NewHTTPEnricher creates an HTTPEnricher for the URL template that makes at most
+// requestsPerSecond requests per second (unlimited when 0), with at most concurrency
+// requests in flight.
+func NewHTTPEnricher(afs *afero.Afero, urlTemplate string, requestsPerSecond float64, concurrency int) *HTTPEnricher {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	limiter := &rateLimiter{}
+	if requestsPerSecond > 0 {
+		limiter.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return &HTTPEnricher{
+		Afs:            afs,
+		Client:         &http.Client{Timeout: 30 * time.Second},
+		URL:            urlTemplate,
+		LicenseField:   "license",
+		CopyrightField: "copyright",
+		requests:       make(chan struct{}, concurrency),
+		limiter:        limiter,
+	}
+}
+
+// RequestURL builds the URL for a package by replacing the {purl}, {type},
+// {namespace}, {name} and {version} placeholders of the URL template with the escaped
+// PURL and its components. An empty namespace is replaced by "-". When the template
+// has no placeholders, the escaped PURL is appended to it.
+func (e *HTTPEnricher) RequestURL(purl packageurl.PackageURL) string {
+	if !strings.Contains(e.URL, "{") {
+		return strings.TrimSuffix(e.URL, "/") + "/" + url.PathEscape(purl.ToString())
+	}
+	namespace := purl.Namespace
+	if namespace == "" {
+		namespace = "-"
+	}
+	return strings.NewReplacer(
+		"{purl}", url.PathEscape(purl.ToString()),
+		"{type}", url.PathEscape(purl.Type),
+		"{namespace}", url.PathEscape(namespace),
+		"{name}", url.PathEscape(purl.Name),
+		"{version}", url.PathEscape(purl.Version),
+	).Replace(e.URL)
+}
+
+// Enrich requests the missing license and copyright of the package from the service.
+// Packages the service does not know (404 Not Found) are not enriched.
+func (e *HTTPEnricher) Enrich(pkg models.Package) (enrichment Enrichment, err error) {
+	if pkg.License != "" && pkg.Copyright != "" {
+		return
+	}
+	purl, err := packageurl.FromString(strings.TrimSpace(pkg.Purl))
+	if err != nil {
+		return enrichment, nil
+	}
+
+	data, err := e.fetch(e.RequestURL(purl))
+	if err != nil || data == nil {
+		return
+	}
+	var response any
+	if err = json.Unmarshal(data, &response); err != nil {
+		return
+	}
+
+	fields := []string{}
+	if pkg.License == "" {
+		enrichment.License = joinLicenses(jsonStrings(response, e.LicenseField), models.SPDXAnd)
+		if enrichment.License != "" {
+			fields = append(fields, "license")
+		}
+	}
+	if pkg.Copyright == "" {
+		enrichment.Copyright = strings.Join(jsonStrings(response, e.CopyrightField), "; ")
+		if enrichment.Copyright != "" {
+			fields = append(fields, "copyright")
+		}
+	}
+	if len(fields) > 0 {
+		enrichment.Notes = enrichmentNote(strings.Join(fields, ", "), e.name())
+	}
+	return
+}
+
+// name returns the name of the service recorded in notes.
+func (e *HTTPEnricher) name() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	if u, err := url.Parse(e.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return e.URL
+}
+
+// fetch returns the body of a successful response, or nil if the service responded
+// with 404 Not Found. Both are cached when a cache directory is configured.
+func (e *HTTPEnricher) fetch(requestURL string) ([]byte, error) {
+	cacheFile := ""
+	if e.CacheDir != "" {
+		cacheFile = filepath.Join(e.CacheDir, fmt.Sprintf("%x.json", sha256.Sum256([]byte(requestURL))))
+		if data, err := e.Afs.ReadFile(cacheFile); err == nil {
+			log.Printf("cached: %s", requestURL)
+			if len(data) == 0 {
+				return nil, nil
+			}
+			return data, nil
+		}
+	}
+
+	e.requests <- struct{}{}
+	defer func() { <-e.requests }()
+	e.limiter.wait()
+
+	log.Printf("requesting: %s", requestURL)
+	response, err := e.Client.Get(requestURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var data []byte
+	switch response.StatusCode {
+	case http.StatusOK:
+		if data, err = io.ReadAll(response.Body); err != nil {
+			return nil, err
+		}
+	case http.StatusNotFound:
+		data = []byte{}
+	default:
+		return nil, fmt.Errorf("unexpected response from %s: %s", requestURL, response.Status)
+	}
+
+	if cacheFile != "" {
+		if err = e.Afs.MkdirAll(e.CacheDir, 0755); err == nil {
+			err = e.Afs.WriteFile(cacheFile, data, 0644)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// jsonStrings returns the string values found at the dot separated path of a decoded
+// JSON document. Numeric segments index arrays, and arrays at the end of the path
+// return each of their strings.
+func jsonStrings(value any, path string) (values []string) {
+	if path == "" {
+		return
+	}
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[segment]
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return
+			}
+			value = v[i]
+		default:
+			return
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		values = append(values, v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return
+}
+
+// rateLimiter spaces calls to wait at least interval apart.
+type rateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next call is allowed.
+func (l *rateLimiter) wait() {
+	if l.interval == 0 {
+		return
+	}
+	l.mutex.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mutex.Unlock()
+	time.Sleep(time.Until(slot))
+}
@@ -0,0 +1,157 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// testMirror is an httptest stand-in for a ClearlyDefined mirror that records the
+// requests it receives and the most requests it handled at the same time.
+type testMirror struct {
+	mutex       sync.Mutex
+	requests    []string
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (m *testMirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	m.requests = append(m.requests, r.URL.EscapedPath())
+	m.mutex.Unlock()
+
+	current := atomic.AddInt32(&m.inFlight, 1)
+	defer atomic.AddInt32(&m.inFlight, -1)
+	for {
+		maximum := atomic.LoadInt32(&m.maxInFlight)
+		if current <= maximum || atomic.CompareAndSwapInt32(&m.maxInFlight, maximum, current) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	switch r.URL.EscapedPath() {
+	case "/definitions/npm/npmjs/-/lodash/4.17.21":
+		_, _ = w.Write([]byte(`{"licensed": {"declared": "MIT", "facets": {"core": {"attribution": {"parties": ["Copyright OpenJS Foundation", "Copyright Jeremy Ashkenas"]}}}}}`))
+	case "/definitions/npm/npmjs/@types/node/20.1.0":
+		_, _ = w.Write([]byte(`{"licensed": {"declared": "mit"}}`))
+	case "/definitions/npm/npmjs/-/broken/1.0.0":
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestHTTPEnricher(afs *afero.Afero, server *httptest.Server, concurrency int) *HTTPEnricher {
+	enricher := NewHTTPEnricher(afs, server.URL+"/definitions/{type}/npmjs/{namespace}/{name}/{version}", 0, concurrency)
+	enricher.LicenseField = "licensed.declared"
+	enricher.CopyrightField = "licensed.facets.core.attribution.parties"
+	enricher.CacheDir = "cache"
+	enricher.Name = "clearlydefined"
+	return enricher
+}
+
+func TestHTTPEnricher_Enrich(t *testing.T) {
+	mirror := &testMirror{}
+	server := httptest.NewServer(mirror)
+	defer server.Close()
+
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	enricher := newTestHTTPEnricher(afs, server, 2)
+
+	kissbom := models.KissBOM{
+		Packages: []models.Package{
+			{Purl: "pkg:npm/lodash@4.17.21", Notes: "utility"},
+			{Purl: "pkg:npm/%40types/node@20.1.0", Copyright: "Copyright Microsoft"},
+			{Purl: "pkg:npm/express@4.18.2"},
+			{Purl: "pkg:npm/complete@1.0.0", License: "MIT", Copyright: "Copyright"},
+			{Purl: "pkg:npm/a@1.0.0"},
+			{Purl: "pkg:npm/b@1.0.0"},
+			{Purl: "pkg:npm/c@1.0.0"},
+		},
+	}
+
+	enriched, err := EnrichKissBOM(&kissbom, 8, enricher)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, enriched)
+	assert.Equal(t, models.Package{
+		Purl:      "pkg:npm/lodash@4.17.21",
+		License:   "MIT",
+		Copyright: "Copyright OpenJS Foundation; Copyright Jeremy Ashkenas",
+		Notes:     "utility [enriched license, copyright from clearlydefined]",
+	}, kissbom.Packages[0])
+	assert.Equal(t, "MIT", kissbom.Packages[1].License)
+	assert.Equal(t, "Copyright Microsoft", kissbom.Packages[1].Copyright)
+	assert.Equal(t, "[enriched license from clearlydefined]", kissbom.Packages[1].Notes)
+	assert.Empty(t, kissbom.Packages[2].Notes)
+	assert.Len(t, mirror.requests, 6)
+	assert.LessOrEqual(t, mirror.maxInFlight, int32(2))
+
+	// responses, including not found responses, are served from the cache
+	kissbom.Packages[0].License, kissbom.Packages[0].Copyright = "", ""
+	enriched, err = EnrichKissBOM(&kissbom, 8, enricher)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, enriched)
+	assert.Len(t, mirror.requests, 6)
+}
+
+func TestHTTPEnricher_Errors(t *testing.T) {
+	server := httptest.NewServer(&testMirror{})
+	defer server.Close()
+
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	kissbom := models.KissBOM{Packages: []models.Package{{Purl: "pkg:npm/broken@1.0.0"}}}
+
+	_, err := EnrichKissBOM(&kissbom, 1, newTestHTTPEnricher(afs, server, 1))
+	assert.ErrorContains(t, err, "500 Internal Server Error")
+	exists, _ := afs.DirExists("cache")
+	assert.False(t, exists)
+}
+
+func TestHTTPEnricher_RateLimit(t *testing.T) {
+	mirror := &testMirror{}
+	server := httptest.NewServer(mirror)
+	defer server.Close()
+
+	enricher := NewHTTPEnricher(&afero.Afero{Fs: afero.NewMemMapFs()}, server.URL, 20, 4)
+	kissbom := models.KissBOM{Packages: []models.Package{{Purl: "pkg:npm/a@1"}, {Purl: "pkg:npm/b@1"}, {Purl: "pkg:npm/c@1"}}}
+
+	start := time.Now()
+	_, err := EnrichKissBOM(&kissbom, 4, enricher)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Contains(t, mirror.requests, "/pkg:npm%2Fa@1")
+}
+
+func TestHTTPEnricher_RequestURL(t *testing.T) {
+	purl, err := packageurl.FromString("pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1")
+	assert.NoError(t, err)
+
+	enricher := NewHTTPEnricher(nil, "https://mirror.example.com/deps.dev/v3/systems/{type}/packages/{namespace}:{name}/versions/{version}", 0, 1)
+	assert.Equal(t, "https://mirror.example.com/deps.dev/v3/systems/maven/packages/org.apache.logging.log4j:log4j-core/versions/2.17.1", enricher.RequestURL(purl))
+	assert.Equal(t, "mirror.example.com", enricher.name())
+
+	enricher.URL = "https://mirror.example.com/purl/"
+	assert.Equal(t, "https://mirror.example.com/purl/pkg:maven%2Forg.apache.logging.log4j%2Flog4j-core@2.17.1", enricher.RequestURL(purl))
+}
+
+func TestJSONStrings(t *testing.T) {
+	var document any = map[string]any{
+		"licenses": []any{"MIT", "Apache-2.0", 1.0},
+		"nested":   []any{map[string]any{"name": "first"}},
+	}
+	assert.Equal(t, []string{"MIT", "Apache-2.0"}, jsonStrings(document, "licenses"))
+	assert.Equal(t, []string{"first"}, jsonStrings(document, "nested.0.name"))
+	assert.Empty(t, jsonStrings(document, "nested.1.name"))
+	assert.Empty(t, jsonStrings(document, "missing.path"))
+	assert.Empty(t, jsonStrings(document, ""))
+}
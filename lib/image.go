@@ -0,0 +1,505 @@
+package lib
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Enumeration of the files that describe the images of an image archive.
+const (
+	dockerManifest = "manifest.json" // dockerManifest lists the images of an archive written by docker save.
+	ociIndex       = "index.json"    // ociIndex lists the manifests of an OCI image layout, also written by docker save since Docker 25.
+)
+
+// Enumeration of the media types of the OCI and Docker indexes, which list the manifest
+// of an image for each platform.
+const (
+	ociMediaTypeIndex        = "application/vnd.oci.image.index.v1+json"
+	dockerMediaTypeIndex     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	ociAnnotationRefName     = "org.opencontainers.image.ref.name" // ociAnnotationRefName is the tag, or the reference, of an image of an OCI image layout.
+	containerdAnnotationName = "io.containerd.image.name"          // containerdAnnotationName is the reference of an image saved by Docker.
+)
+
+// Enumeration of the whiteout files that remove the files of the lower layers of an image.
+const (
+	whiteoutPrefix = ".wh."         // whiteoutPrefix is prefixed to the name of a removed file or directory.
+	whiteoutOpaque = ".wh..wh..opq" // whiteoutOpaque removes the content of its directory.
+)
+
+// imageManifestFiles are the manifests read by the lockfile readers, which are kept
+// along with the lockfiles when reading an image.
+var imageManifestFiles = []string{"package.json", "pyproject.toml", "Pipfile", "Cargo.toml", "composer.json"}
+
+// imageArchive is a tar archive written by docker save, or holding an OCI image layout,
+// whose files are read in place.
+type imageArchive struct {
+	file    afero.File
+	entries map[string]imageEntry // Regular files of the archive.
+	links   map[string]string     // Targets of the symbolic and hard links of the archive.
+}
+
+// imageEntry locates the content of a file of an image archive.
+type imageEntry struct {
+	offset int64
+	size   int64
+}
+
+// image is the image found in an image archive.
+type image struct {
+	Repository string   // Repository of the image (ex: docker.io/library/debian), if known.
+	Tag        string   // Tag of the image (ex: 12), if known.
+	Digest     string   // Digest of the manifest of the image or, for older archives of docker save, of its configuration.
+	Layers     []string // Files of the layers of the image, from the bottom one.
+}
+
+// ociDescriptor points to a blob of an OCI image layout.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS string `json:"os"`
+	} `json:"platform"`
+}
+
+// ociManifest is an OCI image manifest or index, or their Docker equivalents.
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// imageFiles are the files of an image kept while applying its layers.
+type imageFiles struct {
+	files    map[string][]byte // Content of the files, keyed by their path from the root.
+	symlinks map[string]string // Targets of the symbolic links, keyed by their path from the root.
+}
+
+// ReadImage builds a KissBOM from an image archive, written by docker save or holding an
+// OCI image layout, without a container runtime. The layers of the image are applied in
+// order, honoring whiteouts, and the resulting file system is read for OS packages, like
+// by ReadRootFS, and for lockfiles, like by ReadLockfile. The image
+// is the subject of the KissBOM, with a pkg:oci PURL of its digest (ex:
+// pkg:oci/debian@sha256%3A...?repository_url=docker.io/library/debian&tag=12), and
+// depends on the OS packages and on the direct dependencies of each lockfile. Packages
+// read from lockfiles note the lockfile they were found in. For multi-platform images,
+// the first platform is read.
+//
+// Parameters:
+//   - afs: The file system to read from.
+//   - filename: The image archive, which must not be compressed.
+//   - filters: Optional filters that determine which packages are kept.
+func ReadImage(afs *afero.Afero, filename string, filters ...models.Filter) (kissbom models.KissBOM, err error) {
+	archive, err := openImageArchive(afs, filename)
+	if err != nil {
+		return
+	}
+	defer archive.file.Close()
+
+	img, err := archive.image()
+	if err != nil {
+		return kissbom, fmt.Errorf("%s: %w", filename, err)
+	}
+	files := imageFiles{files: map[string][]byte{}, symlinks: map[string]string{}}
+	for _, layer := range img.Layers {
+		log.Printf("applying layer: %v", layer)
+		reader, err := archive.open(layer)
+		if err == nil {
+			err = files.apply(reader)
+		}
+		if err != nil {
+			return kissbom, fmt.Errorf("%s: %s: %w", filename, layer, err)
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	return files.kissBOM(img.purl(name), img, filters)
+}
+
+// ScanImage converts an image archive, read by ReadImage, to a KissBOM saved under the
+// name of the archive.
+func (c *Converter) ScanImage(filename string) error {
+	log.Printf("scanning: %v", filename)
+
+	kissbom, err := ReadImage(c.Afs, filename, c.Filter)
+	if err != nil {
+		return err
+	}
+	log.Printf("found %v packages", len(kissbom.Packages))
+	kissbom = c.process(kissbom)
+
+	c.OutputFileName = path.Join(c.OutputFolder, strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+	return c.writeToFile(kissbom)
+}
+
+// openImageArchive lists the files of an image archive.
+func openImageArchive(afs *afero.Afero, filename string) (*imageArchive, error) {
+	file, err := afs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	magic := make([]byte, 2)
+	if _, err := file.ReadAt(magic, 0); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		file.Close()
+		return nil, fmt.Errorf("%s: compressed image archives are not supported, decompress it first", filename)
+	}
+
+	archive := &imageArchive{file: file, entries: map[string]imageEntry{}, links: map[string]string{}}
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		name := path.Clean(header.Name)
+		switch header.Typeflag {
+		case tar.TypeReg:
+			// The content of the file follows its header.
+			offset, err := file.Seek(0, io.SeekCurrent)
+			if err != nil {
+				file.Close()
+				return nil, err
+			}
+			archive.entries[name] = imageEntry{offset: offset, size: header.Size}
+		case tar.TypeSymlink:
+			archive.links[name] = path.Join(path.Dir(name), header.Linkname)
+		case tar.TypeLink:
+			archive.links[name] = path.Clean(header.Linkname)
+		}
+	}
+	return archive, nil
+}
+
+// open returns a reader of the content of a file of the archive, following links.
+func (a *imageArchive) open(name string) (io.Reader, error) {
+	name = path.Clean(name)
+	for i := 0; i < 8 && a.links[name] != ""; i++ {
+		name = a.links[name]
+	}
+	entry, ok := a.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in the archive", name)
+	}
+	return io.NewSectionReader(a.file, entry.offset, entry.size), nil
+}
+
+// readJSON decodes a JSON file of the archive.
+func (a *imageArchive) readJSON(name string, v any) error {
+	reader, err := a.open(name)
+	if err != nil {
+		return err
+	}
+	if err = json.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// image finds the image of the archive, from the index of an OCI image layout or from
+// the manifest written by docker save.
+func (a *imageArchive) image() (img image, err error) {
+	if _, ok := a.entries[ociIndex]; ok {
+		return a.ociImage()
+	}
+
+	var manifests []struct {
+		Config   string   `json:"Config"`
+		RepoTags []string `json:"RepoTags"`
+		Layers   []string `json:"Layers"`
+	}
+	if err = a.readJSON(dockerManifest, &manifests); err != nil {
+		return
+	}
+	if len(manifests) == 0 {
+		return img, errors.New("no image found in the archive")
+	}
+	manifest := manifests[0]
+	reader, err := a.open(manifest.Config)
+	if err != nil {
+		return
+	}
+	digest := sha256.New()
+	if _, err = io.Copy(digest, reader); err != nil {
+		return
+	}
+	img.Digest = fmt.Sprintf("sha256:%x", digest.Sum(nil))
+	if len(manifest.RepoTags) > 0 {
+		img.Repository, img.Tag = splitImageReference(manifest.RepoTags[0])
+	}
+	img.Layers = manifest.Layers
+	return
+}
+
+// ociImage finds the image of an OCI image layout, the first of its index, following
+// nested indexes to the manifest of their first platform.
+func (a *imageArchive) ociImage() (img image, err error) {
+	var index ociManifest
+	if err = a.readJSON(ociIndex, &index); err != nil {
+		return
+	}
+	if len(index.Manifests) == 0 {
+		return img, errors.New("no image found in the archive")
+	}
+	descriptor := index.Manifests[0]
+	img.Digest = descriptor.Digest
+	reference := models.FirstNonEmpty(descriptor.Annotations[containerdAnnotationName], descriptor.Annotations[ociAnnotationRefName])
+	if strings.ContainsAny(reference, "/:") {
+		img.Repository, img.Tag = splitImageReference(reference)
+	} else {
+		// The reference name of an OCI image layout is usually only a tag.
+		img.Tag = reference
+	}
+
+	for depth := 0; ; depth++ {
+		var manifest ociManifest
+		if err = a.readJSON(ociBlob(descriptor.Digest), &manifest); err != nil {
+			return
+		}
+		isIndex := manifest.MediaType == ociMediaTypeIndex || manifest.MediaType == dockerMediaTypeIndex || manifest.Manifests != nil
+		if !isIndex {
+			for _, layer := range manifest.Layers {
+				img.Layers = append(img.Layers, ociBlob(layer.Digest))
+			}
+			return img, nil
+		}
+		if depth == 4 {
+			return img, errors.New("too many nested image indexes")
+		}
+
+		found := false
+		for _, d := range manifest.Manifests {
+			// Attestations are listed with an unknown platform.
+			if d.Platform == nil || d.Platform.OS != "unknown" {
+				descriptor, found = d, true
+				break
+			}
+		}
+		if !found {
+			return img, errors.New("no image found in the image index")
+		}
+	}
+}
+
+// ociBlob returns the file of a blob of an OCI image layout, given its digest.
+func ociBlob(digest string) string {
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+	return path.Join("blobs", algorithm, encoded)
+}
+
+// purl returns the pkg:oci PURL of the image, named after its repository or, when the
+// archive does not record it, after the provided name.
+func (img image) purl(name string) string {
+	qualifiers := map[string]string{}
+	if img.Repository != "" {
+		name = path.Base(img.Repository)
+		if strings.Contains(img.Repository, "/") {
+			qualifiers["repository_url"] = img.Repository
+		}
+	}
+	if img.Tag != "" {
+		qualifiers["tag"] = img.Tag
+	}
+	return packageurl.NewPackageURL(packageurl.TypeOCI, "", strings.ToLower(name), img.Digest, packageurl.QualifiersFromMap(qualifiers), "").ToString()
+}
+
+// splitImageReference splits an image reference (ex: localhost:5000/app:1.0) into its
+// repository and tag, ignoring its digest.
+func splitImageReference(reference string) (repository string, tag string) {
+	reference, _, _ = strings.Cut(reference, "@")
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i+1:]
+	}
+	return reference, ""
+}
+
+// apply applies a layer, a tar archive optionally compressed with gzip: the files and
+// directories it removes with whiteouts are removed from the files of the lower layers,
+// then the files it adds are kept.
+func (f *imageFiles) apply(layer io.Reader) error {
+	buffered := bufio.NewReader(layer)
+	var reader io.Reader = buffered
+	magic, _ := buffered.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		decompressed, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer decompressed.Close()
+		reader = decompressed
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return errors.New("zstd compressed layers are not supported")
+	}
+
+	added, symlinks, hardlinks := map[string][]byte{}, map[string]string{}, map[string]string{}
+	removed, opaque := []string{}, []string{}
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dir, base := path.Split(name)
+		switch {
+		case base == whiteoutOpaque:
+			opaque = append(opaque, path.Clean(dir))
+		case strings.HasPrefix(base, whiteoutPrefix):
+			removed = append(removed, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+		case !isImageFileKept(name):
+			continue
+		case header.Typeflag == tar.TypeReg:
+			data, err := io.ReadAll(archive)
+			if err != nil {
+				return err
+			}
+			added[name] = data
+		case header.Typeflag == tar.TypeSymlink:
+			target := header.Linkname
+			if !path.IsAbs(target) {
+				target = path.Join("/", dir, target)
+			}
+			symlinks[name] = strings.TrimPrefix(path.Clean(target), "/")
+		case header.Typeflag == tar.TypeLink:
+			hardlinks[name] = strings.TrimPrefix(path.Clean("/"+header.Linkname), "/")
+		}
+	}
+
+	for _, name := range removed {
+		f.remove(name, false)
+	}
+	for _, dir := range opaque {
+		f.remove(dir, true)
+	}
+	for name, data := range added {
+		f.files[name] = data
+		delete(f.symlinks, name)
+	}
+	for name, target := range symlinks {
+		f.symlinks[name] = target
+		delete(f.files, name)
+	}
+	for name, target := range hardlinks {
+		if data, ok := f.files[target]; ok {
+			f.files[name] = data
+		}
+	}
+	return nil
+}
+
+// remove removes a file or a directory, or only the content of the directory.
+func (f *imageFiles) remove(name string, contentOnly bool) {
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	for file := range f.files {
+		if (file == name && !contentOnly) || strings.HasPrefix(file, prefix) {
+			delete(f.files, file)
+		}
+	}
+	for link := range f.symlinks {
+		if (link == name && !contentOnly) || strings.HasPrefix(link, prefix) {
+			delete(f.symlinks, link)
+		}
+	}
+}
+
+// isImageFileKept returns true if a file of an image, given by its path from the root, is
+// read by ReadRootFS or is a lockfile or manifest outside of the directories of
+// installed packages and dependency caches.
+func isImageFileKept(name string) bool {
+	switch {
+	case containsString(osReleaseFiles, name), containsString(rpmDatabases, name):
+		return true
+	case name == dpkgStatus, name == apkInstalled, path.Dir(name) == dpkgStatusDir:
+		return true
+	case path.Base(name) == "copyright" && path.Dir(path.Dir(name)) == dpkgDocDir:
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		if containsString(dependencyDirs, dir) {
+			return false
+		}
+	}
+	return IsLockfile(name) || containsString(imageManifestFiles, path.Base(name))
+}
+
+// kissBOM reads the OS packages and the lockfiles of the files of an image.
+// Symbolic links to files that are kept are read as copies of the files.
+func (f *imageFiles) kissBOM(subject string, img image, filters []models.Filter) (kissbom models.KissBOM, err error) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	lockfiles := []string{}
+	write := func(name string, data []byte) error {
+		if IsLockfile(name) {
+			lockfiles = append(lockfiles, name)
+		}
+		return afs.WriteFile("/"+name, data, 0644)
+	}
+	for name, data := range f.files {
+		if err = write(name, data); err != nil {
+			return
+		}
+	}
+	for name, target := range f.symlinks {
+		if data, ok := f.files[target]; ok {
+			if err = write(name, data); err != nil {
+				return
+			}
+		}
+	}
+
+	kissbom, err = ReadRootFS(afs, "/", filters...)
+	if err != nil && !errors.Is(err, errNoPackageDatabase) {
+		return
+	}
+	err = nil
+	kissbom.Metadata = &models.Metadata{Subject: subject, Name: img.Repository, Version: img.Tag, Digest: img.Digest}
+	direct := []string{}
+	for _, p := range kissbom.Packages {
+		direct = append(direct, p.Purl)
+	}
+
+	sort.Strings(lockfiles)
+	for _, name := range lockfiles {
+		project, err := ReadLockfile(afs, "/"+name, filters...)
+		if err != nil {
+			log.Printf("skipping %s: %v", name, err)
+			continue
+		}
+		for _, p := range project.Packages {
+			p.Notes = withSources(p.Notes, []string{name})
+			kissbom.Packages = append(kissbom.Packages, p)
+		}
+		if project.Metadata.Subject != "" {
+			direct = append(direct, project.Dependencies[project.Metadata.Subject]...)
+		}
+		kissbom.Dependencies = models.MergeDependencies(kissbom.Dependencies, project.Dependencies)
+	}
+	kissbom.Dependencies = models.MergeDependencies(kissbom.Dependencies, map[string][]string{subject: direct})
+	return kissbom, nil
+}
@@ -0,0 +1,229 @@
+package lib
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// testTarEntry is a file, or a link when Link is set, of a tar archive built by testTar.
+type testTarEntry struct {
+	Name string
+	Body string
+	Link string
+	Type byte
+}
+
+// testTar builds a tar archive of the entries, optionally compressed with gzip.
+func testTar(t *testing.T, compress bool, entries ...testTarEntry) []byte {
+	var buffer bytes.Buffer
+	var gz *gzip.Writer
+	writer := tar.NewWriter(&buffer)
+	if compress {
+		gz = gzip.NewWriter(&buffer)
+		writer = tar.NewWriter(gz)
+	}
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.Name, Mode: 0644, Size: int64(len(entry.Body)), Typeflag: tar.TypeReg, Linkname: entry.Link}
+		if entry.Type != 0 {
+			header.Typeflag, header.Size = entry.Type, 0
+		}
+		assert.NoError(t, writer.WriteHeader(header))
+		_, err := writer.Write([]byte(entry.Body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+	if gz != nil {
+		assert.NoError(t, gz.Close())
+	}
+	return buffer.Bytes()
+}
+
+// testImageLayers returns the layers of a test image: the first one installs Alpine
+// packages and lockfiles, and the second one removes a lockfile and the content of a
+// directory with whiteouts.
+func testImageLayers(t *testing.T) [][]byte {
+	return [][]byte{
+		testTar(t, true,
+			testTarEntry{Name: "usr/lib/os-release", Body: "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.19.1\n"},
+			testTarEntry{Name: "etc/os-release", Link: "../usr/lib/os-release", Type: tar.TypeSymlink},
+			testTarEntry{Name: "lib/apk/db/installed", Body: testApkInstalled},
+			testTarEntry{Name: "app/package-lock.json", Body: testNpmLockV3},
+			testTarEntry{Name: "app/node_modules/debug/package-lock.json", Body: testNpmLockV3},
+			testTarEntry{Name: "old/Cargo.lock", Body: testCargoLock},
+			testTarEntry{Name: "data/poetry.lock", Body: testPoetryLock},
+			testTarEntry{Name: "usr/bin/busybox", Body: "\x7fELF"},
+		),
+		testTar(t, false,
+			testTarEntry{Name: "old/.wh.Cargo.lock"},
+			testTarEntry{Name: "data/.wh..wh..opq"},
+		),
+	}
+}
+
+// testImageDigest returns the SHA-256 digest of a blob.
+func testImageDigest(blob []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(blob))
+}
+
+func TestReadImage_Docker(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	layers := testImageLayers(t)
+	config := `{"architecture":"amd64","os":"linux"}`
+	archive := testTar(t, false,
+		testTarEntry{Name: "manifest.json", Body: `[{"Config":"0123.json","RepoTags":["docker.io/library/alpine:3.19"],"Layers":["a/layer.tar","b/layer.tar"]}]`},
+		testTarEntry{Name: "0123.json", Body: config},
+		testTarEntry{Name: "a/layer.tar", Body: string(layers[0])},
+		testTarEntry{Name: "b/layer.tar", Link: "c/layer.tar", Type: tar.TypeLink},
+		testTarEntry{Name: "c/layer.tar", Body: string(layers[1])},
+	)
+	assert.NoError(t, afs.WriteFile("alpine.tar", archive, 0644))
+
+	kissbom, err := ReadImage(afs, "alpine.tar")
+	assert.NoError(t, err)
+	digest := testImageDigest([]byte(config))
+	subject := "pkg:oci/alpine@sha256%3A" + digest[7:] + "?repository_url=docker.io%2Flibrary%2Falpine&tag=3.19"
+	assert.Equal(t, &models.Metadata{Subject: subject, Name: "docker.io/library/alpine", Version: "3.19", Digest: digest}, kissbom.Metadata)
+
+	purls := []string{}
+	for _, p := range kissbom.Packages {
+		purls = append(purls, p.Purl)
+	}
+	assert.Equal(t, []string{
+		"pkg:apk/alpine/busybox@1.36.1-r15?arch=x86_64&distro=alpine-3.19.1",
+		"pkg:apk/alpine/musl@1.2.4-r2?arch=x86_64&distro=alpine-3.19.1",
+		"pkg:apk/alpine/ssl_client@1.36.1-r15?arch=x86_64&distro=alpine-3.19.1",
+		"pkg:npm/%40babel/core@7.24.0",
+		"pkg:npm/debug@4.3.4",
+		"pkg:npm/fsevents@2.3.3",
+		"pkg:npm/jest@29.7.0",
+		"pkg:npm/ms@2.0.0",
+		"pkg:npm/ms@2.1.2",
+		"pkg:npm/string-width@4.2.3",
+	}, purls)
+	assert.Equal(t, "[dev] [sources: app/package-lock.json]", kissbom.Packages[6].Notes)
+	assert.Equal(t, []string{
+		"pkg:apk/alpine/busybox@1.36.1-r15?arch=x86_64&distro=alpine-3.19.1",
+		"pkg:apk/alpine/musl@1.2.4-r2?arch=x86_64&distro=alpine-3.19.1",
+		"pkg:apk/alpine/ssl_client@1.36.1-r15?arch=x86_64&distro=alpine-3.19.1",
+		"pkg:npm/%40babel/core@7.24.0",
+		"pkg:npm/jest@29.7.0",
+		"pkg:npm/ms@2.1.2",
+	}, kissbom.Dependencies[subject])
+	assert.Equal(t, []string{"pkg:npm/debug@4.3.4"}, kissbom.Dependencies["pkg:npm/%40babel/core@7.24.0"])
+}
+
+func TestReadImage_OCI(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	layers := testImageLayers(t)
+	manifest := fmt.Sprintf(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[{"digest":"%s"},{"digest":"%s"}]}`, testImageDigest(layers[0]), testImageDigest(layers[1]))
+	index := fmt.Sprintf(`{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+		{"digest":"sha256:0000","platform":{"os":"unknown","architecture":"unknown"}},
+		{"digest":"%s","platform":{"os":"linux","architecture":"amd64"}}]}`, testImageDigest([]byte(manifest)))
+	blob := func(data []byte) testTarEntry {
+		return testTarEntry{Name: "blobs/sha256/" + testImageDigest(data)[7:], Body: string(data)}
+	}
+	archive := testTar(t, false,
+		testTarEntry{Name: "oci-layout", Body: `{"imageLayoutVersion":"1.0.0"}`},
+		testTarEntry{Name: "index.json", Body: fmt.Sprintf(`{"manifests":[{"mediaType":"application/vnd.oci.image.index.v1+json","digest":"%s","annotations":{"org.opencontainers.image.ref.name":"3.19"}}]}`, testImageDigest([]byte(index)))},
+		blob([]byte(index)),
+		blob([]byte(manifest)),
+		blob(layers[0]),
+		blob(layers[1]),
+	)
+	assert.NoError(t, afs.WriteFile("images/Alpine.tar", archive, 0644))
+
+	kissbom, err := ReadImage(afs, "images/Alpine.tar", models.Filter{IncludeTypes: []string{"apk"}})
+	assert.NoError(t, err)
+	digest := testImageDigest([]byte(index))
+	assert.Equal(t, &models.Metadata{Subject: "pkg:oci/alpine@sha256%3A" + digest[7:] + "?tag=3.19", Version: "3.19", Digest: digest}, kissbom.Metadata)
+	assert.Len(t, kissbom.Packages, 3)
+}
+
+func TestReadImage_Errors(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("compressed.tar.gz", testTar(t, true, testTarEntry{Name: "manifest.json", Body: "[]"}), 0644))
+	_, err := ReadImage(afs, "compressed.tar.gz")
+	assert.EqualError(t, err, "compressed.tar.gz: compressed image archives are not supported, decompress it first")
+
+	assert.NoError(t, afs.WriteFile("empty.tar", testTar(t, false, testTarEntry{Name: "manifest.json", Body: "[]"}), 0644))
+	_, err = ReadImage(afs, "empty.tar")
+	assert.EqualError(t, err, "empty.tar: no image found in the archive")
+
+	assert.NoError(t, afs.WriteFile("missing.tar", testTar(t, false, testTarEntry{Name: "manifest.json", Body: `[{"Config":"c.json","Layers":["l.tar"]}]`}), 0644))
+	_, err = ReadImage(afs, "missing.tar")
+	assert.EqualError(t, err, "missing.tar: c.json not found in the archive")
+
+	_, err = ReadImage(afs, "absent.tar")
+	assert.Error(t, err)
+}
+
+func TestImageFiles_Apply(t *testing.T) {
+	files := imageFiles{files: map[string][]byte{}, symlinks: map[string]string{}}
+	assert.NoError(t, files.apply(bytes.NewReader(testTar(t, false,
+		testTarEntry{Name: "./srv/a/package-lock.json", Body: "a"},
+		testTarEntry{Name: "srv/b/package-lock.json", Body: "b"},
+		testTarEntry{Name: "srv/c/Cargo.lock", Body: "c"},
+		testTarEntry{Name: "srv/notes.txt", Body: "ignored"},
+	))))
+	assert.NoError(t, files.apply(bytes.NewReader(testTar(t, false,
+		testTarEntry{Name: "srv/.wh.a"},
+		testTarEntry{Name: "srv/b/.wh..wh..opq"},
+		testTarEntry{Name: "srv/b/yarn.lock", Body: "b2"},
+		testTarEntry{Name: "srv/d/Cargo.lock", Link: "srv/c/Cargo.lock", Type: tar.TypeLink},
+	))))
+	assert.Equal(t, map[string][]byte{
+		"srv/b/yarn.lock":  []byte("b2"),
+		"srv/c/Cargo.lock": []byte("c"),
+		"srv/d/Cargo.lock": []byte("c"),
+	}, files.files)
+
+	assert.EqualError(t, files.apply(bytes.NewReader([]byte{0x28, 0xb5, 0x2f, 0xfd})), "zstd compressed layers are not supported")
+}
+
+func TestIsImageFileKept(t *testing.T) {
+	assert.True(t, isImageFileKept("etc/os-release"))
+	assert.True(t, isImageFileKept("var/lib/dpkg/status.d/libc6"))
+	assert.True(t, isImageFileKept("usr/share/doc/curl/copyright"))
+	assert.True(t, isImageFileKept("usr/lib/sysimage/rpm/rpmdb.sqlite"))
+	assert.True(t, isImageFileKept("srv/app/requirements.txt"))
+	assert.True(t, isImageFileKept("srv/app/pyproject.toml"))
+	assert.False(t, isImageFileKept("srv/app/node_modules/debug/package.json"))
+	assert.False(t, isImageFileKept("usr/lib/python3/dist-packages/pip/requirements.txt"))
+	assert.False(t, isImageFileKept("usr/share/doc/curl/changelog.gz"))
+}
+
+func TestSplitImageReference(t *testing.T) {
+	repository, tag := splitImageReference("localhost:5000/team/app:1.0@sha256:0123")
+	assert.Equal(t, "localhost:5000/team/app", repository)
+	assert.Equal(t, "1.0", tag)
+	repository, tag = splitImageReference("localhost:5000/app")
+	assert.Equal(t, "localhost:5000/app", repository)
+	assert.Equal(t, "", tag)
+}
+
+func TestConverter_ScanImage(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	layers := testImageLayers(t)
+	archive := testTar(t, false,
+		testTarEntry{Name: "manifest.json", Body: `[{"Config":"c.json","Layers":["l.tar"]}]`},
+		testTarEntry{Name: "c.json", Body: "{}"},
+		testTarEntry{Name: "l.tar", Body: string(layers[0])},
+	)
+	assert.NoError(t, afs.WriteFile("images/app.tar", archive, 0644))
+
+	converter := &Converter{Afs: afs, OutputFolder: "out", OutputFormat: models.OptionJSON}
+	assert.NoError(t, converter.ScanImage("images/app.tar"))
+	assert.Equal(t, "out/app.json", converter.OutputFileName)
+	exists, err := afs.Exists("out/app.json")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
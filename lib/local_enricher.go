@@ -0,0 +1,425 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// packageMetadata is the license and copyright information found for a package in
+// a local source, along with the files it was read from.
+type packageMetadata struct {
+	License         string
+	LicenseOrigin   string
+	Copyright       string
+	CopyrightOrigin string
+}
+
+// LocalEnricher fills in missing licenses and copyrights from local package metadata:
+// a node_modules tree, a Go module cache, a Python site-packages directory or a Maven
+// repository.
+type LocalEnricher struct {
+	Afs     *afero.Afero // Afero file system abstraction for file operations.
+	Sources []string     // Directories searched for package metadata, in order.
+
+	mutex       sync.Mutex                   // Guards npmPackages.
+	npmPackages map[string]map[string]string // Package directories of each source, keyed by name@version.
+}
+
+// NewLocalEnricher creates a LocalEnricher that searches the provided directories.
+func NewLocalEnricher(afs *afero.Afero, sources ...string) *LocalEnricher {
+	return &LocalEnricher{Afs: afs, Sources: sources, npmPackages: map[string]map[string]string{}}
+}
+
+// Enrich looks up the missing license and copyright of the package in the sources,
+// noting the file each value came from.
+func (e *LocalEnricher) Enrich(pkg models.Package) (enrichment Enrichment, err error) {
+	if pkg.License != "" && pkg.Copyright != "" {
+		return
+	}
+	purl, err := packageurl.FromString(strings.TrimSpace(pkg.Purl))
+	if err != nil || purl.Version == "" {
+		return enrichment, nil
+	}
+
+	found := packageMetadata{}
+	for _, source := range e.Sources {
+		metadata := e.lookup(source, purl)
+		if found.License == "" && metadata.License != "" {
+			found.License, found.LicenseOrigin = metadata.License, metadata.LicenseOrigin
+		}
+		if found.Copyright == "" && metadata.Copyright != "" {
+			found.Copyright, found.CopyrightOrigin = metadata.Copyright, metadata.CopyrightOrigin
+		}
+	}
+
+	if pkg.License == "" {
+		enrichment.License = found.License
+	}
+	if pkg.Copyright == "" {
+		enrichment.Copyright = found.Copyright
+	}
+	switch {
+	case enrichment.License != "" && enrichment.Copyright != "" && found.LicenseOrigin == found.CopyrightOrigin:
+		enrichment.Notes = enrichmentNote("license, copyright", found.LicenseOrigin)
+	case enrichment.License != "" && enrichment.Copyright != "":
+		enrichment.Notes = enrichmentNote("license", found.LicenseOrigin) + " " + enrichmentNote("copyright", found.CopyrightOrigin)
+	case enrichment.License != "":
+		enrichment.Notes = enrichmentNote("license", found.LicenseOrigin)
+	case enrichment.Copyright != "":
+		enrichment.Notes = enrichmentNote("copyright", found.CopyrightOrigin)
+	}
+	return
+}
+
+// lookup finds the metadata of the package in a single source, based on its PURL type.
+func (e *LocalEnricher) lookup(source string, purl packageurl.PackageURL) packageMetadata {
+	switch purl.Type {
+	case packageurl.TypeNPM:
+		return e.lookupNpm(source, purl)
+	case packageurl.TypeGolang:
+		return e.lookupGo(source, purl)
+	case packageurl.TypePyPi:
+		return e.lookupPyPI(source, purl)
+	case packageurl.TypeMaven:
+		return e.lookupMaven(source, purl)
+	}
+	return packageMetadata{}
+}
+
+// npmManifest is the subset of a package.json file used for enrichment and to read
+// lockfiles. Lockfile entries of npm 7 and later share these fields.
+type npmManifest struct {
+	Name     string          `json:"name"`
+	Version  string          `json:"version"`
+	License  json.RawMessage `json:"license"`
+	Licenses []struct {
+		Type string `json:"type"`
+	} `json:"licenses"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// license returns the license of the manifest, which may be an SPDX expression, a
+// legacy {"type": ...} object or a legacy list of alternative licenses.
+func (m npmManifest) license() string {
+	var license string
+	if json.Unmarshal(m.License, &license) == nil && license != "" {
+		return models.NormalizeLicense(license)
+	}
+	var legacy struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(m.License, &legacy) == nil && legacy.Type != "" {
+		return models.NormalizeLicense(legacy.Type)
+	}
+	names := []string{}
+	for _, l := range m.Licenses {
+		names = append(names, l.Type)
+	}
+	return joinLicenses(names, models.SPDXOr)
+}
+
+// lookupNpm finds a package in the node_modules directories of the source, including
+// nested node_modules directories holding other versions of the package.
+func (e *LocalEnricher) lookupNpm(source string, purl packageurl.PackageURL) (metadata packageMetadata) {
+	e.mutex.Lock()
+	packages, ok := e.npmPackages[source]
+	if !ok {
+		packages = e.indexNpm(source)
+		e.npmPackages[source] = packages
+	}
+	e.mutex.Unlock()
+
+	name := purl.Name
+	if purl.Namespace != "" {
+		name = purl.Namespace + "/" + purl.Name
+	}
+	dir, ok := packages[name+"@"+purl.Version]
+	if !ok {
+		return
+	}
+
+	manifest := filepath.Join(dir, "package.json")
+	data, err := e.Afs.ReadFile(manifest)
+	if err != nil {
+		return
+	}
+	var m npmManifest
+	if json.Unmarshal(data, &m) != nil {
+		return
+	}
+	if license := m.license(); license != "" {
+		metadata.License, metadata.LicenseOrigin = license, manifest
+	}
+	e.readLicenseFile(dir, &metadata)
+	return
+}
+
+// indexNpm walks the source and indexes the directory of every package installed
+// in a node_modules directory by name@version.
+func (e *LocalEnricher) indexNpm(source string) map[string]string {
+	packages := map[string]string{}
+	_ = e.Afs.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Name() != "package.json" || !isNodeModule(filepath.Dir(path)) {
+			return nil
+		}
+		data, err := e.Afs.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var m npmManifest
+		if json.Unmarshal(data, &m) == nil && m.Name != "" {
+			key := m.Name + "@" + m.Version
+			if _, ok := packages[key]; !ok {
+				packages[key] = filepath.Dir(path)
+			}
+		}
+		return nil
+	})
+	log.Printf("indexed %v npm packages in %s", len(packages), source)
+	return packages
+}
+
+// isNodeModule returns true if the directory is a package installed directly in a
+// node_modules directory (node_modules/name or node_modules/@scope/name).
+func isNodeModule(dir string) bool {
+	segments := strings.Split(filepath.ToSlash(dir), "/")
+	n := len(segments)
+	switch {
+	case n >= 2 && segments[n-2] == "node_modules":
+		return true
+	case n >= 3 && segments[n-3] == "node_modules" && strings.HasPrefix(segments[n-2], "@"):
+		return true
+	}
+	return false
+}
+
+// lookupGo finds a module in a Go module cache, where the source is either the
+// module cache itself (GOMODCACHE) or a GOPATH containing pkg/mod.
+func (e *LocalEnricher) lookupGo(source string, purl packageurl.PackageURL) (metadata packageMetadata) {
+	module := purl.Name
+	if purl.Namespace != "" {
+		module = purl.Namespace + "/" + purl.Name
+	}
+	for _, root := range []string{source, filepath.Join(source, "pkg", "mod")} {
+		if dir, ok := e.findModuleDir(root, module+"@"+purl.Version); ok {
+			e.readLicenseFile(dir, &metadata)
+			return
+		}
+	}
+	return
+}
+
+// findModuleDir resolves the directory of module@version in a module cache. Segments
+// are matched without regard to case, as PURLs may lower case the module path while
+// the module cache escapes upper case letters as "!" followed by the lower case letter.
+func (e *LocalEnricher) findModuleDir(root string, module string) (string, bool) {
+	dir := root
+	for _, segment := range strings.Split(module, "/") {
+		entries, err := e.Afs.ReadDir(dir)
+		if err != nil {
+			return "", false
+		}
+		found := false
+		for _, entry := range entries {
+			if entry.IsDir() && strings.EqualFold(unescapeModulePath(entry.Name()), segment) {
+				dir, found = filepath.Join(dir, entry.Name()), true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return dir, true
+}
+
+// unescapeModulePath reverses the module cache escaping of upper case letters.
+func unescapeModulePath(escaped string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range escaped {
+		switch {
+		case r == '!':
+			upper = true
+			continue
+		case upper:
+			r = unicode.ToUpper(r)
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// lookupPyPI finds a distribution in a Python site-packages directory by reading the
+// METADATA file of its dist-info directory.
+func (e *LocalEnricher) lookupPyPI(source string, purl packageurl.PackageURL) (metadata packageMetadata) {
+	entries, err := e.Afs.ReadDir(source)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name, version, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".dist-info"), "-")
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".dist-info") || !ok {
+			continue
+		}
+		if osvKey("PyPI", name) != osvKey("PyPI", purl.Name) || comparePEP440(version, purl.Version) != 0 {
+			continue
+		}
+
+		dir := filepath.Join(source, entry.Name())
+		file := filepath.Join(dir, "METADATA")
+		if data, err := e.Afs.ReadFile(file); err == nil {
+			if license := pythonLicense(data); license != "" {
+				metadata.License, metadata.LicenseOrigin = license, file
+			}
+		}
+		e.readLicenseFile(dir, &metadata)
+		e.readLicenseFile(filepath.Join(dir, "licenses"), &metadata)
+		return
+	}
+	return
+}
+
+// pythonLicense reads the license of a distribution from the headers of its METADATA
+// file, preferring the License-Expression header, then the License header and then
+// license classifiers.
+func pythonLicense(data []byte) string {
+	headers := map[string]string{}
+	classifiers := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(key) {
+		case "license-expression", "license":
+			headers[strings.ToLower(key)] = value
+		case "classifier":
+			if strings.HasPrefix(value, "License ::") {
+				segments := strings.Split(value, "::")
+				classifiers = append(classifiers, strings.TrimSpace(segments[len(segments)-1]))
+			}
+		}
+	}
+
+	if expression := headers["license-expression"]; expression != "" {
+		return models.NormalizeLicense(expression)
+	}
+	if license := headers["license"]; license != "" && !strings.EqualFold(license, "UNKNOWN") {
+		if id := models.IdentifyLicenseText(license); id != "" {
+			return id
+		}
+		if len(license) <= 100 {
+			return models.NormalizeLicense(license)
+		}
+	}
+	return joinLicenses(classifiers, models.SPDXAnd)
+}
+
+// mavenPOM is the subset of a POM file used for enrichment.
+type mavenPOM struct {
+	Licenses []struct {
+		Name string `xml:"name"`
+		URL  string `xml:"url"`
+	} `xml:"licenses>license"`
+}
+
+// lookupMaven finds an artifact in a Maven repository, where the source is either the
+// repository itself or the ~/.m2 directory containing it.
+func (e *LocalEnricher) lookupMaven(source string, purl packageurl.PackageURL) (metadata packageMetadata) {
+	file := filepath.Join(strings.Split(purl.Namespace, ".")...)
+	file = filepath.Join(file, purl.Name, purl.Version, fmt.Sprintf("%s-%s.pom", purl.Name, purl.Version))
+	for _, root := range []string{source, filepath.Join(source, "repository")} {
+		data, err := e.Afs.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			continue
+		}
+		var pom mavenPOM
+		if xml.Unmarshal(data, &pom) != nil {
+			return
+		}
+		names := []string{}
+		for _, l := range pom.Licenses {
+			names = append(names, models.FirstNonEmpty(l.Name, l.URL))
+		}
+		if license := joinLicenses(names, models.SPDXAnd); license != "" {
+			metadata.License, metadata.LicenseOrigin = license, filepath.Join(root, file)
+		}
+		return
+	}
+	return
+}
+
+// readLicenseFile reads the first license file (LICENSE, LICENCE or COPYING) in the
+// directory, identifying the license from its text and extracting the copyright
+// statement for any values that are still missing.
+func (e *LocalEnricher) readLicenseFile(dir string, metadata *packageMetadata) {
+	entries, err := e.Afs.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := strings.ToUpper(entry.Name())
+		if entry.IsDir() || !(strings.HasPrefix(name, "LICENSE") || strings.HasPrefix(name, "LICENCE") || strings.HasPrefix(name, "COPYING")) {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		data, err := e.Afs.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if metadata.License == "" {
+			if license := models.IdentifyLicenseText(string(data)); license != "" {
+				metadata.License, metadata.LicenseOrigin = license, file
+			}
+		}
+		if metadata.Copyright == "" {
+			if copyright := models.FindCopyright(string(data)); copyright != "" {
+				metadata.Copyright, metadata.CopyrightOrigin = copyright, file
+			}
+		}
+		return
+	}
+}
+
+// joinLicenses normalizes each license name and combines them into a single
+// expression using the provided operator.
+func joinLicenses(names []string, operator string) string {
+	licenses := []string{}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			licenses = appendUnique(licenses, models.NormalizeLicense(name))
+		}
+	}
+	if len(licenses) > 1 {
+		for i, l := range licenses {
+			if strings.Contains(l, " ") {
+				licenses[i] = "(" + l + ")"
+			}
+		}
+	}
+	return strings.Join(licenses, " "+operator+" ")
+}
@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const mitLicense = `MIT License
+
+Copyright (c) 2021 Example Authors
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+`
+
+const pythonMetadata = `Metadata-Version: 2.1
+Name: requests
+Version: 2.26.0
+License: Apache 2.0
+Classifier: License :: OSI Approved :: Apache Software License
+
+Requests is an HTTP library.
+`
+
+const log4jPOM = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+	<licenses>
+		<license>
+			<name>Apache License, Version 2.0</name>
+			<url>https://www.apache.org/licenses/LICENSE-2.0.txt</url>
+		</license>
+	</licenses>
+</project>`
+
+func TestLocalEnricher_Enrich(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	files := map[string]string{
+		"app/node_modules/lodash/package.json":                                           `{"name": "lodash", "version": "4.17.21", "license": "MIT"}`,
+		"app/node_modules/lodash/LICENSE":                                                mitLicense,
+		"app/node_modules/@types/node/package.json":                                      `{"name": "@types/node", "version": "20.1.0", "licenses": [{"type": "MIT"}, {"type": "Apache-2.0"}]}`,
+		"app/node_modules/a/node_modules/lodash/package.json":                            `{"name": "lodash", "version": "3.10.1", "license": {"type": "MIT"}}`,
+		"gopath/pkg/mod/github.com/!burnt!sushi/toml@v1.3.2/LICENSE":                     mitLicense,
+		"site-packages/requests-2.26.0.dist-info/METADATA":                               pythonMetadata,
+		"site-packages/requests-2.26.0.dist-info/licenses/LICENSE":                       "Copyright 2019 Kenneth Reitz",
+		"m2/repository/org/apache/logging/log4j/log4j-core/2.17.1/log4j-core-2.17.1.pom": log4jPOM,
+	}
+	for name, content := range files {
+		assert.NoError(t, afs.WriteFile(name, []byte(content), 0644))
+	}
+
+	kissbom := models.KissBOM{
+		Packages: []models.Package{
+			{Purl: "pkg:npm/lodash@4.17.21"},
+			{Purl: "pkg:npm/lodash@3.10.1", License: "BSD-3-Clause", Copyright: "Copyright JS Foundation"},
+			{Purl: "pkg:npm/lodash@3.10.1", Copyright: "Copyright JS Foundation"},
+			{Purl: "pkg:npm/%40types/node@20.1.0", Notes: "types"},
+			{Purl: "pkg:golang/github.com/BurntSushi/toml@v1.3.2", License: "MIT"},
+			{Purl: "pkg:pypi/requests@2.26.0"},
+			{Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1"},
+			{Purl: "pkg:npm/express@4.18.2"},
+		},
+	}
+
+	enriched, err := EnrichKissBOM(&kissbom, 2, NewLocalEnricher(afs, "app", "gopath", "site-packages", "m2"))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, enriched)
+
+	assert.Equal(t, models.Package{
+		Purl:      "pkg:npm/lodash@4.17.21",
+		License:   "MIT",
+		Copyright: "Copyright (c) 2021 Example Authors",
+		Notes:     "[enriched license from app/node_modules/lodash/package.json] [enriched copyright from app/node_modules/lodash/LICENSE]",
+	}, kissbom.Packages[0])
+	assert.Equal(t, "BSD-3-Clause", kissbom.Packages[1].License)
+	assert.Equal(t, "MIT", kissbom.Packages[2].License)
+	assert.Equal(t, "MIT OR Apache-2.0", kissbom.Packages[3].License)
+	assert.Equal(t, "types [enriched license from app/node_modules/@types/node/package.json]", kissbom.Packages[3].Notes)
+	assert.Equal(t, "Copyright (c) 2021 Example Authors", kissbom.Packages[4].Copyright)
+	assert.Equal(t, "[enriched copyright from gopath/pkg/mod/github.com/!burnt!sushi/toml@v1.3.2/LICENSE]", kissbom.Packages[4].Notes)
+	assert.Equal(t, "Apache-2.0", kissbom.Packages[5].License)
+	assert.Equal(t, "Copyright 2019 Kenneth Reitz", kissbom.Packages[5].Copyright)
+	assert.Equal(t, "Apache-2.0", kissbom.Packages[6].License)
+	assert.Equal(t, "[enriched license from m2/repository/org/apache/logging/log4j/log4j-core/2.17.1/log4j-core-2.17.1.pom]", kissbom.Packages[6].Notes)
+	assert.Empty(t, kissbom.Packages[7].License)
+	assert.Empty(t, kissbom.Packages[7].Notes)
+}
+
+func TestPythonLicense(t *testing.T) {
+	assert.Equal(t, "MIT", pythonLicense([]byte("License-Expression: mit\nLicense: BSD\n\n")))
+	assert.Equal(t, "BSD-3-Clause AND Apache-2.0", pythonLicense([]byte("License: UNKNOWN\nClassifier: License :: OSI Approved :: BSD-3-Clause\nClassifier: License :: OSI Approved :: Apache Software License\n\n")))
+	assert.Equal(t, "", pythonLicense([]byte("Name: thing\n\nLicense: MIT\n")))
+}
@@ -0,0 +1,368 @@
+package lib
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Enumeration of the lockfiles and dependency files that can be read.
+const (
+	NpmLockfile      = "package-lock.json"           // NpmLockfile is the lockfile of npm, in version 1, 2 or 3.
+	NpmShrinkwrap    = "npm-shrinkwrap.json"         // NpmShrinkwrap is the publishable lockfile of npm, in the same format.
+	YarnLockfile     = "yarn.lock"                   // YarnLockfile is the lockfile of yarn, classic (v1) or berry (v2 and later).
+	PnpmLockfile     = "pnpm-lock.yaml"              // PnpmLockfile is the lockfile of pnpm, in version 5, 6 or 9.
+	PipRequirements  = "requirements.txt"            // PipRequirements lists pip requirements, also read from other requirements*.txt files.
+	PoetryLockfile   = "poetry.lock"                 // PoetryLockfile is the lockfile of Poetry.
+	PipenvLockfile   = "Pipfile.lock"                // PipenvLockfile is the lockfile of Pipenv.
+	UvLockfile       = "uv.lock"                     // UvLockfile is the lockfile of uv.
+	GradleLockfile   = "gradle.lockfile"             // GradleLockfile is the dependency lockfile of a Gradle project.
+	GradleBuildLock  = "buildscript-gradle.lockfile" // GradleBuildLock is the lockfile of the classpath of a Gradle build script.
+	CargoLockfile    = "Cargo.lock"                  // CargoLockfile is the lockfile of Cargo, in version 1 to 4.
+	BundlerLockfile  = "Gemfile.lock"                // BundlerLockfile is the lockfile of Bundler.
+	ComposerLockfile = "composer.lock"               // ComposerLockfile is the lockfile of Composer.
+	NuGetLockfile    = "packages.lock.json"          // NuGetLockfile is the lockfile of NuGet.
+)
+
+// Lockfiles contains the names of all the lockfiles and dependency files that can be read.
+var Lockfiles = []string{NpmLockfile, NpmShrinkwrap, YarnLockfile, PnpmLockfile, PipRequirements, PoetryLockfile, PipenvLockfile, UvLockfile, GradleLockfile, GradleBuildLock, CargoLockfile, BundlerLockfile, ComposerLockfile, NuGetLockfile}
+
+// ScopeDev is the scope of the development dependencies read from a lockfile, so that
+// they can be dropped with the ExcludeScopes of a Filter.
+const ScopeDev = "dev"
+
+// lockfile is the dependency graph of a project read from a lockfile.
+type lockfile struct {
+	Type         string                  // PURL type of the packages (ex: npm).
+	Namespace    string                  // PURL namespace of the packages, such as the distribution of OS packages.
+	Name         string                  // Name of the project, if known.
+	Version      string                  // Version of the project, if known.
+	Packages     map[string]*lockPackage // Packages keyed by an identifier specific to the lockfile.
+	Dependencies map[string][]string     // Identifiers of the dependencies of each package, and of the project under "".
+	Dev          map[string]bool         // Direct dependencies of the project, true if they are only development dependencies.
+}
+
+// lockPackage is a package of a lockfile.
+type lockPackage struct {
+	Name       string
+	Version    string
+	License    string
+	Copyright  string
+	Integrity  string            // Subresource integrity of the package (ex: sha512-...), if known.
+	Hashes     map[string]string // Hashes of the package keyed by algorithm, used instead of the integrity when set.
+	Dev        bool              // True if the package is only needed for development.
+	Optional   bool              // True if the package is an optional dependency.
+	Scopes     []string          // Scopes of a package that is neither a development nor an optional package, such as Gradle configurations.
+	Qualifiers map[string]string // PURL qualifiers of the package (ex: the classifier of a Maven artifact).
+	Local      bool              // True for workspaces and linked directories, which are not packages.
+}
+
+// newLockfile returns an empty lockfile of packages of the PURL type.
+func newLockfile(purlType string) *lockfile {
+	return &lockfile{Type: purlType, Packages: map[string]*lockPackage{}, Dependencies: map[string][]string{}, Dev: map[string]bool{}}
+}
+
+// IsLockfile returns true if the filename is one of the Lockfiles, or a pip requirements
+// file.
+func IsLockfile(filename string) bool {
+	return containsString(Lockfiles, filepath.Base(filename)) || isRequirementsFile(filename)
+}
+
+// ReadLockfile builds a KissBOM from a lockfile, or a pip requirements file, with a PURL
+// of the type of its ecosystem (ex: pkg:npm, pkg:pypi, pkg:cargo) for each package. The
+// project manifest next to the lockfile (ex: package.json, pyproject.toml), when present, provides
+// the subject of the KissBOM and tells development dependencies apart for lockfiles that
+// do not flag them. Development dependencies are noted with "[dev]" and have the "dev"
+// scope, and licenses and hashes are read from lockfiles that record them.
+//
+// Parameters:
+//   - afs: The file system to read from.
+//   - filename: The lockfile, see Lockfiles.
+//   - filters: Optional filters that determine which packages are kept.
+func ReadLockfile(afs *afero.Afero, filename string, filters ...models.Filter) (kissbom models.KissBOM, err error) {
+	source, err := afs.ReadFile(filename)
+	if err != nil {
+		return
+	}
+
+	var lock *lockfile
+	switch filepath.Base(filename) {
+	case NpmLockfile, NpmShrinkwrap, YarnLockfile, PnpmLockfile:
+		lock, err = readNpmLockfile(afs, filename, source)
+	case GradleLockfile, GradleBuildLock, CargoLockfile, BundlerLockfile, ComposerLockfile, NuGetLockfile:
+		lock, err = readOtherLockfile(afs, filename, source)
+	default:
+		lock, err = readPythonLockfile(afs, filename, source)
+	}
+	if err != nil {
+		return
+	}
+
+	kissbom = lock.kissBOM(filters)
+	kissbom.Metadata.Digest = models.DocumentDigest(source)
+	return
+}
+
+// readNpmLockfile parses an npm, yarn or pnpm lockfile, along with the package.json file
+// next to it.
+func readNpmLockfile(afs *afero.Afero, filename string, source []byte) (lock *lockfile, err error) {
+	manifest := npmManifest{}
+	manifestFilename := filepath.Join(filepath.Dir(filename), "package.json")
+	if data, err := afs.ReadFile(manifestFilename); err == nil {
+		if err = json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("%s: %w", manifestFilename, err)
+		}
+	}
+
+	switch filepath.Base(filename) {
+	case YarnLockfile:
+		lock, err = parseYarnLock(source, manifest)
+	case PnpmLockfile:
+		lock, err = parsePnpmLock(source)
+	default:
+		lock, err = parseNpmLock(source, &manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	lock.Name, lock.Version = manifest.Name, manifest.Version
+	return
+}
+
+// readOtherLockfile parses a Gradle, Cargo, Bundler, Composer or NuGet lockfile, along
+// with the manifest next to it for Cargo and Composer.
+func readOtherLockfile(afs *afero.Afero, filename string, source []byte) (lock *lockfile, err error) {
+	dir := filepath.Dir(filename)
+	switch filepath.Base(filename) {
+	case CargoLockfile:
+		lock, err = parseCargoLock(source, readManifest(afs, filepath.Join(dir, "Cargo.toml")))
+	case BundlerLockfile:
+		lock, err = parseGemfileLock(source)
+	case ComposerLockfile:
+		lock, err = parseComposerLock(source, readManifest(afs, filepath.Join(dir, "composer.json")))
+	case NuGetLockfile:
+		lock, err = parseNuGetLock(source)
+	default:
+		lock, err = parseGradleLock(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return
+}
+
+// readManifest returns the content of a manifest, or nil if it cannot be read.
+func readManifest(afs *afero.Afero, filename string) []byte {
+	data, err := afs.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// addDirect records a direct dependency of the project. A package that is both a
+// development and a regular dependency is a regular dependency.
+func (l *lockfile) addDirect(id string, dev bool) {
+	if _, ok := l.Packages[id]; !ok {
+		return
+	}
+	l.Dependencies[""] = append(l.Dependencies[""], id)
+	if direct, ok := l.Dev[id]; !ok || direct {
+		l.Dev[id] = dev
+	}
+}
+
+// addManifestDependencies records the direct dependencies of a package.json file,
+// resolving each name and version range to a package identifier.
+func (l *lockfile) addManifestDependencies(manifest npmManifest, resolve func(name string, version string) string) {
+	for _, dependencies := range []map[string]string{manifest.Dependencies, manifest.OptionalDependencies} {
+		for name, version := range dependencies {
+			l.addDirect(resolve(name, version), false)
+		}
+	}
+	for name, version := range manifest.DevDependencies {
+		l.addDirect(resolve(name, version), true)
+	}
+}
+
+// markDev flags the packages that can be reached from the development dependencies of
+// the project, but not from its regular dependencies, as development packages. Used for
+// lockfiles that do not flag them themselves.
+func (l *lockfile) markDev() {
+	regular, dev := []string{}, []string{}
+	for id, isDev := range l.Dev {
+		if isDev {
+			dev = append(dev, id)
+		} else {
+			regular = append(regular, id)
+		}
+	}
+	reachedRegular, reachedDev := l.reach(regular), l.reach(dev)
+	for id, p := range l.Packages {
+		p.Dev = reachedDev[id] && !reachedRegular[id]
+	}
+}
+
+// reach returns the packages that can be reached from the provided packages, including
+// themselves.
+func (l *lockfile) reach(queue []string) map[string]bool {
+	reached := map[string]bool{}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if !reached[id] {
+			reached[id] = true
+			queue = append(queue, l.Dependencies[id]...)
+		}
+	}
+	return reached
+}
+
+// kissBOM converts the lockfile to a KissBOM, in the order of the package identifiers.
+// The project, when its name is known, is the subject.
+func (l *lockfile) kissBOM(filters []models.Filter) (kissbom models.KissBOM) {
+	kissbom.Metadata = &models.Metadata{}
+	purls := map[string]string{}
+	if l.Name != "" {
+		kissbom.Metadata.Subject, kissbom.Metadata.Name, kissbom.Metadata.Version = l.purl(l.Name, l.Version, nil), l.Name, l.Version
+		purls[""] = kissbom.Metadata.Subject
+	}
+
+	ids := []string{}
+	for id := range l.Packages {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		p := l.Packages[id]
+		if p.Local || p.Name == "" || p.Version == "" {
+			continue
+		}
+		purl := l.purl(p.Name, p.Version, p.Qualifiers)
+		if !allowedLockPackage(purl, p, filters) {
+			continue
+		}
+		purls[id] = purl
+		pkg := models.Package{Purl: purl, License: p.License, Copyright: p.Copyright, Hashes: p.Hashes}
+		if pkg.Hashes == nil {
+			pkg.Hashes = integrityHashes(p.Integrity)
+		}
+		if p.Dev {
+			pkg.Notes = "[dev]"
+		}
+		kissbom.Packages = append(kissbom.Packages, pkg)
+	}
+
+	dependencies := map[string][]string{}
+	for id := range l.Dependencies {
+		from, ok := purls[id]
+		if !ok {
+			continue
+		}
+		for _, to := range l.dependencyPurls(id, purls, map[string]bool{}) {
+			if to != from {
+				dependencies[from] = append(dependencies[from], to)
+			}
+		}
+	}
+	kissbom.Dependencies = models.MergeDependencies(nil, dependencies)
+	return
+}
+
+// dependencyPurls returns the PURLs of the dependencies of a package, looking through
+// local packages to their own dependencies.
+func (l *lockfile) dependencyPurls(id string, purls map[string]string, visited map[string]bool) (values []string) {
+	for _, d := range l.Dependencies[id] {
+		if purl, ok := purls[d]; ok {
+			values = append(values, purl)
+		} else if p, ok := l.Packages[d]; ok && p.Local && !visited[d] {
+			visited[d] = true
+			values = append(values, l.dependencyPurls(d, purls, visited)...)
+		}
+	}
+	return
+}
+
+// allowedLockPackage returns true if the package is allowed by every provided filter.
+// Development packages have the "dev" scope, optional packages the optional scope, and
+// packages with several scopes are allowed if one of them is.
+func allowedLockPackage(purl string, p *lockPackage, filters []models.Filter) bool {
+	scopes := p.Scopes
+	switch {
+	case p.Dev:
+		scopes = []string{ScopeDev}
+	case p.Optional:
+		scopes = []string{string(cyclonedx.ScopeOptional)}
+	case len(scopes) == 0:
+		scopes = []string{""}
+	}
+	for _, scope := range scopes {
+		allowed := true
+		for _, f := range filters {
+			allowed = allowed && f.Allows(purl, scope, "")
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// purl returns the PURL of a package of the lockfile.
+func (l *lockfile) purl(name string, version string, qualifiers map[string]string) string {
+	namespace := l.Namespace
+	switch l.Type {
+	case packageurl.TypeNPM:
+		return npmPurl(name, version)
+	case packageurl.TypePyPi:
+		return pypiPurl(name, version)
+	case packageurl.TypeMaven:
+		return mavenPurl(name, version, qualifiers)
+	case packageurl.TypeComposer:
+		if vendor, rest, ok := strings.Cut(name, "/"); ok {
+			namespace, name = vendor, rest
+		}
+	}
+	return packageurl.NewPackageURL(l.Type, namespace, name, version, packageurl.QualifiersFromMap(qualifiers), "").ToString()
+}
+
+// npmPurl returns the pkg:npm PURL of a package name, which may be scoped, and version.
+func npmPurl(name string, version string) string {
+	namespace := ""
+	if scope, rest, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		namespace, name = scope, rest
+	}
+	return packageurl.NewPackageURL(packageurl.TypeNPM, namespace, name, version, nil, "").ToString()
+}
+
+// integrityHashes converts a subresource integrity value, a space separated list of
+// base64 digests prefixed with their algorithm (ex: sha512-z4PhNX7v...), to hexadecimal
+// hashes keyed by CycloneDX algorithm name. Returns nil if there are none.
+func integrityHashes(integrity string) map[string]string {
+	hashes := map[string]string{}
+	for _, value := range strings.Fields(integrity) {
+		algorithm, digest, ok := strings.Cut(value, "-")
+		if !ok {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil {
+			continue
+		}
+		hashes[models.SPDXChecksumAlgorithm(algorithm)] = hex.EncodeToString(decoded)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes
+}
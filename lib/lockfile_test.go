@@ -0,0 +1,154 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+func TestIsLockfile(t *testing.T) {
+	assert.True(t, IsLockfile("package-lock.json"))
+	assert.True(t, IsLockfile("web/yarn.lock"))
+	assert.True(t, IsLockfile("/src/pnpm-lock.yaml"))
+	assert.True(t, IsLockfile("npm-shrinkwrap.json"))
+	assert.True(t, IsLockfile("api/requirements.txt"))
+	assert.True(t, IsLockfile("requirements-dev.txt"))
+	assert.True(t, IsLockfile("poetry.lock"))
+	assert.True(t, IsLockfile("Pipfile.lock"))
+	assert.True(t, IsLockfile("uv.lock"))
+	assert.True(t, IsLockfile("service/gradle.lockfile"))
+	assert.True(t, IsLockfile("buildscript-gradle.lockfile"))
+	assert.True(t, IsLockfile("Cargo.lock"))
+	assert.True(t, IsLockfile("Gemfile.lock"))
+	assert.True(t, IsLockfile("composer.lock"))
+	assert.True(t, IsLockfile("src/App/packages.lock.json"))
+	assert.False(t, IsLockfile("pyproject.toml"))
+	assert.False(t, IsLockfile("notes.txt"))
+	assert.False(t, IsLockfile("package.json"))
+	assert.False(t, IsLockfile("bom.json"))
+}
+
+func TestNpmPurl(t *testing.T) {
+	assert.Equal(t, "pkg:npm/%40babel/core@7.24.0", npmPurl("@babel/core", "7.24.0"))
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", npmPurl("lodash", "4.17.21"))
+	assert.Equal(t, "pkg:npm/app", npmPurl("app", ""))
+}
+
+func TestIntegrityHashes(t *testing.T) {
+	assert.Equal(t, map[string]string{"SHA-1": "a94af54a0e9ff1b2dc3cbf73d7d090ae4342786f"}, integrityHashes("sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8="))
+	assert.Equal(t, map[string]string{
+		"SHA-1":   "a94af54a0e9ff1b2dc3cbf73d7d090ae4342786f",
+		"SHA-256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}, integrityHashes("sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU= sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8="))
+	assert.Nil(t, integrityHashes(""))
+	assert.Nil(t, integrityHashes("sha512-!!!"))
+}
+
+func TestReadLockfile(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("web/package-lock.json", []byte(testNpmLockV3), 0644))
+
+	kissbom, err := ReadLockfile(afs, "web/package-lock.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg:npm/app@1.0.0", kissbom.Metadata.Subject)
+	assert.Equal(t, models.DocumentDigest([]byte(testNpmLockV3)), kissbom.Metadata.Digest)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:npm/%40babel/core@7.24.0", License: "MIT", Hashes: map[string]string{"SHA-1": "a94af54a0e9ff1b2dc3cbf73d7d090ae4342786f"}},
+		{Purl: "pkg:npm/debug@4.3.4", License: "MIT"},
+		{Purl: "pkg:npm/fsevents@2.3.3", License: "MIT"},
+		{Purl: "pkg:npm/jest@29.7.0", License: "MIT", Notes: "[dev]"},
+		{Purl: "pkg:npm/ms@2.0.0", License: "MIT", Notes: "[dev]"},
+		{Purl: "pkg:npm/ms@2.1.2", License: "MIT"},
+		{Purl: "pkg:npm/string-width@4.2.3", License: "MIT"},
+	}, kissbom.Packages)
+	assert.Equal(t, map[string][]string{
+		"pkg:npm/app@1.0.0":            {"pkg:npm/%40babel/core@7.24.0", "pkg:npm/jest@29.7.0", "pkg:npm/ms@2.1.2"},
+		"pkg:npm/%40babel/core@7.24.0": {"pkg:npm/debug@4.3.4"},
+		"pkg:npm/debug@4.3.4":          {"pkg:npm/ms@2.1.2"},
+		"pkg:npm/jest@29.7.0":          {"pkg:npm/ms@2.0.0"},
+	}, kissbom.Dependencies)
+}
+
+func TestReadLockfile_Filter(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("package-lock.json", []byte(testNpmLockV3), 0644))
+
+	kissbom, err := ReadLockfile(afs, "package-lock.json", models.Filter{ExcludeScopes: []string{ScopeDev, "optional"}})
+	assert.NoError(t, err)
+	assert.Len(t, kissbom.Packages, 4)
+	for _, p := range kissbom.Packages {
+		assert.NotContains(t, []string{"pkg:npm/jest@29.7.0", "pkg:npm/ms@2.0.0", "pkg:npm/fsevents@2.3.3"}, p.Purl)
+	}
+}
+
+func TestAllowedLockPackage(t *testing.T) {
+	filter := models.Filter{ExcludeScopes: []string{"test", ScopeDev}}
+	assert.True(t, allowedLockPackage("pkg:maven/a/b@1", &lockPackage{Scopes: []string{"test", "compile"}}, []models.Filter{filter}))
+	assert.False(t, allowedLockPackage("pkg:maven/a/b@1", &lockPackage{Scopes: []string{"test"}}, []models.Filter{filter}))
+	assert.False(t, allowedLockPackage("pkg:npm/a@1", &lockPackage{Dev: true}, []models.Filter{filter}))
+	assert.True(t, allowedLockPackage("pkg:npm/a@1", &lockPackage{}, []models.Filter{filter}))
+	assert.True(t, allowedLockPackage("pkg:npm/a@1", &lockPackage{Dev: true}, nil))
+}
+
+func TestReadLockfile_Manifest(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("yarn.lock", []byte(testYarnClassic), 0644))
+	assert.NoError(t, afs.WriteFile("package.json", []byte(`{
+		"name": "@acme/web",
+		"version": "2.0.0",
+		"dependencies": {"@babel/core": "^7.1.0"},
+		"devDependencies": {"jest": "^29.0.0"}
+	}`), 0644))
+
+	kissbom, err := ReadLockfile(afs, "yarn.lock")
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg:npm/%40acme/web@2.0.0", kissbom.Metadata.Subject)
+	assert.Equal(t, "@acme/web", kissbom.Metadata.Name)
+	assert.Equal(t, []string{"pkg:npm/%40babel/core@7.24.0", "pkg:npm/jest@29.7.0"}, kissbom.Dependencies["pkg:npm/%40acme/web@2.0.0"])
+
+	kissbom.SetDepths()
+	for _, p := range kissbom.Packages {
+		if p.Purl == "pkg:npm/ms@2.1.2" {
+			assert.Equal(t, 3, p.Depth)
+			assert.Empty(t, p.Notes)
+		}
+	}
+}
+
+func TestReadLockfile_Errors(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	_, err := ReadLockfile(afs, "package-lock.json")
+	assert.Error(t, err)
+
+	assert.NoError(t, afs.WriteFile("pnpm-lock.yaml", []byte("lockfileVersion: '1.0'\n"), 0644))
+	_, err = ReadLockfile(afs, "pnpm-lock.yaml")
+	assert.EqualError(t, err, `pnpm-lock.yaml: unsupported lockfileVersion "1.0"`)
+
+	assert.NoError(t, afs.WriteFile("package.json", []byte("{"), 0644))
+	_, err = ReadLockfile(afs, "pnpm-lock.yaml")
+	assert.ErrorContains(t, err, "package.json")
+}
+
+func TestConvert_Lockfile(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter.OutputFormat = models.OptionJSON
+	converter.DirectOnly = true
+	assert.NoError(t, converter.Afs.WriteFile("web/pnpm-lock.yaml", []byte(testPnpmLockV9), 0644))
+	assert.NoError(t, converter.Afs.WriteFile("web/package.json", []byte(`{"name": "web", "version": "1.0.0"}`), 0644))
+
+	assert.NoError(t, converter.Convert("web/pnpm-lock.yaml"))
+	assert.Equal(t, "web/pnpm-lock.yaml.json", converter.OutputFileName)
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Equal(t, "pkg:npm/web@1.0.0", kissbom.Metadata.Subject)
+	assert.Len(t, kissbom.Packages, 4)
+}
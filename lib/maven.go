@@ -0,0 +1,195 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// mavenResolvedHeader starts the artifacts listed by mvn dependency:list.
+const mavenResolvedHeader = "The following files have been resolved:"
+
+// mavenOmitted stands for an artifact that a verbose tree shows as omitted for a
+// conflict, under which nothing is recorded.
+const mavenOmitted = "-"
+
+// mavenLogPrefix matches the level that Maven prefixes to the lines of its log.
+var mavenLogPrefix = regexp.MustCompile(`^\[(INFO|WARNING|ERROR|DEBUG)\] ?`)
+
+// mavenTreeBranch matches the branch drawn before an artifact by mvn dependency:tree,
+// three characters for each level (ex: "|  \- ").
+var mavenTreeBranch = regexp.MustCompile(`^((?:[| ]  )*)[+\\]- `)
+
+// mavenCoordinates matches the coordinates of an artifact, written as
+// groupId:artifactId:type[:classifier]:version[:scope], followed by annotations such as
+// "(optional)" or "-- module name".
+var mavenCoordinates = regexp.MustCompile(`^\(?([\w.-]+):([\w.-]+):([\w.-]+)(?::([\w.-]+))?(?::([\w.-]+))?(?::([\w.-]+))?(.*)$`)
+
+// mavenArtifact is an artifact printed by the Maven dependency plugin.
+type mavenArtifact struct {
+	ID       string // Coordinates of the artifact, without its scope.
+	Package  lockPackage
+	Scope    string
+	Omitted  bool // True for artifacts that verbose trees print in parentheses, as they were not selected.
+	Conflict bool // True if the artifact was omitted in favor of another version.
+}
+
+// isMavenDependencyOutput returns true if the source is the output of mvn
+// dependency:list or mvn dependency:tree, either the log of Maven or the file written
+// with -DoutputFile.
+func isMavenDependencyOutput(source []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := mavenLogPrefix.ReplaceAllString(scanner.Text(), "")
+		if strings.TrimSpace(line) == mavenResolvedHeader {
+			return true
+		}
+		if branch := mavenTreeBranch.FindString(line); branch != "" {
+			if _, ok := parseMavenArtifact(line[len(branch):]); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMavenDependencies builds a KissBOM from the output of mvn dependency:list or mvn
+// dependency:tree, with a pkg:maven PURL for each artifact, qualified by its type when
+// it is not a jar and by its classifier. Maven scopes (ex: compile, test) are the scopes
+// of the packages, so that test or provided artifacts can be dropped with a filter.
+//
+// The root of a tree, the project, becomes the subject of the KissBOM, and the tree
+// provides the dependencies of each artifact; with a multi-module build, the
+// dependencies of the other modules are dependencies of the subject. Artifacts that a
+// verbose tree shows as omitted for a conflict are left out. A list does not record
+// dependencies.
+//
+// Parameters:
+//   - source: The output of the Maven dependency plugin.
+//   - filters: Optional filters that determine which packages are kept.
+func ReadMavenDependencies(source []byte, filters ...models.Filter) (kissbom models.KissBOM, err error) {
+	lock := newLockfile(packageurl.TypeMaven)
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var parents []string // Artifacts from the root of the current tree to the last one read, "" for the project.
+	for scanner.Scan() {
+		line := strings.TrimRight(mavenLogPrefix.ReplaceAllString(scanner.Text(), ""), " \t")
+
+		if branch := mavenTreeBranch.FindString(line); branch != "" {
+			artifact, ok := parseMavenArtifact(line[len(branch):])
+			depth := len(branch) / 3
+			if !ok || depth > len(parents) {
+				continue
+			}
+			parents = parents[:depth]
+			parent := parents[depth-1]
+			if artifact.Conflict || parent == mavenOmitted {
+				parents = append(parents, mavenOmitted)
+				continue
+			}
+			lock.addMavenArtifact(artifact)
+			lock.Dependencies[parent] = appendUnique(lock.Dependencies[parent], artifact.ID)
+			parents = append(parents, artifact.ID)
+			continue
+		}
+
+		artifact, ok := parseMavenArtifact(line)
+		switch {
+		case !ok || artifact.Omitted:
+			continue
+		case artifact.Scope == "" && line == strings.TrimSpace(line):
+			// The root of a tree, which is printed without indentation nor scope.
+			if lock.Name == "" {
+				lock.Name, lock.Version = artifact.Package.Name, artifact.Package.Version
+				parents = []string{""}
+				continue
+			}
+			artifact.Package.Local = true
+			lock.Packages[artifact.ID] = &artifact.Package
+			lock.addDirect(artifact.ID, false)
+			parents = []string{artifact.ID}
+		case artifact.Scope != "":
+			// An artifact of a list.
+			lock.addMavenArtifact(artifact)
+			parents = nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	return lock.kissBOM(filters), nil
+}
+
+// addMavenArtifact adds an artifact to the lockfile, adding its scope to those of the
+// same artifact seen elsewhere in the output.
+func (l *lockfile) addMavenArtifact(artifact mavenArtifact) {
+	p, ok := l.Packages[artifact.ID]
+	if !ok {
+		p = &artifact.Package
+		l.Packages[artifact.ID] = p
+	}
+	p.Scopes = appendUnique(p.Scopes, artifact.Scope)
+	p.Optional = p.Optional && artifact.Package.Optional
+}
+
+// parseMavenArtifact parses the coordinates and annotations of an artifact printed by
+// the Maven dependency plugin. Returns false if the text is not an artifact.
+func parseMavenArtifact(text string) (artifact mavenArtifact, ok bool) {
+	match := mavenCoordinates.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return
+	}
+
+	fields := []string{}
+	for _, field := range match[3:7] {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	artifactType, classifier, version, scope := fields[0], "", "", ""
+	switch len(fields) {
+	case 2:
+		version = fields[1]
+	case 3:
+		version, scope = fields[1], fields[2]
+	case 4:
+		classifier, version, scope = fields[1], fields[2], fields[3]
+	default:
+		return
+	}
+
+	annotations := match[7]
+	artifact.Omitted = strings.HasPrefix(strings.TrimSpace(text), "(")
+	artifact.Conflict = artifact.Omitted && strings.Contains(annotations, "omitted for conflict")
+	artifact.Scope = scope
+	artifact.ID = strings.Join([]string{match[1], match[2], artifactType, classifier, version}, ":")
+	artifact.Package = lockPackage{
+		Name:     match[1] + ":" + match[2],
+		Version:  version,
+		Optional: strings.Contains(annotations, "(optional)"),
+	}
+	if artifactType != "jar" || classifier != "" {
+		artifact.Package.Qualifiers = map[string]string{}
+		if artifactType != "jar" {
+			artifact.Package.Qualifiers["type"] = artifactType
+		}
+		if classifier != "" {
+			artifact.Package.Qualifiers["classifier"] = classifier
+		}
+	}
+	return artifact, true
+}
+
+// mavenPurl returns the pkg:maven PURL of an artifact named groupId:artifactId, with
+// optional qualifiers such as its type and classifier.
+func mavenPurl(name string, version string, qualifiers map[string]string) string {
+	group, artifact, _ := strings.Cut(name, ":")
+	return packageurl.NewPackageURL(packageurl.TypeMaven, group, artifact, version, packageurl.QualifiersFromMap(qualifiers), "").ToString()
+}
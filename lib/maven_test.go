@@ -0,0 +1,147 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const testMavenTree = `[INFO] Scanning for projects...
+[INFO]
+[INFO] --- dependency:3.6.1:tree (default-cli) @ app ---
+[INFO] com.example:app:jar:1.0.0
+[INFO] +- org.springframework:spring-core:jar:6.1.4:compile
+[INFO] |  \- org.springframework:spring-jcl:jar:6.1.4:compile
+[INFO] +- com.google.guava:guava:jar:33.0.0-jre:compile (optional)
+[INFO] |  +- (org.checkerframework:checker-qual:jar:3.41.0:compile - omitted for conflict with 3.42.0)
+[INFO] |  \- (org.springframework:spring-jcl:jar:6.1.4:compile - omitted for duplicate)
+[INFO] +- io.netty:netty-transport-native-epoll:jar:linux-x86_64:4.1.107.Final:runtime
+[INFO] \- junit:junit:jar:4.13.2:test
+[INFO]    \- org.hamcrest:hamcrest-core:jar:1.3:test
+[INFO] ------------------------------------------------------------------------
+[INFO] BUILD SUCCESS
+`
+
+const testMavenList = `
+The following files have been resolved:
+   org.springframework:spring-core:jar:6.1.4:compile -- module spring.core [auto]
+   junit:junit:jar:4.13.2:test -- module junit
+   com.example:parent:pom:2.0:provided
+`
+
+func TestIsMavenDependencyOutput(t *testing.T) {
+	assert.True(t, isMavenDependencyOutput([]byte(testMavenTree)))
+	assert.True(t, isMavenDependencyOutput([]byte(testMavenList)))
+	assert.True(t, isMavenDependencyOutput([]byte("com.example:app:jar:1.0.0\n\\- junit:junit:jar:4.13.2:test\n")))
+	assert.False(t, isMavenDependencyOutput([]byte(`{"bomFormat": "CycloneDX"}`)))
+	assert.False(t, isMavenDependencyOutput([]byte("[INFO] BUILD SUCCESS\n")))
+}
+
+func TestReadMavenDependencies_Tree(t *testing.T) {
+	kissbom, err := ReadMavenDependencies([]byte(testMavenTree))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "pkg:maven/com.example/app@1.0.0", kissbom.Metadata.Subject)
+	assert.Equal(t, "com.example:app", kissbom.Metadata.Name)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:maven/com.google.guava/guava@33.0.0-jre"},
+		{Purl: "pkg:maven/io.netty/netty-transport-native-epoll@4.1.107.Final?classifier=linux-x86_64"},
+		{Purl: "pkg:maven/junit/junit@4.13.2"},
+		{Purl: "pkg:maven/org.hamcrest/hamcrest-core@1.3"},
+		{Purl: "pkg:maven/org.springframework/spring-core@6.1.4"},
+		{Purl: "pkg:maven/org.springframework/spring-jcl@6.1.4"},
+	}, kissbom.Packages)
+	assert.Equal(t, map[string][]string{
+		"pkg:maven/com.example/app@1.0.0": {
+			"pkg:maven/com.google.guava/guava@33.0.0-jre",
+			"pkg:maven/io.netty/netty-transport-native-epoll@4.1.107.Final?classifier=linux-x86_64",
+			"pkg:maven/junit/junit@4.13.2",
+			"pkg:maven/org.springframework/spring-core@6.1.4",
+		},
+		"pkg:maven/com.google.guava/guava@33.0.0-jre":     {"pkg:maven/org.springframework/spring-jcl@6.1.4"},
+		"pkg:maven/junit/junit@4.13.2":                    {"pkg:maven/org.hamcrest/hamcrest-core@1.3"},
+		"pkg:maven/org.springframework/spring-core@6.1.4": {"pkg:maven/org.springframework/spring-jcl@6.1.4"},
+	}, kissbom.Dependencies)
+}
+
+func TestReadMavenDependencies_Filter(t *testing.T) {
+	kissbom, err := ReadMavenDependencies([]byte(testMavenTree), models.Filter{ExcludeScopes: []string{"test", "optional"}})
+	assert.NoError(t, err)
+	assert.Len(t, kissbom.Packages, 3)
+	for _, p := range kissbom.Packages {
+		assert.NotContains(t, []string{"pkg:maven/junit/junit@4.13.2", "pkg:maven/org.hamcrest/hamcrest-core@1.3", "pkg:maven/com.google.guava/guava@33.0.0-jre"}, p.Purl)
+	}
+}
+
+func TestReadMavenDependencies_List(t *testing.T) {
+	kissbom, err := ReadMavenDependencies([]byte(testMavenList))
+	assert.NoError(t, err)
+	assert.Empty(t, kissbom.Metadata.Subject)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:maven/com.example/parent@2.0?type=pom"},
+		{Purl: "pkg:maven/junit/junit@4.13.2"},
+		{Purl: "pkg:maven/org.springframework/spring-core@6.1.4"},
+	}, kissbom.Packages)
+	assert.Nil(t, kissbom.Dependencies)
+}
+
+func TestReadMavenDependencies_Modules(t *testing.T) {
+	kissbom, err := ReadMavenDependencies([]byte(`com.example:parent:pom:1.0.0
+com.example:core:jar:1.0.0
+\- org.slf4j:slf4j-api:jar:2.0.12:compile
+com.example:web:jar:1.0.0
++- com.example:core:jar:1.0.0:compile
+\- jakarta.servlet:jakarta.servlet-api:jar:6.0.0:provided
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg:maven/com.example/parent@1.0.0", kissbom.Metadata.Subject)
+	assert.Len(t, kissbom.Packages, 2)
+	assert.Equal(t, []string{"pkg:maven/jakarta.servlet/jakarta.servlet-api@6.0.0", "pkg:maven/org.slf4j/slf4j-api@2.0.12"}, kissbom.Dependencies["pkg:maven/com.example/parent@1.0.0"])
+}
+
+func TestParseMavenArtifact(t *testing.T) {
+	artifact, ok := parseMavenArtifact("org.example:lib:test-jar:tests:1.0:test (optional) ")
+	assert.True(t, ok)
+	assert.Equal(t, "org.example:lib:test-jar:tests:1.0", artifact.ID)
+	assert.Equal(t, "test", artifact.Scope)
+	assert.Equal(t, lockPackage{Name: "org.example:lib", Version: "1.0", Optional: true, Qualifiers: map[string]string{"type": "test-jar", "classifier": "tests"}}, artifact.Package)
+
+	artifact, ok = parseMavenArtifact("(org.example:lib:jar:1.0:compile - omitted for conflict with 2.0)")
+	assert.True(t, ok)
+	assert.True(t, artifact.Omitted)
+	assert.True(t, artifact.Conflict)
+
+	_, ok = parseMavenArtifact("Total time:  1.234 s")
+	assert.False(t, ok)
+	_, ok = parseMavenArtifact("org.example:lib")
+	assert.False(t, ok)
+}
+
+func TestMavenPurl(t *testing.T) {
+	assert.Equal(t, "pkg:maven/org.example/lib@1.0", mavenPurl("org.example:lib", "1.0", nil))
+	assert.Equal(t, "pkg:maven/org.example/lib@1.0?classifier=tests&type=test-jar", mavenPurl("org.example:lib", "1.0", map[string]string{"type": "test-jar", "classifier": "tests"}))
+}
+
+func TestConvert_Maven(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter.OutputFormat = models.OptionJSON
+	converter.Filter = models.Filter{ExcludeScopes: []string{"test"}}
+	assert.NoError(t, converter.Afs.WriteFile("tree.txt", []byte(testMavenTree), 0644))
+
+	assert.NoError(t, converter.Convert("tree.txt"))
+	assert.Equal(t, "tree.txt.json", converter.OutputFileName)
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Equal(t, "pkg:maven/com.example/app@1.0.0", kissbom.Metadata.Subject)
+	assert.Equal(t, models.DocumentDigest([]byte(testMavenTree)), kissbom.Metadata.Digest)
+	assert.Len(t, kissbom.Packages, 4)
+}
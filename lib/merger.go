@@ -0,0 +1,167 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Merge decodes each of the provided files and combines them into a single KissBOM.
+// Packages are unioned by canonical PURL and conflicting license, copyright and notes
+// values are reconciled according to the MergeStrategy of the Converter. The files
+// each package was found in are recorded in the package Notes, and dependency graphs
+// are unioned.
+func (c *Converter) Merge(filenames []string) error {
+	kissboms := make([]models.KissBOM, len(filenames))
+	sources := make([]string, len(filenames))
+	for i, filename := range filenames {
+		log.Printf("merging: %v", filename)
+
+		kissbom, err := c.load(filename)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		kissboms[i], sources[i] = kissbom, filepath.Base(filename)
+	}
+
+	merged, err := c.merge(kissboms, sources)
+	if err != nil {
+		return err
+	}
+	c.applyDependencies(&merged)
+	merged.PruneDependencies()
+
+	log.Printf("merged %v packages from %v files", len(merged.Packages), len(filenames))
+
+	c.OutputFileName = path.Join(c.OutputFolder, fmt.Sprintf("merged_%s", time.Now().Format("20060102150405")))
+	return c.writeToFile(merged)
+}
+
+// merge combines KissBOMs into a single KissBOM, unioning packages by canonical PURL
+// and reconciling conflicting values according to the MergeStrategy of the Converter.
+// The source of each KissBOM, in the same order, is recorded in the notes of its packages.
+func (c *Converter) merge(kissboms []models.KissBOM, sources []string) (merged models.KissBOM, err error) {
+	index := map[string]int{}
+	found := map[string][]string{}
+
+	for i, kissbom := range kissboms {
+		for _, p := range kissbom.Packages {
+			key := models.CanonicalPurl(p.Purl)
+			if j, ok := index[key]; ok {
+				merged.Packages[j], err = models.MergePackage(merged.Packages[j], p, c.MergeStrategy)
+				if err != nil {
+					return merged, fmt.Errorf("%s: %w", sources[i], err)
+				}
+			} else {
+				index[key] = len(merged.Packages)
+				merged.Packages = append(merged.Packages, p)
+			}
+			found[key] = appendUnique(found[key], sources[i])
+		}
+		merged.Dependencies = models.MergeDependencies(merged.Dependencies, kissbom.Dependencies)
+	}
+
+	for i, p := range merged.Packages {
+		merged.Packages[i].Notes = withSources(p.Notes, found[models.CanonicalPurl(p.Purl)])
+	}
+	return merged, nil
+}
+
+// load reads the provided file and decodes it into a KissBOM. CycloneDX JSON documents,
+// SPDX JSON documents, previously generated KissBOM JSON documents, lockfiles, Go
+// modules and Go binaries are accepted.
+func (c *Converter) load(filename string) (kissbom models.KissBOM, err error) {
+	switch {
+	case IsGoModule(c.Afs, filename):
+		return ReadGoModule(c.Afs, filename, c.GoBuildList, c.Filter)
+	case IsLockfile(filename):
+		return ReadLockfile(c.Afs, filename, c.Filter)
+	}
+
+	source, err := c.Afs.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	switch {
+	case isExecutable(source):
+		if kissbom, err = ReadGoBinary(source, c.Filter); err != nil {
+			return
+		}
+		kissbom.Metadata = withDigest(kissbom.Metadata, source)
+		return
+	case isMavenDependencyOutput(source):
+		if kissbom, err = ReadMavenDependencies(source, c.Filter); err != nil {
+			return
+		}
+		kissbom.Metadata = withDigest(kissbom.Metadata, source)
+		return
+	}
+
+	var probe struct {
+		BOMFormat   string          `json:"bomFormat"`
+		SPDXVersion string          `json:"spdxVersion"`
+		Packages    json.RawMessage `json:"packages"`
+	}
+	if err = json.Unmarshal(source, &probe); err != nil {
+		return
+	}
+
+	switch {
+	case probe.BOMFormat == cyclonedx.BOMFormat:
+		var cdx cyclonedx.BOM
+		err = cyclonedx.NewBOMDecoder(bytes.NewReader(source), cyclonedx.BOMFileFormatJSON).Decode(&cdx)
+		if err != nil {
+			return
+		}
+		kissbom = models.NewKissBOMFromCycloneDX(&cdx, c.Filter)
+		kissbom.Metadata = withDigest(kissbom.Metadata, source)
+	case probe.SPDXVersion != "":
+		var spdx models.SPDXDocument
+		if err = json.Unmarshal(source, &spdx); err != nil {
+			return
+		}
+		kissbom = models.NewKissBOMFromSPDX(&spdx, c.Filter)
+		kissbom.Metadata = withDigest(kissbom.Metadata, source)
+	case probe.Packages != nil:
+		if err = json.Unmarshal(source, &kissbom); err != nil {
+			return
+		}
+		packages := []models.Package{}
+		for _, p := range kissbom.Packages {
+			if c.Filter.Allows(p.Purl, "", "") {
+				packages = append(packages, p)
+			}
+		}
+		kissbom.Packages = packages
+	default:
+		err = fmt.Errorf("unrecognized input format")
+	}
+	return
+}
+
+// appendUnique appends value to values if it is not already present.
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// withSources appends the list of source files to the provided notes.
+func withSources(notes string, sources []string) string {
+	if len(sources) == 0 {
+		return notes
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s [sources: %s]", notes, strings.Join(sources, ", ")))
+}
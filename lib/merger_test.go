@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const mergeCycloneDX = `
+{
+	"bomFormat": "CycloneDX",
+	"specVersion": "1.4",
+	"components": [
+		{
+			"type": "library",
+			"purl": "pkg:npm/lodash@4.17.21",
+			"licenses": [{"expression": "MIT"}]
+		},
+		{
+			"type": "library",
+			"purl": "pkg:npm/express@4.18.2"
+		}
+	]
+}`
+
+const mergeKissBOM = `
+{
+	"packages": [
+		{"purl": "pkg:NPM/lodash@4.17.21", "license": "Apache-2.0", "copyright": "Copyright JS Foundation"},
+		{"purl": "pkg:pypi/requests@2.26.0"}
+	]
+}`
+
+func TestConverter_Merge(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter.OutputFormat = models.OptionJSON
+
+	assert.NoError(t, converter.Afs.WriteFile("a.cdx.json", []byte(mergeCycloneDX), 0644))
+	assert.NoError(t, converter.Afs.WriteFile("b.json", []byte(mergeKissBOM), 0644))
+
+	err := converter.Merge([]string{"a.cdx.json", "b.json"})
+	assert.NoError(t, err)
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Len(t, kissbom.Packages, 3)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", kissbom.Packages[0].Purl)
+	assert.Equal(t, "MIT", kissbom.Packages[0].License)
+	assert.Equal(t, "Copyright JS Foundation", kissbom.Packages[0].Copyright)
+	assert.Equal(t, "[sources: a.cdx.json, b.json]", kissbom.Packages[0].Notes)
+	assert.Equal(t, "[sources: b.json]", kissbom.Packages[2].Notes)
+
+	converter.MergeStrategy = models.MergeFail
+	assert.Error(t, converter.Merge([]string{"a.cdx.json", "b.json"}))
+
+	assert.Error(t, converter.Merge([]string{"a.cdx.json", "missing.json"}))
+
+	assert.NoError(t, converter.Afs.WriteFile("c.json", []byte(`{"something": "else"}`), 0644))
+	assert.Error(t, converter.Merge([]string{"a.cdx.json", "c.json"}))
+}
+
+func TestConverter_Merge_Dependencies(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter.OutputFormat = models.OptionJSON
+	converter.Depth = true
+
+	assert.NoError(t, converter.Afs.WriteFile("a.json", []byte(`{
+		"packages": [{"purl": "pkg:npm/express@4.18.2"}, {"purl": "pkg:npm/debug@2.6.9"}],
+		"dependencies": {"pkg:npm/express@4.18.2": ["pkg:npm/debug@2.6.9"]}
+	}`), 0644))
+	assert.NoError(t, converter.Afs.WriteFile("b.json", []byte(`{
+		"packages": [{"purl": "pkg:npm/debug@2.6.9"}, {"purl": "pkg:npm/ms@2.0.0"}],
+		"dependencies": {"pkg:npm/debug@2.6.9": ["pkg:npm/ms@2.0.0"]}
+	}`), 0644))
+	assert.NoError(t, converter.Merge([]string{"a.json", "b.json"}))
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Equal(t, map[string][]string{
+		"pkg:npm/express@4.18.2": {"pkg:npm/debug@2.6.9"},
+		"pkg:npm/debug@2.6.9":    {"pkg:npm/ms@2.0.0"},
+	}, kissbom.Dependencies)
+	assert.Equal(t, 3, kissbom.Packages[2].Depth)
+}
@@ -0,0 +1,185 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// npmLock contains the parts of a package-lock.json or npm-shrinkwrap.json file used to
+// list packages. Version 1 nests dependencies, while versions 2 and 3 list every package
+// by its path in node_modules. Version 2 has both.
+type npmLock struct {
+	Name            string                       `json:"name"`
+	Version         string                       `json:"version"`
+	LockfileVersion int                          `json:"lockfileVersion"`
+	Packages        map[string]npmLockPackage    `json:"packages"`
+	Dependencies    map[string]npmLockDependency `json:"dependencies"`
+}
+
+// npmLockPackage is a package of a version 2 or 3 lockfile, keyed by its path.
+type npmLockPackage struct {
+	npmManifest
+	Integrity   string `json:"integrity"`
+	Resolved    string `json:"resolved"`
+	Dev         bool   `json:"dev"`
+	Optional    bool   `json:"optional"`
+	DevOptional bool   `json:"devOptional"`
+	Link        bool   `json:"link"`
+}
+
+// npmLockDependency is a dependency of a version 1 lockfile, keyed by its name.
+type npmLockDependency struct {
+	Version      string                       `json:"version"`
+	Integrity    string                       `json:"integrity"`
+	Dev          bool                         `json:"dev"`
+	Optional     bool                         `json:"optional"`
+	Requires     map[string]string            `json:"requires"`
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+// parseNpmLock parses an npm lockfile. Packages are identified by their path in
+// node_modules, and the name and version of the manifest are taken from the lockfile
+// when it records them.
+func parseNpmLock(source []byte, manifest *npmManifest) (*lockfile, error) {
+	var lock npmLock
+	if err := json.Unmarshal(source, &lock); err != nil {
+		return nil, err
+	}
+	if lock.LockfileVersion < 1 || lock.LockfileVersion > 3 {
+		return nil, fmt.Errorf("unsupported lockfileVersion %v", lock.LockfileVersion)
+	}
+	manifest.Name = models.FirstNonEmpty(lock.Name, manifest.Name)
+	manifest.Version = models.FirstNonEmpty(lock.Version, manifest.Version)
+
+	l := newLockfile(packageurl.TypeNPM)
+	if lock.Packages != nil {
+		l.addNpmPackages(lock.Packages, manifest)
+		return l, nil
+	}
+
+	requires := map[string]map[string]string{}
+	l.addNpmDependencies("", lock.Dependencies, requires)
+	for id, names := range requires {
+		for name := range names {
+			if dependency := resolveNodeModule(l.Packages, id, name); dependency != "" {
+				l.Dependencies[id] = append(l.Dependencies[id], dependency)
+			}
+		}
+	}
+	l.addManifestDependencies(*manifest, func(name string, _ string) string {
+		return resolveNodeModule(l.Packages, "", name)
+	})
+	return l, nil
+}
+
+// addNpmPackages adds the packages of a version 2 or 3 lockfile. Workspaces and links
+// to them are local packages. The direct dependencies of the project are read from the
+// root package, or from the manifest when the lockfile has none.
+func (l *lockfile) addNpmPackages(packages map[string]npmLockPackage, manifest *npmManifest) {
+	for id, p := range packages {
+		if id == "" {
+			manifest.Name = models.FirstNonEmpty(p.Name, manifest.Name)
+			manifest.Version = models.FirstNonEmpty(p.Version, manifest.Version)
+			continue
+		}
+		l.Packages[id] = &lockPackage{
+			Name:      models.FirstNonEmpty(p.Name, nodeModuleName(id)),
+			Version:   p.Version,
+			License:   p.license(),
+			Integrity: p.Integrity,
+			Dev:       p.Dev,
+			Optional:  p.Optional || p.DevOptional,
+			Local:     p.Link || !strings.Contains(id, "node_modules/"),
+		}
+	}
+
+	for id, p := range packages {
+		switch {
+		case id == "":
+			continue
+		case p.Link:
+			l.Dependencies[id] = []string{p.Resolved}
+			continue
+		}
+		for _, dependencies := range []map[string]string{p.Dependencies, p.OptionalDependencies} {
+			for name := range dependencies {
+				if dependency := resolveNodeModule(l.Packages, id, name); dependency != "" {
+					l.Dependencies[id] = append(l.Dependencies[id], dependency)
+				}
+			}
+		}
+	}
+
+	root := *manifest
+	if p, ok := packages[""]; ok {
+		root = p.npmManifest
+	}
+	l.addManifestDependencies(root, func(name string, _ string) string {
+		return resolveNodeModule(l.Packages, "", name)
+	})
+}
+
+// addNpmDependencies adds the nested dependencies of a version 1 lockfile under their
+// path in node_modules, collecting the names each of them requires.
+func (l *lockfile) addNpmDependencies(parent string, dependencies map[string]npmLockDependency, requires map[string]map[string]string) {
+	for name, d := range dependencies {
+		id := path.Join(parent, "node_modules", name)
+		realName, version := npmAlias(name, d.Version)
+		l.Packages[id] = &lockPackage{
+			Name:      realName,
+			Version:   version,
+			Integrity: d.Integrity,
+			Dev:       d.Dev,
+			Optional:  d.Optional,
+			Local:     strings.HasPrefix(d.Version, "file:"),
+		}
+		requires[id] = d.Requires
+		l.addNpmDependencies(id, d.Dependencies, requires)
+	}
+}
+
+// npmAlias returns the name and version of the package installed under an alias, whose
+// version is npm:name@version, or the name and version as they are otherwise.
+func npmAlias(name string, version string) (string, string) {
+	alias, ok := strings.CutPrefix(version, "npm:")
+	if i := strings.LastIndex(alias, "@"); ok && i > 0 {
+		return alias[:i], alias[i+1:]
+	}
+	return name, version
+}
+
+// resolveNodeModule returns the identifier of the package a package requires by name,
+// found the way Node.js does: in the node_modules directory of the package, then in
+// those of its parents. Returns an empty string if the package is not installed.
+func resolveNodeModule(packages map[string]*lockPackage, from string, name string) string {
+	dir := from
+	for {
+		id := path.Join(dir, "node_modules", name)
+		if _, ok := packages[id]; ok {
+			return id
+		}
+		if dir == "" {
+			return ""
+		}
+		if i := strings.LastIndex(dir, "node_modules/"); i > 0 {
+			dir = strings.TrimSuffix(dir[:i], "/")
+		} else {
+			dir = ""
+		}
+	}
+}
+
+// nodeModuleName returns the name of the package installed at a node_modules path
+// (ex: node_modules/a/node_modules/@scope/b is @scope/b).
+func nodeModuleName(id string) string {
+	if i := strings.LastIndex(id, "node_modules/"); i >= 0 {
+		return id[i+len("node_modules/"):]
+	}
+	return path.Base(id)
+}
@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testNpmLockV3 = `{
+	"name": "app",
+	"version": "1.0.0",
+	"lockfileVersion": 3,
+	"requires": true,
+	"packages": {
+		"": {
+			"name": "app",
+			"version": "1.0.0",
+			"license": "MIT",
+			"workspaces": ["packages/*"],
+			"dependencies": {"@babel/core": "^7.0.0", "lib": "*"},
+			"devDependencies": {"jest": "^29.0.0"}
+		},
+		"node_modules/@babel/core": {
+			"version": "7.24.0",
+			"integrity": "sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8=",
+			"license": "MIT",
+			"dependencies": {"debug": "^4.1.0"}
+		},
+		"node_modules/debug": {"version": "4.3.4", "license": "MIT", "dependencies": {"ms": "2.1.2"}},
+		"node_modules/ms": {"version": "2.1.2", "license": "MIT"},
+		"node_modules/jest": {"version": "29.7.0", "dev": true, "license": "MIT", "dependencies": {"ms": "^2.0.0"}},
+		"node_modules/jest/node_modules/ms": {"version": "2.0.0", "dev": true, "license": "MIT"},
+		"node_modules/fsevents": {"version": "2.3.3", "optional": true, "license": "MIT"},
+		"node_modules/string-width-cjs": {"name": "string-width", "version": "4.2.3", "license": {"type": "MIT"}},
+		"node_modules/lib": {"resolved": "packages/lib", "link": true},
+		"packages/lib": {"name": "lib", "version": "0.1.0", "dependencies": {"ms": "2.1.2"}}
+	}
+}`
+
+const testNpmLockV1 = `{
+	"name": "app",
+	"version": "1.0.0",
+	"lockfileVersion": 1,
+	"requires": true,
+	"dependencies": {
+		"debug": {
+			"version": "4.3.4",
+			"integrity": "sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8=",
+			"requires": {"ms": "2.1.2"}
+		},
+		"jest": {
+			"version": "29.7.0",
+			"dev": true,
+			"requires": {"ms": "^2.0.0"},
+			"dependencies": {
+				"ms": {"version": "2.0.0", "dev": true}
+			}
+		},
+		"ms": {"version": "2.1.2"},
+		"string-width-cjs": {"version": "npm:string-width@4.2.3"}
+	}
+}`
+
+func TestParseNpmLock_V3(t *testing.T) {
+	manifest := npmManifest{}
+	l, err := parseNpmLock([]byte(testNpmLockV3), &manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, "app", manifest.Name)
+	assert.Equal(t, "1.0.0", manifest.Version)
+
+	assert.Equal(t, &lockPackage{Name: "@babel/core", Version: "7.24.0", License: "MIT", Integrity: "sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8="}, l.Packages["node_modules/@babel/core"])
+	assert.True(t, l.Packages["node_modules/jest/node_modules/ms"].Dev)
+	assert.True(t, l.Packages["node_modules/fsevents"].Optional)
+	assert.Equal(t, "string-width", l.Packages["node_modules/string-width-cjs"].Name)
+	assert.True(t, l.Packages["node_modules/lib"].Local)
+	assert.True(t, l.Packages["packages/lib"].Local)
+
+	assert.Equal(t, []string{"node_modules/jest/node_modules/ms"}, l.Dependencies["node_modules/jest"])
+	assert.Equal(t, []string{"node_modules/ms"}, l.Dependencies["node_modules/debug"])
+	assert.Equal(t, []string{"packages/lib"}, l.Dependencies["node_modules/lib"])
+	assert.Equal(t, []string{"node_modules/ms"}, l.Dependencies["packages/lib"])
+	assert.ElementsMatch(t, []string{"node_modules/@babel/core", "node_modules/lib", "node_modules/jest"}, l.Dependencies[""])
+	assert.True(t, l.Dev["node_modules/jest"])
+}
+
+func TestParseNpmLock_V1(t *testing.T) {
+	manifest := npmManifest{DevDependencies: map[string]string{"jest": "^29.0.0"}, Dependencies: map[string]string{"debug": "^4.0.0"}}
+	l, err := parseNpmLock([]byte(testNpmLockV1), &manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, "app", manifest.Name)
+
+	assert.Equal(t, &lockPackage{Name: "ms", Version: "2.0.0", Dev: true}, l.Packages["node_modules/jest/node_modules/ms"])
+	assert.Equal(t, &lockPackage{Name: "string-width", Version: "4.2.3"}, l.Packages["node_modules/string-width-cjs"])
+	assert.Equal(t, []string{"node_modules/jest/node_modules/ms"}, l.Dependencies["node_modules/jest"])
+	assert.Equal(t, []string{"node_modules/ms"}, l.Dependencies["node_modules/debug"])
+	assert.ElementsMatch(t, []string{"node_modules/debug", "node_modules/jest"}, l.Dependencies[""])
+}
+
+func TestParseNpmLock_Errors(t *testing.T) {
+	_, err := parseNpmLock([]byte(`{"lockfileVersion": 4}`), &npmManifest{})
+	assert.EqualError(t, err, "unsupported lockfileVersion 4")
+
+	_, err = parseNpmLock([]byte(`not json`), &npmManifest{})
+	assert.Error(t, err)
+}
+
+func TestResolveNodeModule(t *testing.T) {
+	packages := map[string]*lockPackage{
+		"node_modules/a":                           {},
+		"node_modules/b":                           {},
+		"node_modules/a/node_modules/b":            {},
+		"node_modules/a/node_modules/@scope/c":     {},
+		"node_modules/a/node_modules/@scope/c/x/y": {},
+	}
+
+	assert.Equal(t, "node_modules/a/node_modules/b", resolveNodeModule(packages, "node_modules/a/node_modules/@scope/c", "b"))
+	assert.Equal(t, "node_modules/a", resolveNodeModule(packages, "node_modules/a/node_modules/@scope/c", "a"))
+	assert.Equal(t, "node_modules/b", resolveNodeModule(packages, "packages/lib", "b"))
+	assert.Equal(t, "node_modules/b", resolveNodeModule(packages, "", "b"))
+	assert.Equal(t, "", resolveNodeModule(packages, "node_modules/a", "missing"))
+}
+
+func TestNpmAlias(t *testing.T) {
+	name, version := npmAlias("string-width-cjs", "npm:string-width@4.2.3")
+	assert.Equal(t, "string-width", name)
+	assert.Equal(t, "4.2.3", version)
+
+	name, version = npmAlias("core", "npm:@babel/core@7.24.0")
+	assert.Equal(t, "@babel/core", name)
+	assert.Equal(t, "7.24.0", version)
+
+	name, version = npmAlias("ms", "2.1.2")
+	assert.Equal(t, "ms", name)
+	assert.Equal(t, "2.1.2", version)
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// nugetLock contains the parts of a packages.lock.json file used to list packages:
+// the packages resolved for each target framework, and runtime, keyed by name.
+type nugetLock struct {
+	Dependencies map[string]map[string]nugetPackage `json:"dependencies"`
+}
+
+// nugetPackage is a package of a packages.lock.json file.
+type nugetPackage struct {
+	Type         string            `json:"type"` // Direct, Transitive, CentralTransitive or Project.
+	Resolved     string            `json:"resolved"`
+	ContentHash  string            `json:"contentHash"` // Base64 SHA-512 digest of the package.
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// parseNuGetLock parses a packages.lock.json file. Packages are keyed by lowercase name
+// and version, as NuGet names are case insensitive and each target framework may
+// resolve a different version, and the packages of every target framework are listed.
+// Direct packages are direct dependencies of the project, and referenced projects are
+// looked through. Content hashes are SHA-512 hashes.
+func parseNuGetLock(source []byte) (*lockfile, error) {
+	var document nugetLock
+	if err := json.Unmarshal(source, &document); err != nil {
+		return nil, err
+	}
+
+	lock := newLockfile(packageurl.TypeNuget)
+	for _, packages := range document.Dependencies {
+		versions := map[string]string{}
+		for name, entry := range packages {
+			versions[strings.ToLower(name)] = entry.Resolved
+		}
+		for name, entry := range packages {
+			id := strings.ToLower(name) + "@" + entry.Resolved
+			if _, ok := lock.Packages[id]; !ok {
+				p := &lockPackage{Name: name, Version: entry.Resolved, Local: entry.Type == "Project"}
+				if entry.ContentHash != "" {
+					p.Hashes = integrityHashes("sha512-" + entry.ContentHash)
+				}
+				lock.Packages[id] = p
+			}
+			for dependency := range entry.Dependencies {
+				dependency = strings.ToLower(dependency)
+				lock.Dependencies[id] = appendUnique(lock.Dependencies[id], dependency+"@"+versions[dependency])
+			}
+			if entry.Type == "Direct" || entry.Type == "Project" {
+				lock.addDirect(id, false)
+			}
+		}
+	}
+	return lock, nil
+}
@@ -0,0 +1,78 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testNuGetLock = `{
+  "version": 1,
+  "dependencies": {
+    "net8.0": {
+      "Serilog.Sinks.Console": {
+        "type": "Direct",
+        "requested": "[5.0.1, )",
+        "resolved": "5.0.1",
+        "contentHash": "qUr1Sg6f8bLcPL9z19CQrkNCeG8=",
+        "dependencies": {
+          "serilog": "3.1.1"
+        }
+      },
+      "Serilog": {
+        "type": "Transitive",
+        "resolved": "3.1.1"
+      },
+      "Shared": {
+        "type": "Project",
+        "dependencies": {
+          "Newtonsoft.Json": "[13.0.3, )"
+        }
+      },
+      "Newtonsoft.Json": {
+        "type": "CentralTransitive",
+        "requested": "[13.0.3, )",
+        "resolved": "13.0.3"
+      }
+    },
+    "net6.0": {
+      "Serilog": {
+        "type": "Direct",
+        "requested": "[2.12.0, )",
+        "resolved": "2.12.0"
+      }
+    }
+  }
+}`
+
+func TestParseNuGetLock(t *testing.T) {
+	l, err := parseNuGetLock([]byte(testNuGetLock))
+	assert.NoError(t, err)
+
+	assert.Len(t, l.Packages, 5)
+	assert.Equal(t, &lockPackage{
+		Name:    "Serilog.Sinks.Console",
+		Version: "5.0.1",
+		Hashes:  map[string]string{"SHA-512": "a94af54a0e9ff1b2dc3cbf73d7d090ae4342786f"},
+	}, l.Packages["serilog.sinks.console@5.0.1"])
+	assert.True(t, l.Packages["shared@"].Local)
+	assert.Equal(t, []string{"serilog@3.1.1"}, l.Dependencies["serilog.sinks.console@5.0.1"])
+	assert.Equal(t, []string{"newtonsoft.json@13.0.3"}, l.Dependencies["shared@"])
+	assert.ElementsMatch(t, []string{"serilog.sinks.console@5.0.1", "shared@", "serilog@2.12.0"}, l.Dependencies[""])
+
+	purls := []string{}
+	for _, p := range l.kissBOM(nil).Packages {
+		purls = append(purls, p.Purl)
+	}
+	assert.Equal(t, []string{
+		"pkg:nuget/Newtonsoft.Json@13.0.3",
+		"pkg:nuget/Serilog.Sinks.Console@5.0.1",
+		"pkg:nuget/Serilog@2.12.0",
+		"pkg:nuget/Serilog@3.1.1",
+	}, purls)
+}
+
+func TestParseNuGetLock_Error(t *testing.T) {
+	_, err := parseNuGetLock([]byte("{"))
+	assert.Error(t, err)
+}
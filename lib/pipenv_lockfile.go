@@ -0,0 +1,56 @@
+package lib
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// pipfileLock contains the parts of a Pipfile.lock file used to list packages.
+type pipfileLock struct {
+	Default map[string]pipfileLockPackage `json:"default"`
+	Develop map[string]pipfileLockPackage `json:"develop"`
+}
+
+// pipfileLockPackage is a package of a Pipfile.lock file.
+type pipfileLockPackage struct {
+	Version  string   `json:"version"` // Pinned version (ex: ==2.31.0).
+	Hashes   []string `json:"hashes"`
+	Path     string   `json:"path"`
+	Editable bool     `json:"editable"`
+}
+
+// parsePipfileLock parses a Pipfile.lock file. Packages are keyed by normalized name,
+// and those only listed in the develop section are development packages. The lockfile
+// does not record the dependencies of each package, so the Pipfile, when present,
+// provides the direct dependencies of the project.
+func parsePipfileLock(source []byte, manifest pythonManifest) (*lockfile, error) {
+	var document pipfileLock
+	if err := json.Unmarshal(source, &document); err != nil {
+		return nil, err
+	}
+
+	lock := newLockfile(packageurl.TypePyPi)
+	for _, section := range []map[string]pipfileLockPackage{document.Develop, document.Default} {
+		for name, entry := range section {
+			name = pypiName(name)
+			lock.Packages[name] = &lockPackage{
+				Name:    name,
+				Version: strings.TrimLeft(entry.Version, "="),
+				Hashes:  pythonHashes(entry.Hashes),
+				Local:   entry.Path != "" || entry.Editable,
+			}
+		}
+	}
+	regular := map[string]bool{}
+	for name := range document.Default {
+		regular[pypiName(name)] = true
+	}
+	for name := range document.Develop {
+		lock.Packages[pypiName(name)].Dev = !regular[pypiName(name)]
+	}
+
+	lock.addPythonManifest(manifest)
+	return lock, nil
+}
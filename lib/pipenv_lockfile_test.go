@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPipfileLock = `{
+    "_meta": {"hash": {"sha256": "abc"}, "pipfile-spec": 6},
+    "default": {
+        "certifi": {
+            "hashes": ["sha256:9b469f3a900bf28dc19b8cfbf8019bf47f7fdd1a65a1d4ffb98fc14166beb4d1"],
+            "version": "==2024.2.2"
+        },
+        "Requests": {
+            "hashes": [
+                "sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f",
+                "sha256:942c5a758f98d790eaed1a29cb6eefc7ffb0d1cf7af05c3d2791656dbd6ad1e1"
+            ],
+            "index": "pypi",
+            "version": "==2.31.0"
+        },
+        "lib": {"editable": true, "path": "./packages/lib"}
+    },
+    "develop": {
+        "certifi": {"version": "==2024.2.2"},
+        "pytest": {"version": "==8.1.1"}
+    }
+}`
+
+func TestParsePipfileLock(t *testing.T) {
+	manifest := pythonManifest{Dependencies: []string{"requests", "lib"}, DevDependencies: []string{"pytest"}}
+	l, err := parsePipfileLock([]byte(testPipfileLock), manifest)
+	assert.NoError(t, err)
+
+	assert.Len(t, l.Packages, 4)
+	assert.Equal(t, &lockPackage{
+		Name:    "certifi",
+		Version: "2024.2.2",
+		Hashes:  map[string]string{"SHA-256": "9b469f3a900bf28dc19b8cfbf8019bf47f7fdd1a65a1d4ffb98fc14166beb4d1"},
+	}, l.Packages["certifi"])
+	assert.Equal(t, &lockPackage{Name: "requests", Version: "2.31.0"}, l.Packages["requests"])
+	assert.Equal(t, &lockPackage{Name: "pytest", Version: "8.1.1", Dev: true}, l.Packages["pytest"])
+	assert.True(t, l.Packages["lib"].Local)
+	assert.Equal(t, []string{"requests", "lib", "pytest"}, l.Dependencies[""])
+}
+
+func TestParsePipfileLock_Error(t *testing.T) {
+	_, err := parsePipfileLock([]byte("{"), pythonManifest{})
+	assert.Error(t, err)
+}
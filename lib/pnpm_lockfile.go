@@ -0,0 +1,170 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/package-url/packageurl-go"
+	"gopkg.in/yaml.v3"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// pnpmLock contains the parts of a pnpm-lock.yaml file used to list packages. Version 9
+// separates the packages from their dependencies, which are listed as snapshots.
+type pnpmLock struct {
+	LockfileVersion string                  `yaml:"lockfileVersion"`
+	Importers       map[string]pnpmImporter `yaml:"importers"`
+	Packages        map[string]pnpmPackage  `yaml:"packages"`
+	Snapshots       map[string]pnpmPackage  `yaml:"snapshots"`
+	pnpmImporter    `yaml:",inline"`        // Direct dependencies of the project, before version 9 without workspaces.
+}
+
+// pnpmImporter lists the direct dependencies of a project or workspace.
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmVersion `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmVersion `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmVersion `yaml:"optionalDependencies"`
+}
+
+// pnpmVersion is the resolved version of a direct dependency, written as a version
+// before version 6, and as a specifier and version afterwards.
+type pnpmVersion string
+
+// UnmarshalYAML decodes both forms of a pnpmVersion.
+func (v *pnpmVersion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = pnpmVersion(node.Value)
+		return nil
+	}
+	var spec struct {
+		Version string `yaml:"version"`
+	}
+	err := node.Decode(&spec)
+	*v = pnpmVersion(spec.Version)
+	return err
+}
+
+// pnpmPackage is a package, or a snapshot of its dependencies, of a pnpm lockfile.
+type pnpmPackage struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Resolution struct {
+		Integrity string `yaml:"integrity"`
+	} `yaml:"resolution"`
+	Dev                  *bool             `yaml:"dev"`
+	Optional             bool              `yaml:"optional"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// parsePnpmLock parses a pnpm lockfile in version 5, 6 or 9. Packages are identified by
+// their key, including the versions of their peer dependencies, which is the key of
+// their snapshot in version 9. Development packages are flagged by pnpm before version
+// 9, and found from the development dependencies of the importers afterwards.
+func parsePnpmLock(source []byte) (*lockfile, error) {
+	var lock pnpmLock
+	if err := yaml.Unmarshal(source, &lock); err != nil {
+		return nil, err
+	}
+	major, _, _ := strings.Cut(lock.LockfileVersion, ".")
+	if major != "5" && major != "6" && major != "9" {
+		return nil, fmt.Errorf("unsupported lockfileVersion %q", lock.LockfileVersion)
+	}
+
+	l := newLockfile(packageurl.TypeNPM)
+	snapshots := lock.Packages
+	if major == "9" {
+		snapshots = lock.Snapshots
+	}
+	for id, snapshot := range snapshots {
+		name, version := pnpmNameVersion(id, major)
+		p := snapshot
+		if major == "9" {
+			p = lock.Packages[name+"@"+version]
+		}
+		l.Packages[id] = &lockPackage{
+			Name:      models.FirstNonEmpty(p.Name, name),
+			Version:   models.FirstNonEmpty(p.Version, version),
+			Integrity: p.Resolution.Integrity,
+			Dev:       p.Dev != nil && *p.Dev,
+			Optional:  p.Optional || snapshot.Optional,
+		}
+	}
+
+	resolve := func(name string, version string) string {
+		id := pnpmDependencyID(name, version, major)
+		if _, ok := l.Packages[id]; !ok {
+			return ""
+		}
+		return id
+	}
+	for id, snapshot := range snapshots {
+		for _, dependencies := range []map[string]string{snapshot.Dependencies, snapshot.OptionalDependencies} {
+			for name, version := range dependencies {
+				if dependency := resolve(name, version); dependency != "" {
+					l.Dependencies[id] = append(l.Dependencies[id], dependency)
+				}
+			}
+		}
+	}
+
+	importers := lock.Importers
+	if importers == nil {
+		importers = map[string]pnpmImporter{".": lock.pnpmImporter}
+	}
+	for _, importer := range importers {
+		for _, dependencies := range []map[string]pnpmVersion{importer.Dependencies, importer.OptionalDependencies} {
+			for name, version := range dependencies {
+				l.addDirect(resolve(name, string(version)), false)
+			}
+		}
+		for name, version := range importer.DevDependencies {
+			l.addDirect(resolve(name, string(version)), true)
+		}
+	}
+	if major == "9" {
+		l.markDev()
+	}
+	return l, nil
+}
+
+// pnpmNameVersion returns the name and version of a package key, without the versions
+// of its peer dependencies. Keys are /name/version_peers in version 5, /name@version(peers)
+// in version 6 and name@version(peers) in version 9.
+func pnpmNameVersion(key string, major string) (name string, version string) {
+	key = strings.TrimPrefix(key, "/")
+	if major == "5" {
+		i := strings.LastIndex(key, "/")
+		if i < 0 {
+			return key, ""
+		}
+		version, _, _ = strings.Cut(key[i+1:], "_")
+		return key[:i], version
+	}
+	key, _, _ = strings.Cut(key, "(")
+	if i := strings.LastIndex(key, "@"); i > 0 {
+		return key[:i], key[i+1:]
+	}
+	return key, ""
+}
+
+// pnpmDependencyID returns the key of the package a dependency resolves to. Versions are
+// usually resolved to a version of the same package, but may also be the key of another
+// package, for aliases and packages that are not from the registry.
+func pnpmDependencyID(name string, version string, major string) string {
+	switch {
+	case strings.HasPrefix(version, "link:"), strings.HasPrefix(version, "file:"):
+		return ""
+	case strings.HasPrefix(version, "/"):
+		return version
+	case major == "5":
+		return "/" + name + "/" + version
+	case major == "6":
+		return "/" + name + "@" + version
+	case version != "" && !unicode.IsDigit(rune(version[0])) && strings.Contains(version, "@"):
+		return version
+	}
+	return name + "@" + version
+}
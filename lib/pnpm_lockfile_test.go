@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPnpmLockV5 = `lockfileVersion: 5.4
+
+specifiers:
+  '@babel/core': ^7.0.0
+  jest: ^29.0.0
+
+dependencies:
+  '@babel/core': 7.24.0_debug@4.3.4
+
+devDependencies:
+  jest: 29.7.0
+
+packages:
+
+  /@babel/core/7.24.0_debug@4.3.4:
+    resolution: {integrity: sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8=}
+    dependencies:
+      debug: 4.3.4
+    dev: false
+
+  /debug/4.3.4:
+    resolution: {integrity: sha512-abc}
+    dependencies:
+      ms: 2.1.2
+    dev: false
+
+  /ms/2.1.2:
+    resolution: {integrity: sha512-def}
+
+  /jest/29.7.0:
+    resolution: {integrity: sha512-ghi}
+    dependencies:
+      ms: 2.1.2
+    optionalDependencies:
+      fsevents: 2.3.3
+    dev: true
+
+  /fsevents/2.3.3:
+    resolution: {integrity: sha512-jkl}
+    dev: true
+    optional: true
+`
+
+const testPnpmLockV6 = `lockfileVersion: '6.0'
+
+dependencies:
+  '@babel/core':
+    specifier: ^7.0.0
+    version: 7.24.0(debug@4.3.4)
+  lib:
+    specifier: link:packages/lib
+    version: link:packages/lib
+
+devDependencies:
+  jest:
+    specifier: ^29.0.0
+    version: 29.7.0
+
+packages:
+
+  /@babel/core@7.24.0(debug@4.3.4):
+    resolution: {integrity: sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8=}
+    dependencies:
+      debug: 4.3.4
+    dev: false
+
+  /debug@4.3.4:
+    resolution: {integrity: sha512-abc}
+    dependencies:
+      ms: 2.1.2
+    dev: false
+
+  /ms@2.1.2:
+    resolution: {integrity: sha512-def}
+    dev: false
+
+  /jest@29.7.0:
+    resolution: {integrity: sha512-ghi}
+    dev: true
+`
+
+const testPnpmLockV9 = `lockfileVersion: '9.0'
+
+settings:
+  autoInstallPeers: true
+  excludeLinksFromLockfile: false
+
+importers:
+
+  .:
+    dependencies:
+      '@babel/core':
+        specifier: ^7.0.0
+        version: 7.24.0(debug@4.3.4)
+      string-width-cjs:
+        specifier: npm:string-width@^4.2.0
+        version: string-width@4.2.3
+    devDependencies:
+      jest:
+        specifier: ^29.0.0
+        version: 29.7.0
+
+  packages/lib:
+    dependencies:
+      ms:
+        specifier: 2.1.2
+        version: 2.1.2
+
+packages:
+
+  '@babel/core@7.24.0':
+    resolution: {integrity: sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8=}
+
+  debug@4.3.4:
+    resolution: {integrity: sha512-abc}
+
+  jest@29.7.0:
+    resolution: {integrity: sha512-ghi}
+
+  ms@2.0.0:
+    resolution: {integrity: sha512-jkl}
+
+  ms@2.1.2:
+    resolution: {integrity: sha512-def}
+
+  string-width@4.2.3:
+    resolution: {integrity: sha512-mno}
+
+snapshots:
+
+  '@babel/core@7.24.0(debug@4.3.4)':
+    dependencies:
+      debug: 4.3.4
+
+  debug@4.3.4:
+    dependencies:
+      ms: 2.1.2
+
+  jest@29.7.0:
+    dependencies:
+      ms: 2.0.0
+
+  ms@2.0.0: {}
+
+  ms@2.1.2: {}
+
+  string-width@4.2.3: {}
+`
+
+func TestParsePnpmLock_V5(t *testing.T) {
+	l, err := parsePnpmLock([]byte(testPnpmLockV5))
+	assert.NoError(t, err)
+
+	assert.Equal(t, &lockPackage{Name: "@babel/core", Version: "7.24.0", Integrity: "sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8="}, l.Packages["/@babel/core/7.24.0_debug@4.3.4"])
+	assert.Equal(t, &lockPackage{Name: "fsevents", Version: "2.3.3", Integrity: "sha512-jkl", Dev: true, Optional: true}, l.Packages["/fsevents/2.3.3"])
+	assert.False(t, l.Packages["/ms/2.1.2"].Dev)
+	assert.Equal(t, []string{"/debug/4.3.4"}, l.Dependencies["/@babel/core/7.24.0_debug@4.3.4"])
+	assert.ElementsMatch(t, []string{"/ms/2.1.2", "/fsevents/2.3.3"}, l.Dependencies["/jest/29.7.0"])
+	assert.ElementsMatch(t, []string{"/@babel/core/7.24.0_debug@4.3.4", "/jest/29.7.0"}, l.Dependencies[""])
+}
+
+func TestParsePnpmLock_V6(t *testing.T) {
+	l, err := parsePnpmLock([]byte(testPnpmLockV6))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "@babel/core", l.Packages["/@babel/core@7.24.0(debug@4.3.4)"].Name)
+	assert.Equal(t, "7.24.0", l.Packages["/@babel/core@7.24.0(debug@4.3.4)"].Version)
+	assert.True(t, l.Packages["/jest@29.7.0"].Dev)
+	assert.Equal(t, []string{"/ms@2.1.2"}, l.Dependencies["/debug@4.3.4"])
+	assert.ElementsMatch(t, []string{"/@babel/core@7.24.0(debug@4.3.4)", "/jest@29.7.0"}, l.Dependencies[""])
+}
+
+func TestParsePnpmLock_V9(t *testing.T) {
+	l, err := parsePnpmLock([]byte(testPnpmLockV9))
+	assert.NoError(t, err)
+
+	assert.Len(t, l.Packages, 6)
+	assert.Equal(t, &lockPackage{Name: "@babel/core", Version: "7.24.0", Integrity: "sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8="}, l.Packages["@babel/core@7.24.0(debug@4.3.4)"])
+	assert.Equal(t, []string{"debug@4.3.4"}, l.Dependencies["@babel/core@7.24.0(debug@4.3.4)"])
+	assert.ElementsMatch(t, []string{"@babel/core@7.24.0(debug@4.3.4)", "string-width@4.2.3", "jest@29.7.0", "ms@2.1.2"}, l.Dependencies[""])
+
+	assert.True(t, l.Packages["jest@29.7.0"].Dev)
+	assert.True(t, l.Packages["ms@2.0.0"].Dev)
+	assert.False(t, l.Packages["ms@2.1.2"].Dev)
+	assert.False(t, l.Packages["string-width@4.2.3"].Dev)
+}
+
+func TestParsePnpmLock_Errors(t *testing.T) {
+	_, err := parsePnpmLock([]byte("lockfileVersion: '4.0'\n"))
+	assert.EqualError(t, err, `unsupported lockfileVersion "4.0"`)
+
+	_, err = parsePnpmLock([]byte("lockfileVersion: [\n"))
+	assert.Error(t, err)
+}
+
+func TestPnpmNameVersion(t *testing.T) {
+	for _, test := range []struct {
+		key     string
+		major   string
+		name    string
+		version string
+	}{
+		{"/@babel/core/7.24.0_debug@4.3.4", "5", "@babel/core", "7.24.0"},
+		{"/ms/2.1.2", "5", "ms", "2.1.2"},
+		{"/@babel/core@7.24.0(debug@4.3.4)", "6", "@babel/core", "7.24.0"},
+		{"ms@2.1.2", "9", "ms", "2.1.2"},
+		{"@babel/core@7.24.0(debug@4.3.4)(supports-color@8.1.1)", "9", "@babel/core", "7.24.0"},
+	} {
+		name, version := pnpmNameVersion(test.key, test.major)
+		assert.Equal(t, test.name, name, test.key)
+		assert.Equal(t, test.version, version, test.key)
+	}
+}
+
+func TestPnpmDependencyID(t *testing.T) {
+	assert.Equal(t, "/ms/2.1.2", pnpmDependencyID("ms", "2.1.2", "5"))
+	assert.Equal(t, "/ms@2.1.2", pnpmDependencyID("ms", "2.1.2", "6"))
+	assert.Equal(t, "ms@2.1.2", pnpmDependencyID("ms", "2.1.2", "9"))
+	assert.Equal(t, "string-width@4.2.3", pnpmDependencyID("string-width-cjs", "string-width@4.2.3", "9"))
+	assert.Equal(t, "/string-width@4.2.3", pnpmDependencyID("string-width-cjs", "/string-width@4.2.3", "6"))
+	assert.Equal(t, "", pnpmDependencyID("lib", "link:packages/lib", "9"))
+}
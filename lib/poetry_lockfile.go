@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/package-url/packageurl-go"
+)
+
+// poetryLock contains the parts of a poetry.lock file used to list packages.
+type poetryLock struct {
+	Packages []poetryPackage `toml:"package"`
+	Metadata struct {
+		Files map[string][]poetryFile `toml:"files"` // Files of each package, before Poetry 1.2.
+	} `toml:"metadata"`
+}
+
+// poetryPackage is a package of a poetry.lock file.
+type poetryPackage struct {
+	Name     string       `toml:"name"`
+	Version  string       `toml:"version"`
+	Category string       `toml:"category"` // Either main or dev, before Poetry 1.5.
+	Optional bool         `toml:"optional"`
+	Groups   []string     `toml:"groups"` // Dependency groups, since Poetry 2.
+	Files    []poetryFile `toml:"files"`
+	Source   struct {
+		Type string `toml:"type"`
+	} `toml:"source"`
+	Dependencies map[string]any `toml:"dependencies"` // Version constraints, or tables, by name.
+}
+
+// poetryFile is a distribution of a package of a poetry.lock file.
+type poetryFile struct {
+	File string `toml:"file"`
+	Hash string `toml:"hash"`
+}
+
+// parsePoetryLock parses a poetry.lock file. Packages are keyed by normalized name, as
+// Poetry resolves a single version of each. Lockfiles written before Poetry 1.5 flag
+// development packages with their category, and those written by Poetry 2 list the
+// dependency groups of each package; for the others, development packages are those
+// only reached from the development groups of pyproject.toml.
+func parsePoetryLock(source []byte, manifest pythonManifest) (*lockfile, error) {
+	var document poetryLock
+	if err := toml.Unmarshal(source, &document); err != nil {
+		return nil, err
+	}
+
+	lock := newLockfile(packageurl.TypePyPi)
+	flagged := false
+	for _, entry := range document.Packages {
+		name := pypiName(entry.Name)
+		p := &lockPackage{Name: name, Version: entry.Version, Optional: entry.Optional}
+
+		switch entry.Source.Type {
+		case "directory", "file":
+			p.Local = true
+		}
+
+		if entry.Category != "" {
+			flagged, p.Dev = true, entry.Category == "dev"
+		}
+		if entry.Groups != nil {
+			flagged, p.Dev = true, !containsString(entry.Groups, "main")
+		}
+
+		files := entry.Files
+		if files == nil {
+			files = document.Metadata.Files[entry.Name]
+		}
+		for _, file := range files {
+			if isPythonSdist(file.File) {
+				p.Hashes = pythonHashes([]string{file.Hash})
+			}
+		}
+
+		lock.Packages[name] = p
+		for dependency := range entry.Dependencies {
+			lock.Dependencies[name] = append(lock.Dependencies[name], pypiName(dependency))
+		}
+	}
+
+	lock.addPythonManifest(manifest)
+	if !flagged {
+		lock.markDev()
+	}
+	return lock, nil
+}
@@ -0,0 +1,137 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPoetryLock = `# This file is automatically @generated by Poetry 1.8.2 and should not be changed by hand.
+
+[[package]]
+name = "Flask"
+version = "3.0.2"
+description = "A simple framework for building complex web applications."
+optional = false
+python-versions = ">=3.8"
+files = [
+    {file = "flask-3.0.2-py3-none-any.whl", hash = "sha256:3232e0e9c850d781933cf0207523d1ece087eb8d87b23777ae38456e2fbe7c6e"},
+    {file = "flask-3.0.2.tar.gz", hash = "sha256:822c03f4b799204250a7ee84b1eddc8b4c5d23b4a3c8bd5d7e4feb1b5a6e1fdd"},
+]
+
+[package.dependencies]
+Werkzeug = ">=3.0.0"
+
+[[package]]
+name = "pytest"
+version = "8.1.1"
+description = "pytest: simple powerful testing with Python"
+optional = false
+python-versions = ">=3.8"
+files = []
+
+[package.dependencies]
+iniconfig = "*"
+
+[[package]]
+name = "iniconfig"
+version = "2.0.0"
+description = "brain-dead simple config-ini parsing"
+optional = false
+python-versions = ">=3.7"
+files = []
+
+[[package]]
+name = "werkzeug"
+version = "3.0.1"
+description = "The comprehensive WSGI web application library."
+optional = false
+python-versions = ">=3.8"
+files = []
+
+[[package]]
+name = "lib"
+version = "0.1.0"
+description = ""
+optional = false
+python-versions = "^3.12"
+files = []
+develop = true
+
+[package.source]
+type = "directory"
+url = "packages/lib"
+
+[metadata]
+lock-version = "2.0"
+python-versions = "^3.12"
+content-hash = "abc"
+`
+
+const testPoetryLockLegacy = `[[package]]
+name = "flask"
+version = "3.0.2"
+description = ""
+category = "main"
+optional = false
+python-versions = ">=3.8"
+
+[[package]]
+name = "pytest"
+version = "8.1.1"
+description = ""
+category = "dev"
+optional = false
+python-versions = ">=3.8"
+
+[metadata]
+lock-version = "1.1"
+python-versions = "^3.12"
+content-hash = "abc"
+
+[metadata.files]
+flask = [
+    {file = "flask-3.0.2.tar.gz", hash = "sha256:822c03f4b799204250a7ee84b1eddc8b4c5d23b4a3c8bd5d7e4feb1b5a6e1fdd"},
+]
+pytest = []
+`
+
+func TestParsePoetryLock(t *testing.T) {
+	manifest := pythonManifest{Dependencies: []string{"flask", "lib"}, DevDependencies: []string{"pytest"}}
+	l, err := parsePoetryLock([]byte(testPoetryLock), manifest)
+	assert.NoError(t, err)
+
+	assert.Len(t, l.Packages, 5)
+	assert.Equal(t, &lockPackage{
+		Name:    "flask",
+		Version: "3.0.2",
+		Hashes:  map[string]string{"SHA-256": "822c03f4b799204250a7ee84b1eddc8b4c5d23b4a3c8bd5d7e4feb1b5a6e1fdd"},
+	}, l.Packages["flask"])
+	assert.True(t, l.Packages["lib"].Local)
+	assert.Equal(t, []string{"werkzeug"}, l.Dependencies["flask"])
+	assert.Equal(t, []string{"flask", "lib", "pytest"}, l.Dependencies[""])
+
+	assert.False(t, l.Packages["werkzeug"].Dev)
+	assert.True(t, l.Packages["pytest"].Dev)
+	assert.True(t, l.Packages["iniconfig"].Dev)
+}
+
+func TestParsePoetryLock_Flagged(t *testing.T) {
+	l, err := parsePoetryLock([]byte(testPoetryLockLegacy), pythonManifest{})
+	assert.NoError(t, err)
+	assert.Equal(t, &lockPackage{
+		Name:    "flask",
+		Version: "3.0.2",
+		Hashes:  map[string]string{"SHA-256": "822c03f4b799204250a7ee84b1eddc8b4c5d23b4a3c8bd5d7e4feb1b5a6e1fdd"},
+	}, l.Packages["flask"])
+	assert.True(t, l.Packages["pytest"].Dev)
+
+	l, err = parsePoetryLock([]byte("[[package]]\nname = \"pytest\"\nversion = \"8.1.1\"\ngroups = [\"test\"]\n"), pythonManifest{})
+	assert.NoError(t, err)
+	assert.True(t, l.Packages["pytest"].Dev)
+}
+
+func TestParsePoetryLock_Error(t *testing.T) {
+	_, err := parsePoetryLock([]byte("[[package]\n"), pythonManifest{})
+	assert.EqualError(t, err, `toml: line 2: expected end of table array name delimiter ']', but got '\n' instead`)
+}
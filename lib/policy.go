@@ -0,0 +1,353 @@
+package lib
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Enumeration of policy decisions, from least to most severe.
+const (
+	DecisionAllow  = "allow"  // DecisionAllow means the package may be released.
+	DecisionReview = "review" // DecisionReview means the package must be reviewed before release.
+	DecisionDeny   = "deny"   // DecisionDeny means the package blocks a release.
+)
+
+// decisionRanks orders decisions by severity.
+var decisionRanks = map[string]int{DecisionAllow: 0, DecisionReview: 1, DecisionDeny: 2}
+
+// decisionsByRank maps a severity back to its decision.
+var decisionsByRank = []string{DecisionAllow, DecisionReview, DecisionDeny}
+
+// Policy is a license policy loaded from a YAML file.
+//
+// Licenses are matched by SPDX identifier without regard to case. Licenses that are
+// not found in any list receive the Default decision. Exceptions allow specific
+// packages or ecosystems to use otherwise denied licenses until they expire.
+type Policy struct {
+	Allow            []string          `yaml:"allow" json:"allow,omitempty"`                       // SPDX identifiers that are allowed.
+	Deny             []string          `yaml:"deny" json:"deny,omitempty"`                         // SPDX identifiers that block a release.
+	Review           []string          `yaml:"review" json:"review,omitempty"`                     // SPDX identifiers that require review.
+	Default          string            `yaml:"default" json:"default,omitempty"`                   // Decision for licenses not found in any list, defaults to review.
+	MissingLicense   string            `yaml:"missingLicense" json:"missingLicense,omitempty"`     // Decision for packages with no license, defaults to review.
+	MissingCopyright string            `yaml:"missingCopyright" json:"missingCopyright,omitempty"` // Decision for packages with no copyright, defaults to allow.
+	Exceptions       []PolicyException `yaml:"exceptions" json:"exceptions,omitempty"`             // Packages or ecosystems exempt from the license lists.
+}
+
+// PolicyException allows matching packages to use the listed licenses (or any
+// license when none are listed) until the exception expires.
+type PolicyException struct {
+	Ecosystem string   `yaml:"ecosystem" json:"ecosystem,omitempty"` // PURL type the exception applies to (ex: npm).
+	Package   string   `yaml:"package" json:"package,omitempty"`     // Glob pattern matched against the package PURL (ex: pkg:npm/left-pad@*).
+	Licenses  []string `yaml:"licenses" json:"licenses,omitempty"`   // SPDX identifiers the exception applies to, all licenses when empty.
+	Expires   string   `yaml:"expires" json:"expires,omitempty"`     // Date (YYYY-MM-DD) after which the exception no longer applies.
+	Reason    string   `yaml:"reason" json:"reason,omitempty"`       // Why the exception was granted.
+}
+
+// PolicyResult is the outcome of evaluating the policy for a single package.
+type PolicyResult struct {
+	Purl     string   `json:"purl"`
+	License  string   `json:"license,omitempty"`
+	Category string   `json:"category"`
+	Decision string   `json:"decision"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+// PolicyReport contains the results of evaluating a policy against a KissBOM.
+type PolicyReport struct {
+	Source  string         `json:"source"`
+	FailOn  string         `json:"failOn"`
+	Passed  bool           `json:"passed"`
+	Allowed int            `json:"allowed"`
+	Review  int            `json:"review"`
+	Denied  int            `json:"denied"`
+	Results []PolicyResult `json:"results"`
+}
+
+// LoadPolicy reads and validates a YAML policy file.
+func LoadPolicy(afs *afero.Afero, filename string) (policy Policy, err error) {
+	data, err := afs.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	if err = yaml.Unmarshal(data, &policy); err != nil {
+		return
+	}
+	return policy, policy.validate()
+}
+
+// validate applies defaults and checks that all decisions and dates are valid.
+func (p *Policy) validate() error {
+	defaults := []struct {
+		value    *string
+		name     string
+		fallback string
+	}{
+		{&p.Default, "default", DecisionReview},
+		{&p.MissingLicense, "missingLicense", DecisionReview},
+		{&p.MissingCopyright, "missingCopyright", DecisionAllow},
+	}
+	for _, d := range defaults {
+		if *d.value == "" {
+			*d.value = d.fallback
+		}
+		if _, ok := decisionRanks[*d.value]; !ok {
+			return fmt.Errorf("invalid %s decision %q, valid options are: %s", d.name, *d.value, decisionsByRank)
+		}
+	}
+	for _, e := range p.Exceptions {
+		if e.Expires == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, e.Expires); err != nil {
+			return fmt.Errorf("invalid expiry date %q for exception %s%s", e.Expires, e.Ecosystem, e.Package)
+		}
+	}
+	return nil
+}
+
+// Evaluate checks every package of the KissBOM against the policy. Exceptions that
+// expired before now are ignored. The report fails if any package receives a
+// decision at least as severe as failOn.
+func (p *Policy) Evaluate(kissbom models.KissBOM, failOn string, now time.Time) (report PolicyReport) {
+	report.FailOn = failOn
+	report.Passed = true
+	for _, pkg := range kissbom.Packages {
+		result := p.evaluatePackage(pkg, now)
+		switch result.Decision {
+		case DecisionAllow:
+			report.Allowed++
+		case DecisionReview:
+			report.Review++
+		case DecisionDeny:
+			report.Denied++
+		}
+		if decisionRanks[result.Decision] >= decisionRanks[failOn] {
+			report.Passed = false
+		}
+		report.Results = append(report.Results, result)
+	}
+	return
+}
+
+// evaluatePackage determines the decision for a single package.
+func (p *Policy) evaluatePackage(pkg models.Package, now time.Time) PolicyResult {
+	result := PolicyResult{Purl: pkg.Purl, License: pkg.License, Category: models.LicenseCategory(pkg.License)}
+	rank := 0
+
+	if strings.TrimSpace(pkg.License) == "" {
+		rank = decisionRanks[p.MissingLicense]
+		result.Reasons = append(result.Reasons, fmt.Sprintf("missing license (%s)", p.MissingLicense))
+	} else {
+		licenses := []*models.LicenseExpression{{ID: strings.TrimSpace(pkg.License)}}
+		expression, err := models.ParseLicenseExpression(pkg.License)
+		if err == nil {
+			licenses = expression.Licenses()
+		} else {
+			expression = licenses[0]
+		}
+
+		decisions := map[*models.LicenseExpression]string{}
+		for _, license := range licenses {
+			decision, reason := p.licenseDecision(pkg.Purl, license, now)
+			decisions[license] = decision
+			if decision != DecisionAllow || reason != "" {
+				result.Reasons = append(result.Reasons, fmt.Sprintf("%s: %s", license, models.FirstNonEmpty(reason, decision)))
+			}
+		}
+		rank = expression.Evaluate(func(license *models.LicenseExpression) int {
+			return decisionRanks[decisions[license]]
+		})
+	}
+
+	if strings.TrimSpace(pkg.Copyright) == "" && decisionRanks[p.MissingCopyright] > 0 {
+		if decisionRanks[p.MissingCopyright] > rank {
+			rank = decisionRanks[p.MissingCopyright]
+		}
+		result.Reasons = append(result.Reasons, fmt.Sprintf("missing copyright (%s)", p.MissingCopyright))
+	}
+
+	result.Decision = decisionsByRank[rank]
+	return result
+}
+
+// licenseDecision determines the decision for a single license of a package, along
+// with the reason when an exception was considered.
+func (p *Policy) licenseDecision(purl string, license *models.LicenseExpression, now time.Time) (decision string, reason string) {
+	for _, e := range p.Exceptions {
+		if !e.matches(purl, license) {
+			continue
+		}
+		if e.expired(now) {
+			reason = fmt.Sprintf("exception expired on %s", e.Expires)
+			continue
+		}
+		return DecisionAllow, fmt.Sprintf("allowed by exception (%s)", models.FirstNonEmpty(e.Reason, "no reason given"))
+	}
+
+	switch {
+	case licenseListed(p.Deny, license):
+		decision = DecisionDeny
+	case licenseListed(p.Review, license):
+		decision = DecisionReview
+	case licenseListed(p.Allow, license):
+		decision = DecisionAllow
+	default:
+		decision = p.Default
+		reason = models.FirstNonEmpty(reason, "not listed in policy")
+	}
+	if reason != "" {
+		reason = fmt.Sprintf("%s, %s", decision, reason)
+	}
+	return
+}
+
+// matches returns true if the exception applies to the package and license.
+func (e PolicyException) matches(purl string, license *models.LicenseExpression) bool {
+	if e.Ecosystem != "" && !(models.Filter{IncludeTypes: []string{e.Ecosystem}}).Allows(purl, "", "") {
+		return false
+	}
+	if e.Package != "" && !globMatchPurl(e.Package, purl) {
+		return false
+	}
+	return len(e.Licenses) == 0 || licenseListed(e.Licenses, license)
+}
+
+// expired returns true if the exception expired before now.
+func (e PolicyException) expired(now time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+	expires, err := time.Parse(time.DateOnly, e.Expires)
+	return err != nil || now.After(expires.AddDate(0, 0, 1))
+}
+
+// licenseListed returns true if the license matches one of the listed identifiers,
+// either exactly as written (including any "+" or WITH exception) or by identifier alone.
+func licenseListed(list []string, license *models.LicenseExpression) bool {
+	for _, id := range list {
+		id = strings.TrimSpace(id)
+		if strings.EqualFold(id, license.String()) || strings.EqualFold(id, license.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatchPurl matches a purl against a glob pattern, comparing canonical forms
+// when the pattern contains no wildcards.
+func globMatchPurl(pattern string, purl string) bool {
+	if !strings.ContainsAny(pattern, "*?") {
+		return models.CanonicalPurl(pattern) == models.CanonicalPurl(purl)
+	}
+	return models.GlobMatch(pattern, purl) || models.GlobMatch(pattern, models.CanonicalPurl(purl))
+}
+
+// Violations returns the results that were not allowed.
+func (r PolicyReport) Violations() (violations []PolicyResult) {
+	for _, result := range r.Results {
+		if result.Decision != DecisionAllow {
+			violations = append(violations, result)
+		}
+	}
+	return
+}
+
+// JSON converts the policy report to JSON format
+func (r PolicyReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "    ")
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit converts the policy report to JUnit XML, with one test case per package.
+// Packages with a decision at least as severe as the report's FailOn decision are
+// reported as failures.
+func (r PolicyReport) JUnit() ([]byte, error) {
+	suite := junitTestSuite{Name: fmt.Sprintf("kissbom license policy: %s", r.Source)}
+	for _, result := range r.Results {
+		testCase := junitTestCase{
+			Name:      result.Purl,
+			ClassName: "kissbom.policy",
+		}
+		message := fmt.Sprintf("%s: %s", result.Decision, strings.Join(result.Reasons, "; "))
+		if decisionRanks[result.Decision] >= decisionRanks[r.FailOn] {
+			testCase.Failure = &junitFailure{
+				Message: message,
+				Type:    result.Decision,
+				Text:    fmt.Sprintf("license: %s (%s)", result.License, result.Category),
+			}
+			suite.Failures++
+		} else if result.Decision != DecisionAllow {
+			testCase.SystemOut = message
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	suites := junitTestSuites{
+		Name:     "kissbom",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+	data, err := xml.MarshalIndent(suites, "", "    ")
+	return append([]byte(xml.Header), data...), err
+}
+
+// CheckPolicy reads the provided CycloneDX or KissBOM file and evaluates every
+// package against the policy file.
+func (c *Converter) CheckPolicy(filename string, policyFile string, failOn string) (report PolicyReport, err error) {
+	if _, ok := decisionRanks[failOn]; !ok || failOn == DecisionAllow {
+		err = fmt.Errorf("invalid fail-on decision %q, valid options are: %s", failOn, decisionsByRank[1:])
+		return
+	}
+
+	policy, err := LoadPolicy(c.Afs, policyFile)
+	if err != nil {
+		return
+	}
+
+	kissbom, err := c.load(filename)
+	if err != nil {
+		return
+	}
+	c.Duplicates = kissbom.Deduplicate()
+
+	report = policy.Evaluate(kissbom, failOn, time.Now())
+	report.Source = filename
+	return
+}
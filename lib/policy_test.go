@@ -0,0 +1,139 @@
+package lib
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const testPolicy = `
+allow: [MIT, Apache-2.0, BSD-3-Clause]
+deny: [AGPL-3.0-only, AGPL-3.0-or-later]
+review: [LGPL-2.1-only]
+default: review
+missingLicense: deny
+missingCopyright: review
+exceptions:
+  - package: pkg:npm/legacy-agpl@*
+    licenses: [AGPL-3.0-only]
+    expires: 2030-01-01
+    reason: replacement scheduled
+  - ecosystem: npm
+    package: pkg:npm/old-agpl@*
+    expires: 2020-01-01
+`
+
+func TestPolicy_Evaluate(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("policy.yaml", []byte(testPolicy), 0644))
+
+	policy, err := LoadPolicy(afs, "policy.yaml")
+	assert.NoError(t, err)
+
+	kissbom := models.KissBOM{
+		Packages: []models.Package{
+			{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Copyright: "Copyright JS Foundation"},
+			{Purl: "pkg:npm/choice@1.0.0", License: "AGPL-3.0-only OR MIT", Copyright: "Copyright"},
+			{Purl: "pkg:npm/both@1.0.0", License: "AGPL-3.0-only AND MIT", Copyright: "Copyright"},
+			{Purl: "pkg:npm/lgpl@1.0.0", License: "LGPL-2.1-only", Copyright: "Copyright"},
+			{Purl: "pkg:npm/unlicensed@1.0.0", Copyright: "Copyright"},
+			{Purl: "pkg:npm/nocopyright@1.0.0", License: "Apache-2.0"},
+			{Purl: "pkg:npm/legacy-agpl@2.0.0", License: "AGPL-3.0-only", Copyright: "Copyright"},
+			{Purl: "pkg:npm/old-agpl@2.0.0", License: "AGPL-3.0-only", Copyright: "Copyright"},
+			{Purl: "pkg:npm/unknown@1.0.0", License: "Some Custom License", Copyright: "Copyright"},
+		},
+	}
+
+	report := policy.Evaluate(kissbom, DecisionDeny, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	decisions := []string{}
+	for _, r := range report.Results {
+		decisions = append(decisions, r.Decision)
+	}
+	assert.Equal(t, []string{
+		DecisionAllow,
+		DecisionAllow,
+		DecisionDeny,
+		DecisionReview,
+		DecisionDeny,
+		DecisionReview,
+		DecisionAllow,
+		DecisionDeny,
+		DecisionReview,
+	}, decisions)
+	assert.False(t, report.Passed)
+	assert.Equal(t, 3, report.Allowed)
+	assert.Equal(t, 3, report.Review)
+	assert.Equal(t, 3, report.Denied)
+	assert.Len(t, report.Violations(), 6)
+	assert.Equal(t, models.CategoryNetworkCopyleft, report.Results[2].Category)
+	assert.Contains(t, strings.Join(report.Results[6].Reasons, ";"), "replacement scheduled")
+	assert.Contains(t, strings.Join(report.Results[7].Reasons, ";"), "exception expired on 2020-01-01")
+
+	report = policy.Evaluate(models.KissBOM{Packages: kissbom.Packages[:2]}, DecisionReview, time.Now())
+	assert.True(t, report.Passed)
+
+	data, err := report.JSON()
+	assert.NoError(t, err)
+	assert.True(t, json.Valid(data))
+}
+
+func TestPolicyReport_JUnit(t *testing.T) {
+	report := PolicyReport{
+		Source: "test.json",
+		FailOn: DecisionDeny,
+		Results: []PolicyResult{
+			{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Decision: DecisionAllow},
+			{Purl: "pkg:npm/lgpl@1.0.0", License: "LGPL-2.1-only", Decision: DecisionReview, Reasons: []string{"LGPL-2.1-only: review"}},
+			{Purl: "pkg:npm/agpl@1.0.0", License: "AGPL-3.0-only", Category: models.CategoryNetworkCopyleft, Decision: DecisionDeny, Reasons: []string{"AGPL-3.0-only: deny"}},
+		},
+	}
+
+	data, err := report.JUnit()
+	assert.NoError(t, err)
+	xml := string(data)
+	assert.Contains(t, xml, `<testsuites name="kissbom" tests="3" failures="1">`)
+	assert.Contains(t, xml, `<failure message="deny: AGPL-3.0-only: deny" type="deny">license: AGPL-3.0-only (network-copyleft)</failure>`)
+	assert.Contains(t, xml, `<system-out>review: LGPL-2.1-only: review</system-out>`)
+}
+
+func TestLoadPolicy_Errors(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+
+	_, err := LoadPolicy(afs, "missing.yaml")
+	assert.Error(t, err)
+
+	assert.NoError(t, afs.WriteFile("bad.yaml", []byte("default: maybe"), 0644))
+	_, err = LoadPolicy(afs, "bad.yaml")
+	assert.Error(t, err)
+
+	assert.NoError(t, afs.WriteFile("bad.yaml", []byte("exceptions:\n  - expires: tomorrow"), 0644))
+	_, err = LoadPolicy(afs, "bad.yaml")
+	assert.Error(t, err)
+
+	assert.NoError(t, afs.WriteFile("bad.yaml", []byte("allow: {"), 0644))
+	_, err = LoadPolicy(afs, "bad.yaml")
+	assert.Error(t, err)
+}
+
+func TestConverter_CheckPolicy(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, converter.Afs.WriteFile("policy.yaml", []byte(testPolicy), 0644))
+	assert.NoError(t, converter.Afs.WriteFile("a.cdx.json", []byte(mergeCycloneDX), 0644))
+
+	report, err := converter.CheckPolicy("a.cdx.json", "policy.yaml", DecisionDeny)
+	assert.NoError(t, err)
+	assert.Equal(t, "a.cdx.json", report.Source)
+	assert.Len(t, report.Results, 2)
+	assert.False(t, report.Passed)
+
+	_, err = converter.CheckPolicy("a.cdx.json", "policy.yaml", DecisionAllow)
+	assert.Error(t, err)
+}
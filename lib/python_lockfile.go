@@ -0,0 +1,211 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// pythonRequirementName matches the distribution name at the start of a PEP 508
+// requirement (ex: requests in "requests[socks]>=2.31").
+var pythonRequirementName = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// pythonManifest contains the parts of a pyproject.toml or Pipfile file used to read
+// Python lockfiles: the project, and the names of its direct dependencies.
+type pythonManifest struct {
+	Name            string
+	Version         string
+	Dependencies    []string // Normalized names of the regular dependencies.
+	DevDependencies []string // Normalized names of the development dependencies.
+}
+
+// readPythonLockfile parses a Python lockfile or a pip requirements file, along with
+// the pyproject.toml file next to it, or the Pipfile for Pipfile.lock.
+func readPythonLockfile(afs *afero.Afero, filename string, source []byte) (lock *lockfile, err error) {
+	manifestFilename := filepath.Join(filepath.Dir(filename), "pyproject.toml")
+	if filepath.Base(filename) == PipenvLockfile {
+		manifestFilename = filepath.Join(filepath.Dir(filename), "Pipfile")
+	}
+	manifest := pythonManifest{}
+	if data, err := afs.ReadFile(manifestFilename); err == nil {
+		if manifest, err = parsePythonManifest(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", manifestFilename, err)
+		}
+	}
+
+	switch filepath.Base(filename) {
+	case PoetryLockfile:
+		lock, err = parsePoetryLock(source, manifest)
+	case PipenvLockfile:
+		lock, err = parsePipfileLock(source, manifest)
+	case UvLockfile:
+		lock, err = parseUvLock(source)
+	default:
+		lock = newLockfile(packageurl.TypePyPi)
+		err = parseRequirements(afs, filename, source, lock, map[string]bool{})
+		lock.addPythonManifest(manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if lock.Name == "" {
+		lock.Name, lock.Version = manifest.Name, manifest.Version
+	}
+	return
+}
+
+// pyproject contains the parts of a pyproject.toml file, or of a Pipfile, used to read
+// the project and its direct dependencies.
+type pyproject struct {
+	Project struct {
+		Name                 string              `toml:"name"`
+		Version              string              `toml:"version"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	DependencyGroups map[string][]any `toml:"dependency-groups"` // Requirements, or tables including other groups.
+	Tool             struct {
+		Poetry struct {
+			Name            string         `toml:"name"`
+			Version         string         `toml:"version"`
+			Dependencies    map[string]any `toml:"dependencies"`
+			DevDependencies map[string]any `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+		Uv struct {
+			DevDependencies []string `toml:"dev-dependencies"`
+		} `toml:"uv"`
+	} `toml:"tool"`
+	Packages    map[string]any `toml:"packages"`     // Regular dependencies of a Pipfile.
+	DevPackages map[string]any `toml:"dev-packages"` // Development dependencies of a Pipfile.
+}
+
+// parsePythonManifest reads the project and its direct dependencies from a
+// pyproject.toml file, in the PEP 621 [project] table, PEP 735 dependency groups and
+// the tool tables of Poetry and uv, or from a Pipfile. Dependency groups, Poetry
+// groups other than main and the dev-packages of a Pipfile are development
+// dependencies.
+func parsePythonManifest(data []byte) (manifest pythonManifest, err error) {
+	var document pyproject
+	if err = toml.Unmarshal(data, &document); err != nil {
+		return
+	}
+
+	project, poetry := document.Project, document.Tool.Poetry
+	manifest.Name = models.FirstNonEmpty(project.Name, poetry.Name)
+	manifest.Version = models.FirstNonEmpty(project.Version, poetry.Version)
+
+	regular := project.Dependencies
+	for _, extra := range project.OptionalDependencies {
+		regular = append(regular, extra...)
+	}
+	dev := document.Tool.Uv.DevDependencies
+	for _, group := range document.DependencyGroups {
+		for _, requirement := range group {
+			if requirement, ok := requirement.(string); ok {
+				dev = append(dev, requirement)
+			}
+		}
+	}
+
+	for name := range poetry.Dependencies {
+		regular = append(regular, name)
+	}
+	for name := range poetry.DevDependencies {
+		dev = append(dev, name)
+	}
+	for group, table := range poetry.Group {
+		for name := range table.Dependencies {
+			if group == "main" {
+				regular = append(regular, name)
+			} else {
+				dev = append(dev, name)
+			}
+		}
+	}
+
+	for name := range document.Packages {
+		regular = append(regular, name)
+	}
+	for name := range document.DevPackages {
+		dev = append(dev, name)
+	}
+
+	manifest.Dependencies, manifest.DevDependencies = pythonRequirementNames(regular), pythonRequirementNames(dev)
+	return
+}
+
+// pythonRequirementNames returns the normalized names of PEP 508 requirements, or of
+// plain names, leaving out python itself.
+func pythonRequirementNames(requirements []string) (names []string) {
+	for _, requirement := range requirements {
+		if match := pythonRequirementName.FindStringSubmatch(requirement); match != nil {
+			if name := pypiName(match[1]); name != "python" {
+				names = appendUnique(names, name)
+			}
+		}
+	}
+	return
+}
+
+// addPythonManifest records the direct dependencies of a Python manifest, for
+// lockfiles keyed by normalized package name.
+func (l *lockfile) addPythonManifest(manifest pythonManifest) {
+	for _, name := range manifest.Dependencies {
+		l.addDirect(name, false)
+	}
+	for _, name := range manifest.DevDependencies {
+		l.addDirect(name, true)
+	}
+}
+
+// pypiName normalizes a Python package name following PEP 503: lowercase, with runs
+// of separators replaced by "-".
+func pypiName(name string) string {
+	return pypiSeparators.ReplaceAllString(strings.ToLower(strings.TrimSpace(name)), "-")
+}
+
+// pypiPurl returns the pkg:pypi PURL of a package name, which is normalized, and version.
+func pypiPurl(name string, version string) string {
+	return packageurl.NewPackageURL(packageurl.TypePyPi, "", pypiName(name), version, nil, "").ToString()
+}
+
+// pythonHashes converts hashes written as algorithm:hexadecimal (ex: sha256:8f4e...)
+// to hashes keyed by CycloneDX algorithm name. As these hashes cover the files of a
+// release, which has one per wheel and source distribution, an algorithm with more
+// than one distinct value is left out. Returns nil if there are none.
+func pythonHashes(values []string) map[string]string {
+	hashes, ambiguous := map[string]string{}, map[string]bool{}
+	for _, value := range values {
+		algorithm, digest, ok := strings.Cut(strings.TrimSpace(value), ":")
+		if !ok || digest == "" {
+			continue
+		}
+		algorithm, digest = models.SPDXChecksumAlgorithm(strings.ToUpper(algorithm)), strings.ToLower(digest)
+		if existing, ok := hashes[algorithm]; ok && existing != digest {
+			ambiguous[algorithm] = true
+		}
+		hashes[algorithm] = digest
+	}
+	for algorithm := range ambiguous {
+		delete(hashes, algorithm)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes
+}
+
+// isPythonSdist returns true if the file is a source distribution of a Python package.
+func isPythonSdist(filename string) bool {
+	return strings.HasSuffix(filename, ".tar.gz") || strings.HasSuffix(filename, ".zip")
+}
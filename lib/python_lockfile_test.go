@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+func TestParsePythonManifest(t *testing.T) {
+	manifest, err := parsePythonManifest([]byte(`[project]
+name = "api"
+version = "0.1.0"
+dependencies = ["Flask>=3.0", "requests[socks]; python_version >= '3.8'"]
+
+[project.optional-dependencies]
+cli = ["click"]
+
+[dependency-groups]
+test = ["pytest>=8"]
+
+[tool.uv]
+dev-dependencies = ["ruff"]
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "api", manifest.Name)
+	assert.Equal(t, "0.1.0", manifest.Version)
+	assert.ElementsMatch(t, []string{"flask", "requests", "click"}, manifest.Dependencies)
+	assert.ElementsMatch(t, []string{"ruff", "pytest"}, manifest.DevDependencies)
+
+	manifest, err = parsePythonManifest([]byte(`[tool.poetry]
+name = "worker"
+version = "1.2.0"
+
+[tool.poetry.dependencies]
+python = "^3.12"
+Typing_Extensions = "^4.10"
+
+[tool.poetry.dev-dependencies]
+black = "*"
+
+[tool.poetry.group.test.dependencies]
+pytest = "^8.1"
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "worker", manifest.Name)
+	assert.Equal(t, []string{"typing-extensions"}, manifest.Dependencies)
+	assert.ElementsMatch(t, []string{"black", "pytest"}, manifest.DevDependencies)
+
+	manifest, err = parsePythonManifest([]byte("[packages]\nrequests = \"*\"\n\n[dev-packages]\npytest = \"*\"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"requests"}, manifest.Dependencies)
+	assert.Equal(t, []string{"pytest"}, manifest.DevDependencies)
+}
+
+func TestPypiPurl(t *testing.T) {
+	assert.Equal(t, "pkg:pypi/typing-extensions@4.10.0", pypiPurl("Typing_Extensions", "4.10.0"))
+	assert.Equal(t, "pkg:pypi/zope-interface@6.2", pypiPurl("zope.interface", "6.2"))
+	assert.Equal(t, "pkg:pypi/api", pypiPurl("api", ""))
+}
+
+func TestPythonHashes(t *testing.T) {
+	assert.Equal(t, map[string]string{"SHA-256": "abcd"}, pythonHashes([]string{"sha256:ABCD", "sha256:abcd"}))
+	assert.Equal(t, map[string]string{"MD5": "ef"}, pythonHashes([]string{"sha256:ab", "sha256:cd", "md5:ef"}))
+	assert.Nil(t, pythonHashes([]string{"", "sha256:"}))
+}
+
+func TestReadLockfile_Python(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("api/poetry.lock", []byte(testPoetryLock), 0644))
+	assert.NoError(t, afs.WriteFile("api/pyproject.toml", []byte(`[tool.poetry]
+name = "api"
+version = "0.1.0"
+
+[tool.poetry.dependencies]
+python = "^3.12"
+flask = "^3.0"
+lib = {path = "packages/lib", develop = true}
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^8.1"
+`), 0644))
+
+	kissbom, err := ReadLockfile(afs, "api/poetry.lock", models.Filter{ExcludeScopes: []string{ScopeDev}})
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg:pypi/api@0.1.0", kissbom.Metadata.Subject)
+	assert.Equal(t, models.DocumentDigest([]byte(testPoetryLock)), kissbom.Metadata.Digest)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:pypi/flask@3.0.2", Hashes: map[string]string{"SHA-256": "822c03f4b799204250a7ee84b1eddc8b4c5d23b4a3c8bd5d7e4feb1b5a6e1fdd"}},
+		{Purl: "pkg:pypi/werkzeug@3.0.1"},
+	}, kissbom.Packages)
+	assert.Equal(t, map[string][]string{
+		"pkg:pypi/api@0.1.0":   {"pkg:pypi/flask@3.0.2"},
+		"pkg:pypi/flask@3.0.2": {"pkg:pypi/werkzeug@3.0.1"},
+	}, kissbom.Dependencies)
+}
+
+func TestReadLockfile_PythonErrors(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("uv.lock", []byte("version = [\n"), 0644))
+	_, err := ReadLockfile(afs, "uv.lock")
+	assert.ErrorContains(t, err, "uv.lock: toml: line 1")
+
+	assert.NoError(t, afs.WriteFile("pyproject.toml", []byte("[project\n"), 0644))
+	_, err = ReadLockfile(afs, "uv.lock")
+	assert.EqualError(t, err, `pyproject.toml: toml: line 2: expected '.' or ']' to end table name, but got '\n' instead`)
+}
+
+func TestConvert_Requirements(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter.OutputFormat = models.OptionJSON
+	assert.NoError(t, converter.Afs.WriteFile("api/requirements.txt", []byte(testRequirements), 0644))
+	assert.NoError(t, converter.Afs.WriteFile("api/requirements-base.txt", []byte("typing-extensions==4.10.0\n"), 0644))
+
+	assert.NoError(t, converter.Convert("api/requirements.txt"))
+	assert.Equal(t, "api/requirements.txt.json", converter.OutputFileName)
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+	assert.Len(t, kissbom.Packages, 3)
+	assert.Equal(t, "pkg:pypi/flask@3.0.2", kissbom.Packages[0].Purl)
+}
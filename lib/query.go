@@ -0,0 +1,335 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// QueryFields contains the fields that may be referenced in a query expression.
+var QueryFields = []string{"purl", "license", "category", "copyright", "notes", "type", "namespace", "name", "version", "subpath", "depth"}
+
+// Query is a compiled expression that selects packages from a KissBOM.
+//
+// Expressions compare package fields with double quoted strings using == (equals),
+// != (not equals), ~ (matches regular expression) and !~ (does not match regular
+// expression), and combine comparisons with &&, ||, ! and parentheses. For example:
+//
+//	type == "npm" && license ~ "GPL" && copyright == ""
+type Query struct {
+	Expression string // The source expression the query was compiled from.
+	root       queryNode
+}
+
+// ParseQuery compiles the provided expression into a Query.
+func ParseQuery(expression string) (*Query, error) {
+	tokens, err := tokenizeQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &queryParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %v", parser.peek().value, parser.peek().pos)
+	}
+	return &Query{Expression: expression, root: root}, nil
+}
+
+// Match returns true if the package satisfies the query.
+func (q *Query) Match(p models.Package) bool {
+	return q.root.eval(queryValues(p))
+}
+
+// Apply returns a copy of the KissBOM containing only the packages that satisfy the query.
+func (q *Query) Apply(kissbom models.KissBOM) models.KissBOM {
+	packages := []models.Package{}
+	for _, p := range kissbom.Packages {
+		if q.Match(p) {
+			packages = append(packages, p)
+		}
+	}
+	kissbom.Packages = packages
+	return kissbom
+}
+
+// Select reads the provided CycloneDX or KissBOM file and returns a KissBOM containing
+// only the packages that satisfy the query expression.
+func (c *Converter) Select(filename string, expression string) (kissbom models.KissBOM, err error) {
+	query, err := ParseQuery(expression)
+	if err != nil {
+		return
+	}
+
+	kissbom, err = c.load(filename)
+	if err != nil {
+		return
+	}
+	c.Duplicates = kissbom.Deduplicate()
+	c.applyDependencies(&kissbom)
+
+	kissbom = query.Apply(kissbom)
+	kissbom.PruneDependencies()
+	return kissbom, nil
+}
+
+// queryValues returns the values of all query fields for the provided package,
+// including the components of its parsed PURL.
+func queryValues(p models.Package) map[string]string {
+	values := map[string]string{
+		"purl":      p.Purl,
+		"license":   p.License,
+		"category":  models.LicenseCategory(p.License),
+		"copyright": p.Copyright,
+		"notes":     p.Notes,
+	}
+	if purl, err := packageurl.FromString(p.Purl); err == nil {
+		values["type"] = purl.Type
+		values["namespace"] = purl.Namespace
+		values["name"] = purl.Name
+		values["version"] = purl.Version
+		values["subpath"] = purl.Subpath
+	}
+	if p.Depth > 0 {
+		values["depth"] = strconv.Itoa(p.Depth)
+	}
+	return values
+}
+
+// queryNode is a node of a compiled query expression.
+type queryNode interface {
+	eval(values map[string]string) bool
+}
+
+type andNode struct{ left, right queryNode }
+
+func (n andNode) eval(values map[string]string) bool {
+	return n.left.eval(values) && n.right.eval(values)
+}
+
+type orNode struct{ left, right queryNode }
+
+func (n orNode) eval(values map[string]string) bool {
+	return n.left.eval(values) || n.right.eval(values)
+}
+
+type notNode struct{ operand queryNode }
+
+func (n notNode) eval(values map[string]string) bool {
+	return !n.operand.eval(values)
+}
+
+type compareNode struct {
+	field    string
+	operator string
+	value    string
+	pattern  *regexp.Regexp
+}
+
+func (n compareNode) eval(values map[string]string) bool {
+	actual := values[n.field]
+	switch n.operator {
+	case "==":
+		return actual == n.value
+	case "!=":
+		return actual != n.value
+	case "~":
+		return n.pattern.MatchString(actual)
+	case "!~":
+		return !n.pattern.MatchString(actual)
+	}
+	return false
+}
+
+// Enumeration of the kinds of tokens in a query expression.
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+type queryToken struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// tokenizeQuery splits a query expression into tokens.
+func tokenizeQuery(expression string) (tokens []queryToken, err error) {
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokenRParen, ")", i})
+			i++
+		case r == '&' && next == '&':
+			tokens = append(tokens, queryToken{tokenAnd, "&&", i})
+			i += 2
+		case r == '|' && next == '|':
+			tokens = append(tokens, queryToken{tokenOr, "||", i})
+			i += 2
+		case (r == '=' || r == '!') && next == '=', r == '!' && next == '~':
+			tokens = append(tokens, queryToken{tokenOperator, string([]rune{r, next}), i})
+			i += 2
+		case r == '~':
+			tokens = append(tokens, queryToken{tokenOperator, "~", i})
+			i++
+		case r == '!':
+			tokens = append(tokens, queryToken{tokenNot, "!", i})
+			i++
+		case r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %v", start)
+			}
+			tokens = append(tokens, queryToken{tokenString, sb.String(), start})
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, queryToken{tokenIdent, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %v", r, i)
+		}
+	}
+	return append(tokens, queryToken{tokenEOF, "end of expression", len(runes)}), nil
+}
+
+// queryParser is a recursive descent parser for query expressions.
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// parseOr parses: and ( "||" and )*
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peek().kind == tokenOr {
+		p.next()
+		var right queryNode
+		right, err = p.parseAnd()
+		left = orNode{left, right}
+	}
+	return left, err
+}
+
+// parseAnd parses: unary ( "&&" unary )*
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.peek().kind == tokenAnd {
+		p.next()
+		var right queryNode
+		right, err = p.parseUnary()
+		left = andNode{left, right}
+	}
+	return left, err
+}
+
+// parseUnary parses: "!" unary | "(" or ")" | comparison
+func (p *queryParser) parseUnary() (queryNode, error) {
+	switch p.peek().kind {
+	case tokenNot:
+		p.next()
+		operand, err := p.parseUnary()
+		return notNode{operand}, err
+	case tokenLParen:
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, fmt.Errorf("expected \")\" at position %v, found %q", t.pos, t.value)
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: field operator string
+func (p *queryParser) parseComparison() (queryNode, error) {
+	field := p.next()
+	if field.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a field at position %v, found %q", field.pos, field.value)
+	}
+	name := strings.ToLower(field.value)
+	if !containsString(QueryFields, name) {
+		return nil, fmt.Errorf("unknown field %q at position %v, valid fields are %s", field.value, field.pos, QueryFields)
+	}
+
+	operator := p.next()
+	if operator.kind != tokenOperator {
+		return nil, fmt.Errorf("expected an operator at position %v, found %q", operator.pos, operator.value)
+	}
+
+	value := p.next()
+	if value.kind != tokenString {
+		return nil, fmt.Errorf("expected a quoted string at position %v, found %q", value.pos, value.value)
+	}
+
+	node := compareNode{field: name, operator: operator.value, value: value.value}
+	if operator.value == "~" || operator.value == "!~" {
+		pattern, err := regexp.Compile(value.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %v: %w", value.pos, err)
+		}
+		node.pattern = pattern
+	}
+	return node, nil
+}
+
+// containsString returns true if values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+func TestParseQuery_Match(t *testing.T) {
+	gpl := models.Package{Purl: "pkg:npm/%40scope/left-pad@1.3.0", License: "GPL-3.0-only"}
+	mit := models.Package{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Copyright: "Copyright JS Foundation", Depth: 1}
+	pypi := models.Package{Purl: "pkg:pypi/requests@2.26.0", License: "LGPL-2.1"}
+
+	tests := []struct {
+		expression string
+		expected   []bool
+	}{
+		{`type == "npm" && license ~ "GPL" && copyright == ""`, []bool{true, false, false}},
+		{`license ~ "GPL"`, []bool{true, false, true}},
+		{`license !~ "^(L)?GPL"`, []bool{false, true, false}},
+		{`!(type == "npm")`, []bool{false, false, true}},
+		{`namespace == "@scope" || name == "requests"`, []bool{true, false, true}},
+		{`version != "4.17.21" && (type == "pypi" || license == "MIT")`, []bool{false, false, true}},
+		{`purl == "pkg:npm/lodash@4.17.21"`, []bool{false, true, false}},
+		{`category ~ "copyleft"`, []bool{true, false, true}},
+		{`NOTES == "say \"hi\""`, []bool{false, false, false}},
+		{`depth == "1"`, []bool{false, true, false}},
+		{`depth == ""`, []bool{true, false, true}},
+	}
+
+	for _, test := range tests {
+		query, err := ParseQuery(test.expression)
+		assert.NoError(t, err, test.expression)
+		assert.Equal(t, test.expected, []bool{query.Match(gpl), query.Match(mit), query.Match(pypi)}, test.expression)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	expressions := []string{
+		``,
+		`license`,
+		`license ==`,
+		`license == MIT`,
+		`author == "me"`,
+		`license == "MIT" &&`,
+		`(license == "MIT"`,
+		`license == "MIT")`,
+		`license == "MIT`,
+		`license ~ "("`,
+		`license = "MIT"`,
+	}
+	for _, expression := range expressions {
+		_, err := ParseQuery(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestConverter_Select(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, converter.Afs.WriteFile("a.cdx.json", []byte(mergeCycloneDX), 0644))
+
+	kissbom, err := converter.Select("a.cdx.json", `license == "MIT"`)
+	assert.NoError(t, err)
+	assert.Len(t, kissbom.Packages, 1)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", kissbom.Packages[0].Purl)
+
+	_, err = converter.Select("a.cdx.json", `license ==`)
+	assert.Error(t, err)
+
+	_, err = converter.Select("missing.json", `license == "MIT"`)
+	assert.Error(t, err)
+}
+
+func TestTransform_Query(t *testing.T) {
+	query, err := ParseQuery(`name != "express"`)
+	assert.NoError(t, err)
+
+	converter := NewConverter()
+	converter.Query = query
+
+	kissbom, err := converter.transform([]byte(mergeCycloneDX))
+	assert.NoError(t, err)
+	assert.Len(t, kissbom.Packages, 1)
+}
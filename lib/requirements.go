@@ -0,0 +1,123 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// pipRequirement matches a requirement pinned to an exact version, with optional extras
+// (ex: requests[socks]==2.31.0 or pip===24.0).
+var pipRequirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*===?\s*([^\s;,*]+)\s*(?:;.*)?$`)
+
+// isRequirementsFile returns true if the filename is a pip requirements file, such as
+// requirements.txt or requirements-dev.txt.
+func isRequirementsFile(filename string) bool {
+	matched, _ := filepath.Match("requirements*.txt", filepath.Base(filename))
+	return matched
+}
+
+// parseRequirements reads the pinned requirements of a pip requirements file, as
+// written by pip freeze or pip-compile, into the lockfile. Requirements pinned with ==
+// or === are direct dependencies of the project, keyed by normalized name, and their
+// --hash options provide hashes. Files included with -r are read relative to the file.
+// Requirements that are not pinned, editable installs, URLs and other options are
+// skipped, as their version cannot be known without installing them.
+func parseRequirements(afs *afero.Afero, filename string, source []byte, lock *lockfile, visited map[string]bool) error {
+	visited[filepath.Clean(filename)] = true
+	for _, r := range requirementLines(source) {
+		number, line := r.number, r.text
+		if include, ok := requirementOption(line, "-r", "--requirement"); ok {
+			included := filepath.Join(filepath.Dir(filename), include)
+			if visited[filepath.Clean(included)] {
+				continue
+			}
+			data, err := afs.ReadFile(included)
+			if err != nil {
+				return fmt.Errorf("line %v: %w", number, err)
+			}
+			if err = parseRequirements(afs, included, data, lock, visited); err != nil {
+				return fmt.Errorf("%s: %w", included, err)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		requirement, hashes := line, []string{}
+		if i := strings.Index(line, " --"); i >= 0 {
+			requirement = strings.TrimSpace(line[:i])
+			fields := strings.Fields(strings.ReplaceAll(line[i:], "--hash ", "--hash="))
+			for _, field := range fields {
+				if hash, ok := strings.CutPrefix(field, "--hash="); ok {
+					hashes = append(hashes, hash)
+				}
+			}
+		}
+		match := pipRequirement.FindStringSubmatch(requirement)
+		if match == nil {
+			continue
+		}
+
+		name := pypiName(match[1])
+		lock.Packages[name] = &lockPackage{Name: name, Version: match[2], Hashes: pythonHashes(hashes)}
+		lock.addDirect(name, false)
+	}
+	return nil
+}
+
+// requirementLine is a logical line of a requirements file.
+type requirementLine struct {
+	number int    // Number of the first physical line.
+	text   string // Text of the line, with continuations joined and comments removed.
+}
+
+// requirementLines returns the non-empty lines of a requirements file, with comments
+// removed and continuation lines joined to the line they continue.
+func requirementLines(source []byte) (lines []requirementLine) {
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	number, current := 0, requirementLine{}
+	for scanner.Scan() {
+		number++
+		if current.text == "" {
+			current.number = number
+		}
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			line = ""
+		} else if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		if continued, ok := strings.CutSuffix(strings.TrimRight(line, " \t"), `\`); ok {
+			current.text += continued + " "
+			continue
+		}
+		current.text = strings.Join(strings.Fields(current.text+line), " ")
+		if current.text != "" {
+			lines = append(lines, current)
+		}
+		current = requirementLine{}
+	}
+	if current.text = strings.Join(strings.Fields(current.text), " "); current.text != "" {
+		lines = append(lines, current)
+	}
+	return
+}
+
+// requirementOption returns the value of an option of a requirements file line, in
+// its short (-r file or -rfile) or long (--requirement file or --requirement=file) form.
+func requirementOption(line string, short string, long string) (string, bool) {
+	for _, prefix := range []string{long + "=", long + " ", short + " ", short} {
+		if value, ok := strings.CutPrefix(line, prefix); ok && (prefix != short || !strings.HasPrefix(value, "-")) {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
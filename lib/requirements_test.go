@@ -0,0 +1,66 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRequirements = `# This file is autogenerated by pip-compile with Python 3.12
+-r requirements-base.txt
+--index-url https://pypi.org/simple
+
+Flask==3.0.2 \
+    --hash=sha256:3232e0e9c850d781933cf0207523d1ece087eb8d87b23777ae38456e2fbe7c6e
+requests[socks] == 2.31.0 ; python_version >= "3.8" \
+    --hash=sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f \
+    --hash=sha256:942c5a758f98d790eaed1a29cb6eefc7ffb0d1cf7af05c3d2791656dbd6ad1e1
+urllib3>=2.0  # not pinned
+-e ./packages/lib
+pip @ https://example.com/pip-24.0.tar.gz
+`
+
+func TestParseRequirements(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("api/requirements-base.txt", []byte("Typing_Extensions===4.10.0\n-r requirements.txt\n"), 0644))
+
+	lock := newLockfile(packageurl.TypePyPi)
+	assert.NoError(t, parseRequirements(afs, "api/requirements.txt", []byte(testRequirements), lock, map[string]bool{}))
+
+	assert.Len(t, lock.Packages, 3)
+	assert.Equal(t, &lockPackage{Name: "typing-extensions", Version: "4.10.0"}, lock.Packages["typing-extensions"])
+	assert.Equal(t, &lockPackage{
+		Name:    "flask",
+		Version: "3.0.2",
+		Hashes:  map[string]string{"SHA-256": "3232e0e9c850d781933cf0207523d1ece087eb8d87b23777ae38456e2fbe7c6e"},
+	}, lock.Packages["flask"])
+	assert.Equal(t, &lockPackage{Name: "requests", Version: "2.31.0"}, lock.Packages["requests"])
+	assert.Equal(t, []string{"typing-extensions", "flask", "requests"}, lock.Dependencies[""])
+}
+
+func TestParseRequirements_MissingInclude(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	err := parseRequirements(afs, "requirements.txt", []byte("\n--requirement=base.txt\n"), newLockfile(packageurl.TypePyPi), map[string]bool{})
+	assert.ErrorContains(t, err, "line 2: ")
+}
+
+func TestRequirementLines(t *testing.T) {
+	assert.Equal(t, []requirementLine{
+		{1, "a==1 --hash=sha256:ab"},
+		{4, "b==2"},
+	}, requirementLines([]byte("a==1 \\\n  --hash=sha256:ab # comment\n# comment\nb==2")))
+}
+
+func TestRequirementOption(t *testing.T) {
+	for _, line := range []string{"-r base.txt", "-rbase.txt", "--requirement base.txt", "--requirement=base.txt"} {
+		value, ok := requirementOption(line, "-r", "--requirement")
+		assert.True(t, ok, line)
+		assert.Equal(t, "base.txt", value, line)
+	}
+	_, ok := requirementOption("--require-hashes", "-r", "--requirement")
+	assert.False(t, ok)
+	_, ok = requirementOption("requests==2.31.0", "-r", "--requirement")
+	assert.False(t, ok)
+}
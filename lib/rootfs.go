@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// errNoPackageDatabase is returned when a root file system has no package database.
+var errNoPackageDatabase = errors.New("no dpkg, apk or rpm package database found")
+
+// osReleaseFiles identify the distribution of a root file system, relative to its root:
+// the os-release file, and the one it usually links to.
+var osReleaseFiles = []string{"etc/os-release", "usr/lib/os-release"}
+
+// ReadRootFS builds a KissBOM of the OS packages installed in an unpacked root file
+// system, such as the one of a container image, read from the databases of dpkg, apk and
+// rpm. Packages have a pkg:deb, pkg:apk or pkg:rpm PURL, namespaced by the ID of the
+// distribution read from its os-release file and qualified by the distribution and the
+// architecture (ex: pkg:deb/debian/curl@7.88.1-10?arch=amd64&distro=debian-12), and the
+// dependencies between them are recorded. The distribution is the name and version of
+// the KissBOM. Debian packages get their license and copyright statements from their
+// copyright files, and Alpine and RPM packages their license from the database.
+//
+// Parameters:
+//   - afs: The file system to read from.
+//   - root: The directory of the root file system.
+//   - filters: Optional filters that determine which packages are kept.
+func ReadRootFS(afs *afero.Afero, root string, filters ...models.Filter) (kissbom models.KissBOM, err error) {
+	release := readOSRelease(afs, root)
+	kissbom.Metadata = &models.Metadata{Name: models.FirstNonEmpty(release["NAME"], release["ID"]), Version: release["VERSION_ID"]}
+	distro := release["ID"]
+	if version := models.FirstNonEmpty(release["VERSION_ID"], release["VERSION_CODENAME"]); distro != "" && version != "" {
+		distro += "-" + version
+	}
+
+	found := false
+	for _, read := range []func(*afero.Afero, string) (*lockfile, error){readDpkg, readApk, readRPM} {
+		lock, err := read(afs, root)
+		if err != nil {
+			return kissbom, err
+		}
+		if lock == nil {
+			continue
+		}
+		found = true
+		lock.Namespace = release["ID"]
+		for _, p := range lock.Packages {
+			if distro != "" && p.Qualifiers == nil {
+				p.Qualifiers = map[string]string{"distro": distro}
+			} else if distro != "" {
+				p.Qualifiers["distro"] = distro
+			}
+		}
+		packages := lock.kissBOM(filters)
+		kissbom.Packages = append(kissbom.Packages, packages.Packages...)
+		kissbom.Dependencies = models.MergeDependencies(kissbom.Dependencies, packages.Dependencies)
+	}
+	if !found {
+		err = fmt.Errorf("%s: %w", root, errNoPackageDatabase)
+	}
+	return
+}
+
+// ScanRootFS converts the OS packages installed in an unpacked root file system, read
+// by ReadRootFS, to a KissBOM saved under the name of its directory.
+func (c *Converter) ScanRootFS(root string) error {
+	log.Printf("scanning: %v", root)
+
+	kissbom, err := ReadRootFS(c.Afs, root, c.Filter)
+	if err != nil {
+		return err
+	}
+	log.Printf("found %v packages", len(kissbom.Packages))
+	kissbom = c.process(kissbom)
+
+	name := filepath.Base(filepath.Clean(root))
+	if name == "." || name == string(filepath.Separator) {
+		name = "rootfs"
+	}
+	c.OutputFileName = path.Join(c.OutputFolder, name)
+	return c.writeToFile(kissbom)
+}
+
+// readOSRelease reads the variables of the os-release file of a root file system.
+// Returns an empty map if there is none.
+func readOSRelease(afs *afero.Afero, root string) map[string]string {
+	release := map[string]string{}
+	for _, filename := range osReleaseFiles {
+		data, err := afs.ReadFile(filepath.Join(root, filename))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok || strings.HasPrefix(key, "#") {
+				continue
+			}
+			if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+				value = unquoted
+			} else {
+				value = strings.Trim(value, `'"`)
+			}
+			release[key] = value
+		}
+		break
+	}
+	return release
+}
@@ -0,0 +1,91 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const testOSRelease = `PRETTY_NAME="Debian GNU/Linux 12 (bookworm)"
+NAME="Debian GNU/Linux"
+VERSION_ID="12"
+VERSION="12 (bookworm)"
+VERSION_CODENAME=bookworm
+ID=debian
+HOME_URL="https://www.debian.org/"
+`
+
+func TestReadRootFS(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("rootfs/etc/os-release", []byte(testOSRelease), 0644))
+	assert.NoError(t, afs.WriteFile("rootfs/var/lib/dpkg/status", []byte(testDpkgStatus), 0644))
+	assert.NoError(t, afs.WriteFile("rootfs/usr/share/doc/curl/copyright", []byte(testDpkgCopyright), 0644))
+
+	kissbom, err := ReadRootFS(afs, "rootfs", models.Filter{Names: []string{"curl", "lib*", "mawk"}})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Metadata{Name: "Debian GNU/Linux", Version: "12"}, kissbom.Metadata)
+	assert.Equal(t, []models.Package{
+		{
+			Purl:      "pkg:deb/debian/curl@7.88.1-10%2Bdeb12u5?arch=amd64&distro=debian-12",
+			License:   "curl",
+			Copyright: "1996-2023, Daniel Stenberg <daniel@haxx.se>; 2001-2023, Linus Nielsen Feltzing <linus@haxx.se>; 1995-1999, Kungliga Tekniska Högskolan",
+		},
+		{Purl: "pkg:deb/debian/libc6@2.36-9%2Bdeb12u4?arch=amd64&distro=debian-12"},
+		{Purl: "pkg:deb/debian/libgcc-s1@12.2.0-14?arch=amd64&distro=debian-12"},
+		{Purl: "pkg:deb/debian/mawk@1.3.4.20200120-3.1?arch=amd64&distro=debian-12"},
+	}, kissbom.Packages)
+	assert.Equal(t, map[string][]string{
+		"pkg:deb/debian/curl@7.88.1-10%2Bdeb12u5?arch=amd64&distro=debian-12": {
+			"pkg:deb/debian/libc6@2.36-9%2Bdeb12u4?arch=amd64&distro=debian-12",
+			"pkg:deb/debian/mawk@1.3.4.20200120-3.1?arch=amd64&distro=debian-12",
+		},
+		"pkg:deb/debian/libc6@2.36-9%2Bdeb12u4?arch=amd64&distro=debian-12": {"pkg:deb/debian/libgcc-s1@12.2.0-14?arch=amd64&distro=debian-12"},
+		"pkg:deb/debian/libgcc-s1@12.2.0-14?arch=amd64&distro=debian-12":    {"pkg:deb/debian/libc6@2.36-9%2Bdeb12u4?arch=amd64&distro=debian-12"},
+	}, kissbom.Dependencies)
+}
+
+func TestReadRootFS_RPM(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("rootfs/usr/lib/os-release", []byte("NAME=\"Rocky Linux\"\nID=\"rocky\"\nVERSION_ID=\"9.3\"\n"), 0644))
+	assert.NoError(t, afs.WriteFile("rootfs/var/lib/rpm/rpmdb.sqlite", testGunzip(t, testRPMDB), 0644))
+
+	kissbom, err := ReadRootFS(afs, "rootfs")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Metadata{Name: "Rocky Linux", Version: "9.3"}, kissbom.Metadata)
+	assert.Equal(t, []models.Package{
+		{Purl: "pkg:rpm/rocky/basesystem@11-13.el9?arch=noarch&distro=rocky-9.3&epoch=1", License: "Public Domain"},
+		{Purl: "pkg:rpm/rocky/bash@5.1.8-9.el9?arch=x86_64&distro=rocky-9.3", License: "GPLv3+"},
+		{Purl: "pkg:rpm/rocky/glibc@2.34-100.el9?arch=x86_64&distro=rocky-9.3", License: "LGPLv2+ and LGPLv2+ with exceptions and GPLv2+"},
+	}, kissbom.Packages)
+}
+
+func TestReadRootFS_NoDatabase(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	_, err := ReadRootFS(afs, "rootfs")
+	assert.EqualError(t, err, "rootfs: no dpkg, apk or rpm package database found")
+}
+
+func TestConverter_ScanRootFS(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("images/alpine/etc/os-release", []byte("NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.19.1\n"), 0644))
+	assert.NoError(t, afs.WriteFile("images/alpine/lib/apk/db/installed", []byte(testApkInstalled), 0644))
+
+	converter := &Converter{Afs: afs, OutputFolder: "out", OutputFormat: models.OptionMinimal}
+	assert.NoError(t, converter.ScanRootFS("images/alpine/"))
+	assert.Equal(t, "out/alpine.json", converter.OutputFileName)
+
+	data, err := afs.ReadFile("out/alpine.json")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "pkg:apk/alpine/busybox@1.36.1-r15?arch=x86_64\\u0026distro=alpine-3.19.1")
+}
+
+func TestReadOSRelease(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.Empty(t, readOSRelease(afs, "/"))
+
+	assert.NoError(t, afs.WriteFile("/etc/os-release", []byte("# Comment\nNAME='Wolfi'\nID=wolfi\nPRETTY_NAME=\"Say \\\"hi\\\"\"\n"), 0644))
+	assert.Equal(t, map[string]string{"NAME": "Wolfi", "ID": "wolfi", "PRETTY_NAME": `Say "hi"`}, readOSRelease(afs, "/"))
+}
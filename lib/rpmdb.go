@@ -0,0 +1,188 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+)
+
+// rpmDatabases are the locations of the SQLite rpm database, relative to the root file
+// system: the historical one, and the one of distributions that moved it under /usr.
+var rpmDatabases = []string{"var/lib/rpm/rpmdb.sqlite", "usr/lib/sysimage/rpm/rpmdb.sqlite"}
+
+// Enumeration of the tags of an RPM header that are read.
+const (
+	rpmTagName        = 1000
+	rpmTagVersion     = 1001
+	rpmTagRelease     = 1002
+	rpmTagEpoch       = 1003
+	rpmTagLicense     = 1014
+	rpmTagArch        = 1022
+	rpmTagProvideName = 1047
+	rpmTagRequireName = 1049
+)
+
+// Enumeration of the types of the values of an RPM header that are read.
+const (
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeStringArray = 8
+	rpmTypeI18NString  = 9
+)
+
+// errRPMHeader is returned when the header of a package of the rpm database cannot be read.
+var errRPMHeader = errors.New("corrupt RPM header")
+
+// readRPM reads the packages installed by rpm in a root file system, from its SQLite
+// database. Returns nil if there is no such database; the Berkeley DB and NDB formats
+// of older and SUSE distributions are not supported.
+func readRPM(afs *afero.Afero, root string) (*lockfile, error) {
+	for _, database := range rpmDatabases {
+		filename := filepath.Join(root, database)
+		data, err := afs.ReadFile(filename)
+		if err != nil {
+			continue
+		}
+		lock, err := parseRPMDB(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		return lock, nil
+	}
+	return nil, nil
+}
+
+// parseRPMDB parses an SQLite rpm database, where the Packages table holds the header of
+// each installed package. Packages are keyed by name and architecture; their version is
+// the version and release of the package, and the epoch and architecture become
+// qualifiers of their PURL. Requirements are resolved to the installed packages that
+// provide them, ignoring the features of rpm itself and files. The public keys that rpm
+// records as gpg-pubkey packages are left out.
+func parseRPMDB(data []byte) (*lockfile, error) {
+	db, err := openSQLite(data)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.rows("Packages")
+	if err != nil {
+		return nil, err
+	}
+
+	lock := newLockfile(packageurl.TypeRPM)
+	provides := map[string]string{}
+	requires := map[string][]string{}
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		blob, ok := row[1].([]byte)
+		if !ok {
+			continue
+		}
+		header, err := parseRPMHeader(blob)
+		if err != nil {
+			return nil, err
+		}
+
+		name, arch := rpmValue(header, rpmTagName), rpmValue(header, rpmTagArch)
+		if name == "" || name == "gpg-pubkey" {
+			continue
+		}
+		id := name + "." + arch
+		p := &lockPackage{
+			Name:       name,
+			Version:    rpmValue(header, rpmTagVersion) + "-" + rpmValue(header, rpmTagRelease),
+			License:    rpmValue(header, rpmTagLicense),
+			Qualifiers: map[string]string{"arch": arch},
+		}
+		if epoch := rpmValue(header, rpmTagEpoch); epoch != "" {
+			p.Qualifiers["epoch"] = epoch
+		}
+		lock.Packages[id] = p
+		provides[name] = id
+		for _, provided := range header[rpmTagProvideName] {
+			if _, ok := provides[provided]; !ok {
+				provides[provided] = id
+			}
+		}
+		requires[id] = header[rpmTagRequireName]
+	}
+
+	for id, names := range requires {
+		for _, name := range names {
+			if strings.HasPrefix(name, "rpmlib(") || strings.HasPrefix(name, "/") {
+				continue
+			}
+			if dependency, ok := provides[name]; ok && dependency != id {
+				lock.Dependencies[id] = appendUnique(lock.Dependencies[id], dependency)
+			}
+		}
+	}
+	return lock, nil
+}
+
+// parseRPMHeader parses the header of an RPM package as stored in the rpm database: the
+// number of index entries and the size of the data, an entry of 16 bytes for each tag
+// giving the type, offset and count of its value, and the data. Returns the values of
+// the string, string array and integer tags; only the first of the translations of a
+// string is kept.
+func parseRPMHeader(blob []byte) (map[int][]string, error) {
+	if len(blob) < 8 {
+		return nil, errRPMHeader
+	}
+	entries, size := int(binary.BigEndian.Uint32(blob)), int(binary.BigEndian.Uint32(blob[4:]))
+	start := 8 + 16*entries
+	if entries < 0 || size < 0 || start+size > len(blob) {
+		return nil, errRPMHeader
+	}
+	data := blob[start : start+size]
+
+	header := map[int][]string{}
+	for i := 0; i < entries; i++ {
+		entry := blob[8+16*i:]
+		tag := int(binary.BigEndian.Uint32(entry))
+		kind := binary.BigEndian.Uint32(entry[4:])
+		offset, count := int(binary.BigEndian.Uint32(entry[8:])), int(binary.BigEndian.Uint32(entry[12:]))
+		if offset < 0 || offset > len(data) {
+			return nil, errRPMHeader
+		}
+
+		switch kind {
+		case rpmTypeInt32:
+			if count < 0 || offset+4*count > len(data) {
+				return nil, errRPMHeader
+			}
+			for j := 0; j < count; j++ {
+				header[tag] = append(header[tag], strconv.FormatUint(uint64(binary.BigEndian.Uint32(data[offset+4*j:])), 10))
+			}
+		case rpmTypeString, rpmTypeI18NString:
+			count = 1
+			fallthrough
+		case rpmTypeStringArray:
+			for j, rest := 0, data[offset:]; j < count; j++ {
+				value, next, ok := bytes.Cut(rest, []byte{0})
+				if !ok {
+					return nil, errRPMHeader
+				}
+				header[tag] = append(header[tag], string(value))
+				rest = next
+			}
+		}
+	}
+	return header, nil
+}
+
+// rpmValue returns the first value of a tag of an RPM header, or an empty string.
+func rpmValue(header map[int][]string, tag int) string {
+	if values := header[tag]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRPMDB is a gzipped SQLite rpm database, with pages of 512 bytes, holding the
+// headers of glibc, bash, basesystem and a gpg-pubkey.
+const testRPMDB = `
+H4sIAAAAAAACA+1Xy27TQBS9k7gNqAQJaJVWImIWLGySpnGTRq0EEklx04jUCWmKKJvKjqeJVcdOYwfSZQobVnwCK1bli/gCHjsW
+bJAQY3vch1UV2FVqjjwen3vl8X167M1nVd0heNfqdxUH5yACCMFjjAEgSkcMThChgzvFEfwdUci0UNy7+AjoPXpEp//HaCYaS8zM
+oMO4o6gGkZWuN7jVhlRsSrhZLFUl7Eowv0cOcFN60cRyjY6tajWNO+agiytyUypLjVNiXRueI12rNaRKWcZPpW3Mu3cKuCGtSQ1J
+XpU2cV1p7SltYvsaoR6ZTKRSaNuzyt43aCR3bLI/IGYrTKNnbA0peZPanqZMsFAskUyikegtGTwumCNnFgmkvp3HvtQblY1iY9vz
+oLjVrFVketeGJDfTWDUsFZeqtdKxw8KEm0j0y41yzJ/GuDqYjMS9/P8AeoxxJXADRZMoeHdw8eDNPsbVBd3JuQSdr9FR8Xd5bs7n
+6MjdvNuGrrZgMZPLg5jNZoixAtVyvfpqMYUVU8PB9Wvd6WAybJGeo1um7el8FQyXCzuFPPgLeWeeiuYLeQFckrGtTIEX+EJe1R0B
++o6h8WV5a2e9uLkuDMf4Z0C/16UB5Vetbq9PbJtoa7r/xWQLsKDq5oLdAVWxiX1gO6QLca/9PwH6QE+XHKNehIM3GvssdWhdfnH3
+sJMijn5lfILxb4xPMf6TztfpuMX4b6afDTXBPf+lGDQBpN2moFHrwFJGzCzDitcCbmnnjkvbUwcRZlmoKweGpWgV+6XtaOdWOhU5
+urlrhcTBOm+fIw7eZX2X0ecLXJ68wGWX3wm5XPJWmPDzfwRoCY4ue/pjHAeHt1l6UxfEYioUi+lQLJKhWNx3ebvXnu8NVPoDAbta
+PidqSyLkFZLNr+RUYAretEwiwGg9SgtxmhXHw/+w5Cbj39m/1GzIsrshyx6EClNkfO7k2XC6nUURxJxXnMyW+kA19BZ+YnUV3QTT
+UvqtM/3/B4GVe/8ADgAA`
+
+func TestParseRPMDB(t *testing.T) {
+	lock, err := parseRPMDB(testGunzip(t, testRPMDB))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*lockPackage{
+		"glibc.x86_64": {
+			Name:       "glibc",
+			Version:    "2.34-100.el9",
+			License:    "LGPLv2+ and LGPLv2+ with exceptions and GPLv2+",
+			Qualifiers: map[string]string{"arch": "x86_64"},
+		},
+		"bash.x86_64": {Name: "bash", Version: "5.1.8-9.el9", License: "GPLv3+", Qualifiers: map[string]string{"arch": "x86_64"}},
+		"basesystem.noarch": {
+			Name:       "basesystem",
+			Version:    "11-13.el9",
+			License:    "Public Domain",
+			Qualifiers: map[string]string{"arch": "noarch", "epoch": "1"},
+		},
+	}, lock.Packages)
+	assert.Equal(t, map[string][]string{
+		"glibc.x86_64": {"basesystem.noarch"},
+		"bash.x86_64":  {"glibc.x86_64"},
+	}, lock.Dependencies)
+
+	_, err = parseRPMDB([]byte("not a database"))
+	assert.Error(t, err)
+}
+
+func TestReadRPM(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	lock, err := readRPM(afs, "rootfs")
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+
+	assert.NoError(t, afs.WriteFile("rootfs/usr/lib/sysimage/rpm/rpmdb.sqlite", testGunzip(t, testRPMDB), 0644))
+	lock, err = readRPM(afs, "rootfs")
+	assert.NoError(t, err)
+	assert.Len(t, lock.Packages, 3)
+
+	assert.NoError(t, afs.WriteFile("rootfs/var/lib/rpm/rpmdb.sqlite", []byte("corrupt"), 0644))
+	_, err = readRPM(afs, "rootfs")
+	assert.EqualError(t, err, "rootfs/var/lib/rpm/rpmdb.sqlite: not an SQLite 3 database")
+}
+
+func TestParseRPMHeader(t *testing.T) {
+	blob := binary.BigEndian.AppendUint32(nil, 2)
+	blob = binary.BigEndian.AppendUint32(blob, 12)
+	for _, entry := range [][4]uint32{{rpmTagName, rpmTypeString, 0, 1}, {rpmTagEpoch, rpmTypeInt32, 8, 1}} {
+		for _, value := range entry {
+			blob = binary.BigEndian.AppendUint32(blob, value)
+		}
+	}
+	blob = append(blob, "zlib\x00\x00\x00\x00\x00\x00\x00\x02"...)
+
+	header, err := parseRPMHeader(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int][]string{rpmTagName: {"zlib"}, rpmTagEpoch: {"2"}}, header)
+	assert.Equal(t, "zlib", rpmValue(header, rpmTagName))
+	assert.Equal(t, "", rpmValue(header, rpmTagLicense))
+
+	_, err = parseRPMHeader(blob[:20])
+	assert.ErrorIs(t, err, errRPMHeader)
+}
@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// dependencyDirs are the directories of installed packages, vendored dependencies and
+// dependency caches, whose lockfiles are not those of the projects being scanned.
+var dependencyDirs = []string{"node_modules", "vendor", "site-packages", "dist-packages", ".cargo", ".npm", ".cache", ".gradle", ".m2"}
+
+// errNoProjects is returned when a directory scan finds nothing to read.
+var errNoProjects = errors.New("no lockfile, go.mod or SBOM found")
+
+// ScanDirectory discovers every lockfile, go.mod file and CycloneDX (*.cdx.json) or
+// SPDX (*.spdx.json) document of a directory tree, such as the root of a monorepo,
+// and reads them using up to Workers files at a time. Files ignored by the .gitignore
+// files of the tree, and the directories of installed or vendored dependencies, are
+// skipped. Include and Exclude further select the files, by path relative to the root
+// or by name.
+//
+// Unless PerProject is set, the files are merged into a single KissBOM named after the
+// root, the files each package was found in being recorded in its notes. Otherwise a
+// KissBOM is saved for each file, under its path relative to the root.
+func (c *Converter) ScanDirectory(root string) error {
+	log.Printf("scanning: %v", root)
+
+	filenames, err := c.discover(root)
+	if err != nil {
+		return err
+	}
+	if len(filenames) == 0 {
+		return fmt.Errorf("%s: %w", root, errNoProjects)
+	}
+	c.Projects = filenames
+
+	kissboms, err := c.loadAll(root, filenames)
+	if err != nil {
+		return err
+	}
+
+	if c.PerProject {
+		return c.writeProjects(filenames, kissboms)
+	}
+
+	merged, err := c.merge(kissboms, filenames)
+	if err != nil {
+		return err
+	}
+	merged = c.process(merged)
+	log.Printf("merged %v packages from %v files", len(merged.Packages), len(filenames))
+
+	name := filepath.Base(root)
+	if abs, err := filepath.Abs(root); err == nil {
+		name = filepath.Base(abs)
+	}
+	c.OutputFileName = path.Join(c.OutputFolder, name)
+	return c.writeToFile(merged)
+}
+
+// discover walks the directory tree, returning the slash separated paths, relative to
+// the root, of the files to read.
+func (c *Converter) discover(root string) (filenames []string, err error) {
+	ignore := &gitignore{}
+	err = c.Afs.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+
+		if info.IsDir() {
+			if relative != "." && (info.Name() == ".git" || containsString(dependencyDirs, info.Name()) || ignore.ignored(relative, true)) {
+				return filepath.SkipDir
+			}
+			if source := readManifest(c.Afs, filepath.Join(name, ".gitignore")); source != nil {
+				ignore.add(relative, source)
+			}
+			return nil
+		}
+		if isScanned(relative) && !ignore.ignored(relative, false) && c.isSelected(relative) {
+			filenames = append(filenames, relative)
+		}
+		return nil
+	})
+	return
+}
+
+// isScanned returns true if the file is a lockfile, a go.mod file or an SBOM.
+func isScanned(name string) bool {
+	base := path.Base(name)
+	return IsLockfile(base) || base == "go.mod" || strings.HasSuffix(base, ".cdx.json") || strings.HasSuffix(base, ".spdx.json")
+}
+
+// isSelected returns true if the file, given by its path relative to the root of the
+// scan, matches an Include pattern, when there are any, and no Exclude pattern.
+func (c *Converter) isSelected(name string) bool {
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if models.GlobMatch(pattern, name) || models.GlobMatch(pattern, path.Base(name)) {
+				return true
+			}
+		}
+		return false
+	}
+	return (len(c.Include) == 0 || matches(c.Include)) && !matches(c.Exclude)
+}
+
+// loadAll reads the files, relative to the root, using up to Workers files at a time.
+// The errors of every file that could not be read are joined together.
+func (c *Converter) loadAll(root string, filenames []string) ([]models.KissBOM, error) {
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	kissboms := make([]models.KissBOM, len(filenames))
+	errs := make([]error, len(filenames))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, workers)
+	for i, filename := range filenames {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, filename string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			log.Printf("reading: %v", filename)
+			kissbom, err := c.load(filepath.Join(root, filepath.FromSlash(filename)))
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", filename, err)
+				return
+			}
+			kissboms[i] = kissbom
+		}(i, filename)
+	}
+	wg.Wait()
+
+	return kissboms, errors.Join(errs...)
+}
+
+// writeProjects processes and saves the KissBOM of each file under its path relative
+// to the root of the scan, summing up the duplicates, enrichments and categories.
+func (c *Converter) writeProjects(filenames []string, kissboms []models.KissBOM) error {
+	duplicates, enriched, categories := 0, 0, map[string]int{}
+	for i, kissbom := range kissboms {
+		kissbom := c.process(kissbom)
+		duplicates += c.Duplicates
+		enriched += c.Enriched
+		for category, count := range c.Categories {
+			categories[category] += count
+		}
+
+		c.OutputFileName = path.Join(c.OutputFolder, filenames[i])
+		if err := c.writeToFile(kissbom); err != nil {
+			return err
+		}
+	}
+	c.Duplicates, c.Enriched, c.Categories = duplicates, enriched, categories
+	return nil
+}
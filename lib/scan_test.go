@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// testMonorepo writes a repository with a Python service, an npm front end, a Go module,
+// an SBOM and files that are ignored or in dependency directories.
+func testMonorepo(t *testing.T) *afero.Afero {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	files := map[string]string{
+		"repo/.gitignore":                               "/build\n*.tmp.json\n",
+		"repo/api/requirements.txt":                     "flask==3.0.2\nrequests==2.31.0\n",
+		"repo/web/package-lock.json":                    testNpmLockV3,
+		"repo/web/node_modules/debug/package-lock.json": testNpmLockV3,
+		"repo/web/.gitignore":                           "fixtures/\n",
+		"repo/web/fixtures/requirements.txt":            "django==5.0.3\n",
+		"repo/tools/go.mod":                             "module github.com/example/tools\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.8.0\n",
+		"repo/sbom/app.cdx.json":                        mergeCycloneDX,
+		"repo/sbom/old.tmp.json":                        mergeCycloneDX,
+		"repo/build/requirements.txt":                   "django==5.0.3\n",
+		"repo/.git/requirements.txt":                    "django==5.0.3\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, afs.WriteFile(name, []byte(content), 0644))
+	}
+	return afs
+}
+
+func TestConverter_ScanDirectory(t *testing.T) {
+	converter := &Converter{Afs: testMonorepo(t), OutputFolder: "out", OutputFormat: models.OptionJSON, Workers: 2}
+	assert.NoError(t, converter.ScanDirectory("repo"))
+	assert.Equal(t, []string{"api/requirements.txt", "sbom/app.cdx.json", "tools/go.mod", "web/package-lock.json"}, converter.Projects)
+	assert.Equal(t, "out/repo.json", converter.OutputFileName)
+
+	data, err := converter.Afs.ReadFile("out/repo.json")
+	assert.NoError(t, err)
+	var kissbom models.KissBOM
+	assert.NoError(t, json.Unmarshal(data, &kissbom))
+
+	notes := map[string]string{}
+	for _, p := range kissbom.Packages {
+		notes[p.Purl] = p.Notes
+	}
+	assert.Equal(t, "[sources: api/requirements.txt]", notes["pkg:pypi/flask@3.0.2"])
+	assert.Equal(t, "[sources: tools/go.mod]", notes["pkg:golang/github.com/spf13/cobra@v1.8.0"])
+	assert.Equal(t, "[sources: sbom/app.cdx.json]", notes["pkg:npm/lodash@4.17.21"])
+	assert.NotContains(t, notes, "pkg:pypi/django@5.0.3")
+}
+
+func TestConverter_ScanDirectory_PerProject(t *testing.T) {
+	converter := &Converter{Afs: testMonorepo(t), OutputFolder: "out", OutputFormat: models.OptionJSON, PerProject: true}
+	converter.Include = []string{"api/*", "*.cdx.json"}
+	converter.Exclude = []string{"sbom/*"}
+	assert.NoError(t, converter.ScanDirectory("repo"))
+	assert.Equal(t, []string{"api/requirements.txt"}, converter.Projects)
+
+	exists, err := converter.Afs.Exists("out/api/requirements.txt.json")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestConverter_ScanDirectory_Errors(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	converter := &Converter{Afs: afs, OutputFormat: models.OptionJSON}
+	assert.NoError(t, afs.MkdirAll("empty", 0755))
+	assert.EqualError(t, converter.ScanDirectory("empty"), "empty: no lockfile, go.mod or SBOM found")
+
+	assert.NoError(t, afs.WriteFile("broken/a/package-lock.json", []byte("{"), 0644))
+	assert.NoError(t, afs.WriteFile("broken/b.spdx.json", []byte("[]"), 0644))
+	err := converter.ScanDirectory("broken")
+	assert.ErrorContains(t, err, "a/package-lock.json: ")
+	assert.ErrorContains(t, err, "b.spdx.json: ")
+}
+
+func TestIsScanned(t *testing.T) {
+	assert.True(t, isScanned("a/Cargo.lock"))
+	assert.True(t, isScanned("go.mod"))
+	assert.True(t, isScanned("sbom/app.spdx.json"))
+	assert.False(t, isScanned("package.json"))
+}
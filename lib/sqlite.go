@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// sqliteMagic starts every SQLite 3 database file.
+const sqliteMagic = "SQLite format 3\x00"
+
+// Types of the b-tree pages of an SQLite database that hold tables.
+const (
+	sqliteInteriorTable = 0x05 // sqliteInteriorTable is an interior page of a table b-tree.
+	sqliteLeafTable     = 0x0d // sqliteLeafTable is a leaf page of a table b-tree, holding the rows.
+)
+
+// errSQLiteCorrupt is returned when an SQLite database cannot be read.
+var errSQLiteCorrupt = errors.New("corrupt SQLite database")
+
+// sqliteDB reads the tables of an SQLite 3 database held in memory. Only what is needed
+// to read package databases is supported: tables are read in rowid order, and indexes,
+// the write-ahead log and free pages are ignored.
+type sqliteDB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+// openSQLite checks the header of an SQLite 3 database.
+func openSQLite(data []byte) (*sqliteDB, error) {
+	if len(data) < 100 || string(data[:16]) != sqliteMagic {
+		return nil, errors.New("not an SQLite 3 database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, errSQLiteCorrupt
+	}
+	return &sqliteDB{data: data, pageSize: pageSize, usableSize: pageSize - int(data[20])}, nil
+}
+
+// rows returns the rows of a table, in rowid order, each row being the values of its
+// columns: nil, int64, float64, string or []byte. A column declared as INTEGER PRIMARY
+// KEY is an alias of the rowid, stored as nil.
+func (db *sqliteDB) rows(table string) ([][]any, error) {
+	// The schema is a table rooted on the first page, with the columns type, name,
+	// tbl_name, rootpage and sql.
+	schema, err := db.tableRows(1)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range schema {
+		if len(row) < 4 || row[0] != "table" || row[1] != table {
+			continue
+		}
+		root, ok := row[3].(int64)
+		if !ok {
+			return nil, errSQLiteCorrupt
+		}
+		return db.tableRows(int(root))
+	}
+	return nil, fmt.Errorf("no such table: %s", table)
+}
+
+// page returns a page by number, starting at 1.
+func (db *sqliteDB) page(number int) ([]byte, error) {
+	start := (number - 1) * db.pageSize
+	if number < 1 || start+db.pageSize > len(db.data) {
+		return nil, errSQLiteCorrupt
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// tableRows walks the table b-tree rooted at a page and decodes the record of each row.
+func (db *sqliteDB) tableRows(root int) (rows [][]any, err error) {
+	visited := map[int]bool{}
+	var walk func(number int) error
+	walk = func(number int) error {
+		if visited[number] {
+			return errSQLiteCorrupt
+		}
+		visited[number] = true
+		page, err := db.page(number)
+		if err != nil {
+			return err
+		}
+		header := 0
+		if number == 1 {
+			header = 100
+		}
+		if header+8 > len(page) {
+			return errSQLiteCorrupt
+		}
+
+		kind := page[header]
+		cells := int(binary.BigEndian.Uint16(page[header+3:]))
+		pointers := header + 8
+		if kind == sqliteInteriorTable {
+			pointers = header + 12
+		} else if kind != sqliteLeafTable {
+			return errSQLiteCorrupt
+		}
+		if pointers+2*cells > len(page) {
+			return errSQLiteCorrupt
+		}
+
+		for i := 0; i < cells; i++ {
+			offset := int(binary.BigEndian.Uint16(page[pointers+2*i:]))
+			if offset+4 > len(page) {
+				return errSQLiteCorrupt
+			}
+			if kind == sqliteInteriorTable {
+				if err := walk(int(binary.BigEndian.Uint32(page[offset:]))); err != nil {
+					return err
+				}
+				continue
+			}
+			payload, err := db.cellPayload(page, offset)
+			if err != nil {
+				return err
+			}
+			row, err := sqliteRecord(payload)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		if kind == sqliteInteriorTable {
+			return walk(int(binary.BigEndian.Uint32(page[header+8:])))
+		}
+		return nil
+	}
+	return rows, walk(root)
+}
+
+// cellPayload returns the payload of a cell of a table leaf page, following its
+// overflow pages when it does not fit in the page.
+func (db *sqliteDB) cellPayload(page []byte, offset int) ([]byte, error) {
+	size, n := sqliteVarint(page[offset:])
+	offset += n
+	_, n = sqliteVarint(page[offset:]) // The rowid.
+	offset += n
+	if n == 0 || size < 0 || size > int64(len(db.data)) {
+		return nil, errSQLiteCorrupt
+	}
+
+	total := int(size)
+	local := total
+	if maxLocal := db.usableSize - 35; total > maxLocal {
+		minLocal := (db.usableSize-12)*32/255 - 23
+		local = minLocal + (total-minLocal)%(db.usableSize-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if offset+local > len(page) {
+		return nil, errSQLiteCorrupt
+	}
+	payload := append([]byte{}, page[offset:offset+local]...)
+	if local == total {
+		return payload, nil
+	}
+
+	if offset+local+4 > len(page) {
+		return nil, errSQLiteCorrupt
+	}
+	next := int(binary.BigEndian.Uint32(page[offset+local:]))
+	for len(payload) < total {
+		overflow, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := overflow[4:db.usableSize]
+		if remaining := total - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		next = int(binary.BigEndian.Uint32(overflow))
+	}
+	return payload, nil
+}
+
+// sqliteRecord decodes a record, a header of serial types followed by the values.
+func sqliteRecord(payload []byte) ([]any, error) {
+	headerSize, n := sqliteVarint(payload)
+	if n == 0 || headerSize < int64(n) || headerSize > int64(len(payload)) {
+		return nil, errSQLiteCorrupt
+	}
+	types := []int64{}
+	for offset := n; offset < int(headerSize); {
+		serialType, n := sqliteVarint(payload[offset:])
+		if n == 0 {
+			return nil, errSQLiteCorrupt
+		}
+		types = append(types, serialType)
+		offset += n
+	}
+
+	values := []any{}
+	body := payload[headerSize:]
+	for _, serialType := range types {
+		size := sqliteSerialSize(serialType)
+		if size > len(body) {
+			return nil, errSQLiteCorrupt
+		}
+		field := body[:size]
+		body = body[size:]
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType >= 1 && serialType <= 6:
+			value := int64(int8(field[0]))
+			for _, b := range field[1:] {
+				value = value<<8 | int64(b)
+			}
+			values = append(values, value)
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(field)))
+		case serialType == 8 || serialType == 9:
+			values = append(values, serialType-8)
+		case serialType >= 12 && serialType%2 == 0:
+			values = append(values, bytes.Clone(field))
+		case serialType >= 13:
+			values = append(values, string(field))
+		default:
+			return nil, errSQLiteCorrupt
+		}
+	}
+	return values, nil
+}
+
+// sqliteSerialSize returns the size of a value of a record from its serial type.
+func sqliteSerialSize(serialType int64) int {
+	switch {
+	case serialType >= 12:
+		return int((serialType - 12) / 2)
+	case serialType == 5:
+		return 6
+	case serialType == 6 || serialType == 7:
+		return 8
+	case serialType >= 1 && serialType <= 4:
+		return int(serialType)
+	}
+	return 0
+}
+
+// sqliteVarint decodes a big-endian variable-length integer of up to 9 bytes, returning
+// its value and its size, which is 0 if the data is too short.
+func sqliteVarint(data []byte) (value int64, n int) {
+	for n < len(data) && n < 9 {
+		b := data[n]
+		n++
+		if n == 9 {
+			return value<<8 | int64(b), n
+		}
+		value = value<<7 | int64(b&0x7f)
+		if b < 0x80 {
+			return value, n
+		}
+	}
+	return 0, 0
+}
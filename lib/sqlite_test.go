@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSQLiteDB is a gzipped SQLite database with pages of 512 bytes, holding a table
+// created with "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, size INTEGER,
+// ratio REAL, data BLOB)" and 40 rows, spread over several leaf pages. The data of row
+// 7 is 1500 bytes long, so that it overflows.
+const testSQLiteDB = `
+H4sIAAAAAAACA+2XS2wSURSGzxneUCgzPIcBelsoRYOmzEBL3XSgEq3ii7JoVwYtjURrY8tCjYvWVRNNdNPYxK2LunHdjYkLF25c
+uLJRYxrTGOIrxhjT+J5hIF5d1Li1/OF5+O5/Lve/k5kZO1ao1ipkamZ2ulwjEjCACDIhAMpHABP8EipP/R/f/yYGdp9EuwrjVVAe
+bW2hWTT5fD5cGKmVT5ypKLFMzzVemJFiPlvKk1I2V8iTRonEq5Nk9HApvy9fJEeLo4eyxQlyMD+RIGfL0xVSyo+XEmSuerHSghJk
+tlyrzhDFqZAgk+VameQKR3I7DI1svqjtTdpbW9tHRrd6TJva2rZS8je0V2Fb5w/tVfi/8vwXqddmbhgGfItP8R7ewZu4gGOYxRi6
+4Ae8hsdwH1ZgCRZgCooK+LtYtxEEBE69KNkl9r9xu92cSykxzVJy6EDG5XLxnIqZmrXMO5nThnOKWBofzJ1iFTkp1+TAe6fTGehU
+sZZDes+y7NAsOjs7WQeNpz44HA7OTrtK4gO73c51qBg2a+JHXUdHB2ejueTOTZvNxlppu/5PVquVtxiBb3UfisRlRmtusVicZuWn
+Fp3ZNJvNV04rMfAM3NjbqA0K41uEpfMb1fFN64HPw5vNM7PRyBrUpk3ntGvRYDA49VSz1Fe9Xs/rqJlJttXhujZep9M5GQoWvzMM
+wyLlmNTVEVHLvwC4gY/wLt7GJbyMU8oOGMYoOuEbrMNDWIVbcA0uwXEF3EJsnFq4VP9aPB4X+pSSrjW9IVgelyPzDbivr4+NUbyU
+eRKLxVy9Kt8qDcL1xd7eXi5KBScNPLNEo1FXhAbTsLgaiUTYHtow9bynp0fopicgwXxdJtoEuru7WULz4johhOuiNoSUvODv6uri
+w9TulfpfyGHt/4bDYS5E4eLQuf2hUIgN0sdEZiMYDAYEaveKg9XzsqBZCILABmh84GUgEOB42jVdXuF5nvNTiyCm6ka/38/5aE6a
+WFNuH1gvbSe+8nq9AQ/dPVk0yx6tu8fj+Qk3edCqAA4AAA==`
+
+// testGunzip decodes and decompresses a base64 gzipped fixture.
+func testGunzip(t *testing.T, encoded string) []byte {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	assert.NoError(t, err)
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	assert.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestSQLiteRows(t *testing.T) {
+	db, err := openSQLite(testGunzip(t, testSQLiteDB))
+	assert.NoError(t, err)
+	assert.Equal(t, 512, db.pageSize)
+
+	rows, err := db.rows("items")
+	assert.NoError(t, err)
+	assert.Len(t, rows, 40)
+	assert.Equal(t, []any{nil, "item-1", int64(1000), nil, []byte{1, 1, 1}}, rows[0])
+	assert.Equal(t, []any{nil, "item-2", int64(-2), nil, []byte{2, 2, 2}}, rows[1])
+	assert.Equal(t, []any{nil, "item-3", int64(3000), 0.75, []byte{3, 3, 3}}, rows[2])
+	assert.Equal(t, bytes.Repeat([]byte{7}, 1500), rows[6][4])
+	assert.Equal(t, "item-40", rows[39][1])
+
+	_, err = db.rows("missing")
+	assert.EqualError(t, err, "no such table: missing")
+}
+
+func TestOpenSQLite(t *testing.T) {
+	_, err := openSQLite([]byte("not a database"))
+	assert.Error(t, err)
+
+	data := testGunzip(t, testSQLiteDB)
+	data[16], data[17] = 0, 3
+	_, err = openSQLite(data)
+	assert.ErrorIs(t, err, errSQLiteCorrupt)
+
+	data = testGunzip(t, testSQLiteDB)
+	db, err := openSQLite(data[:1024])
+	assert.NoError(t, err)
+	_, err = db.rows("items")
+	assert.ErrorIs(t, err, errSQLiteCorrupt)
+}
+
+func TestSQLiteVarint(t *testing.T) {
+	value, n := sqliteVarint([]byte{0x7f})
+	assert.Equal(t, int64(127), value)
+	assert.Equal(t, 1, n)
+	value, n = sqliteVarint([]byte{0x81, 0x00})
+	assert.Equal(t, int64(128), value)
+	assert.Equal(t, 2, n)
+	value, n = sqliteVarint([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	assert.Equal(t, int64(-1), value)
+	assert.Equal(t, 9, n)
+	_, n = sqliteVarint([]byte{0x81})
+	assert.Equal(t, 0, n)
+}
+
+func TestSQLiteRecord(t *testing.T) {
+	values, err := sqliteRecord([]byte{5, 0, 1, 8, 15, 0xfe, 'a'})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{nil, int64(-2), int64(0), "a"}, values)
+
+	_, err = sqliteRecord([]byte{3, 1, 19, 'a'})
+	assert.ErrorIs(t, err, errSQLiteCorrupt)
+
+	_, err = sqliteRecord([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	assert.ErrorIs(t, err, errSQLiteCorrupt)
+}
@@ -0,0 +1,104 @@
+package lib
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/package-url/packageurl-go"
+)
+
+// uvLock contains the parts of a uv.lock file used to list packages.
+type uvLock struct {
+	Packages []uvPackage `toml:"package"`
+}
+
+// uvPackage is a package of a uv.lock file.
+type uvPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	Source  struct {
+		Editable  string `toml:"editable"`
+		Virtual   string `toml:"virtual"`
+		Directory string `toml:"directory"`
+		Path      string `toml:"path"`
+	} `toml:"source"`
+	Sdist *struct {
+		Hash string `toml:"hash"`
+	} `toml:"sdist"`
+	Dependencies         []uvDependency            `toml:"dependencies"`
+	OptionalDependencies map[string][]uvDependency `toml:"optional-dependencies"`
+	DevDependencies      map[string][]uvDependency `toml:"dev-dependencies"`
+}
+
+// uvDependency is a dependency of a package of a uv.lock file, whose version is only
+// written when several versions of the package are locked.
+type uvDependency struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+}
+
+// parseUvLock parses a uv.lock file. Packages are keyed by normalized name and version,
+// as uv may resolve several versions of a package for different environments. The
+// project is the package whose source is the directory of the lockfile; its
+// dependencies and optional dependencies are regular dependencies, and its development
+// dependency groups are development dependencies. Workspace members and other local
+// directories are looked through.
+func parseUvLock(source []byte) (*lockfile, error) {
+	var document uvLock
+	if err := toml.Unmarshal(source, &document); err != nil {
+		return nil, err
+	}
+
+	lock := newLockfile(packageurl.TypePyPi)
+	versions := map[string][]string{}
+	for _, entry := range document.Packages {
+		name := pypiName(entry.Name)
+		versions[name] = append(versions[name], entry.Version)
+	}
+	resolve := func(dependency uvDependency) string {
+		name, version := pypiName(dependency.Name), dependency.Version
+		if version == "" && len(versions[name]) == 1 {
+			version = versions[name][0]
+		}
+		return name + "@" + version
+	}
+
+	var root *uvPackage
+	for i, entry := range document.Packages {
+		id := resolve(uvDependency{Name: entry.Name, Version: entry.Version})
+		p := &lockPackage{Name: pypiName(entry.Name), Version: entry.Version}
+		for _, directory := range []string{entry.Source.Editable, entry.Source.Virtual, entry.Source.Directory, entry.Source.Path} {
+			if directory != "" {
+				p.Local = true
+				if directory == "." && root == nil {
+					root = &document.Packages[i]
+					lock.Name, lock.Version = entry.Name, p.Version
+				}
+			}
+		}
+		if entry.Sdist != nil {
+			p.Hashes = pythonHashes([]string{entry.Sdist.Hash})
+		}
+		lock.Packages[id] = p
+
+		dependencies := entry.Dependencies
+		for _, extra := range entry.OptionalDependencies {
+			dependencies = append(dependencies, extra...)
+		}
+		for _, dependency := range dependencies {
+			lock.Dependencies[id] = appendUnique(lock.Dependencies[id], resolve(dependency))
+		}
+	}
+
+	if root == nil {
+		return lock, nil
+	}
+	for _, d := range lock.Dependencies[resolve(uvDependency{Name: root.Name, Version: root.Version})] {
+		lock.addDirect(d, false)
+	}
+	for _, group := range root.DevDependencies {
+		for _, dependency := range group {
+			lock.addDirect(resolve(dependency), true)
+		}
+	}
+	lock.markDev()
+	return lock, nil
+}
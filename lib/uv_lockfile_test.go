@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testUvLock = `version = 1
+requires-python = ">=3.12"
+
+[[package]]
+name = "api"
+version = "0.1.0"
+source = { editable = "." }
+dependencies = [
+    { name = "flask" },
+    { name = "lib" },
+]
+
+[package.optional-dependencies]
+socks = [
+    { name = "pysocks" },
+]
+
+[package.dev-dependencies]
+dev = [
+    { name = "pytest" },
+]
+
+[[package]]
+name = "flask"
+version = "3.0.2"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "werkzeug", version = "3.0.1" },
+]
+sdist = { url = "https://files.pythonhosted.org/flask-3.0.2.tar.gz", hash = "sha256:822c03f4b799204250a7ee84b1eddc8b4c5d23b4a3c8bd5d7e4feb1b5a6e1fdd", size = 675248 }
+wheels = [
+    { url = "https://files.pythonhosted.org/flask-3.0.2-py3-none-any.whl", hash = "sha256:3232e0e9c850d781933cf0207523d1ece087eb8d87b23777ae38456e2fbe7c6e", size = 101300 },
+]
+
+[[package]]
+name = "lib"
+version = "0.1.0"
+source = { editable = "packages/lib" }
+dependencies = [
+    { name = "werkzeug", version = "3.0.1" },
+]
+
+[[package]]
+name = "pysocks"
+version = "1.7.1"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "pytest"
+version = "8.1.1"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "werkzeug", version = "2.3.8" },
+]
+
+[[package]]
+name = "werkzeug"
+version = "2.3.8"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "werkzeug"
+version = "3.0.1"
+source = { registry = "https://pypi.org/simple" }
+`
+
+func TestParseUvLock(t *testing.T) {
+	l, err := parseUvLock([]byte(testUvLock))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "api", l.Name)
+	assert.Equal(t, "0.1.0", l.Version)
+	assert.Len(t, l.Packages, 7)
+	assert.True(t, l.Packages["api@0.1.0"].Local)
+	assert.True(t, l.Packages["lib@0.1.0"].Local)
+	assert.Equal(t, &lockPackage{
+		Name:    "flask",
+		Version: "3.0.2",
+		Hashes:  map[string]string{"SHA-256": "822c03f4b799204250a7ee84b1eddc8b4c5d23b4a3c8bd5d7e4feb1b5a6e1fdd"},
+	}, l.Packages["flask@3.0.2"])
+
+	assert.Equal(t, []string{"flask@3.0.2", "lib@0.1.0", "pysocks@1.7.1", "pytest@8.1.1"}, l.Dependencies[""])
+	assert.Equal(t, []string{"werkzeug@2.3.8"}, l.Dependencies["pytest@8.1.1"])
+	assert.Equal(t, []string{"werkzeug@3.0.1"}, l.Dependencies["lib@0.1.0"])
+
+	assert.False(t, l.Packages["pysocks@1.7.1"].Dev)
+	assert.True(t, l.Packages["pytest@8.1.1"].Dev)
+	assert.True(t, l.Packages["werkzeug@2.3.8"].Dev)
+	assert.False(t, l.Packages["werkzeug@3.0.1"].Dev)
+}
+
+func TestParseUvLock_Error(t *testing.T) {
+	_, err := parseUvLock([]byte("version = \n"))
+	assert.EqualError(t, err, `toml: line 1 (last key "version"): expected value but found '\n' instead`)
+}
@@ -0,0 +1,466 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// compareVersions compares two versions using the ordering rules of the provided
+// OSV ecosystem (ex: "npm", "PyPI", "Maven", "Debian:12").
+//
+// Returns:
+//   - -1 if a is lower than b, 0 if they are equal and 1 if a is greater than b.
+func compareVersions(ecosystem string, a string, b string) int {
+	if base, _, ok := strings.Cut(ecosystem, ":"); ok {
+		ecosystem = base
+	}
+	switch ecosystem {
+	case "PyPI":
+		return comparePEP440(a, b)
+	case "Maven":
+		return compareMaven(a, b)
+	case "RubyGems":
+		return compareRubyGems(a, b)
+	case "Debian", "Ubuntu":
+		return compareDebian(a, b)
+	case "npm", "Go", "crates.io", "NuGet", "Hex", "Pub", "Packagist", "SwiftURL":
+		return compareSemver(a, b)
+	}
+	return compareGeneric(a, b)
+}
+
+// compareInts compares two integers, returning -1, 0 or 1.
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareNumeric compares two strings of digits of any length numerically.
+func compareNumeric(a string, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return compareInts(len(a), len(b))
+	}
+	return strings.Compare(a, b)
+}
+
+// isNumeric returns true if the string is non-empty and contains only digits.
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareSemver compares versions following Semantic Versioning 2.0.0, leniently
+// accepting a leading "v" and any number of numeric release components.
+func compareSemver(a string, b string) int {
+	parse := func(v string) (release []string, prerelease []string) {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		v, _, _ = strings.Cut(v, "+")
+		v, pre, hasPre := strings.Cut(v, "-")
+		release = strings.Split(v, ".")
+		if hasPre {
+			prerelease = strings.Split(pre, ".")
+		}
+		return
+	}
+	releaseA, preA := parse(a)
+	releaseB, preB := parse(b)
+
+	for i := 0; i < len(releaseA) || i < len(releaseB); i++ {
+		x, y := "0", "0"
+		if i < len(releaseA) {
+			x = releaseA[i]
+		}
+		if i < len(releaseB) {
+			y = releaseB[i]
+		}
+		if c := compareIdentifier(x, y); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case preA == nil && preB == nil:
+		return 0
+	case preA == nil:
+		return 1
+	case preB == nil:
+		return -1
+	}
+	for i := 0; i < len(preA) && i < len(preB); i++ {
+		if c := compareIdentifier(preA[i], preB[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(preA), len(preB))
+}
+
+// compareIdentifier compares semver identifiers: numeric identifiers compare
+// numerically and are lower than alphanumeric identifiers, which compare lexically.
+func compareIdentifier(a string, b string) int {
+	numA, numB := isNumeric(a), isNumeric(b)
+	switch {
+	case numA && numB:
+		return compareNumeric(a, b)
+	case numA:
+		return -1
+	case numB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// pep440Pattern matches a PEP 440 version, capturing the epoch (1), release (2),
+// pre-release phase (3) and number (4), implicit post-release number (5), explicit
+// post-release marker (6) and number (7), dev-release marker (8) and number (9) and
+// local version label (10).
+var pep440Pattern = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|alpha|b|beta|c|rc|pre|preview)[-_.]?(\d*))?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+	`(?:[-_.]?(dev)[-_.]?(\d*))?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// pep440Phases normalizes pre-release phases to sortable ranks. A dev release of a
+// final version ("") sorts before its pre-releases, and a final version ("~") after.
+var pep440Phases = map[string]string{"a": "a", "alpha": "a", "b": "b", "beta": "b", "c": "c", "rc": "c", "pre": "c", "preview": "c"}
+
+// pep440Version is a parsed PEP 440 version.
+type pep440Version struct {
+	epoch   string
+	release []string
+	phase   string
+	pre     string
+	hasPost bool
+	post    string
+	hasDev  bool
+	dev     string
+	local   string
+}
+
+// parsePEP440 parses a PEP 440 version, returning false if it is not valid.
+func parsePEP440(v string) (version pep440Version, ok bool) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(v)))
+	if m == nil {
+		return version, false
+	}
+	version.epoch = m[1]
+	version.release = strings.Split(m[2], ".")
+	version.hasPost = m[5] != "" || m[6] != ""
+	version.post = m[5] + m[7]
+	version.hasDev = m[8] != ""
+	version.dev = m[9]
+	version.local = m[10]
+
+	switch {
+	case m[3] != "":
+		version.phase, version.pre = pep440Phases[m[3]], m[4]
+	case version.hasDev && !version.hasPost:
+		version.phase = ""
+	default:
+		version.phase = "~"
+	}
+	return version, true
+}
+
+// comparePEP440 compares Python package versions following PEP 440.
+func comparePEP440(a string, b string) int {
+	x, okA := parsePEP440(a)
+	y, okB := parsePEP440(b)
+	if !okA || !okB {
+		return compareGeneric(a, b)
+	}
+	if c := compareNumeric(x.epoch, y.epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(x.release) || i < len(y.release); i++ {
+		r, s := "0", "0"
+		if i < len(x.release) {
+			r = x.release[i]
+		}
+		if i < len(y.release) {
+			s = y.release[i]
+		}
+		if c := compareNumeric(r, s); c != 0 {
+			return c
+		}
+	}
+	if c := strings.Compare(x.phase, y.phase); c != 0 {
+		return c
+	}
+	if c := compareNumeric(x.pre, y.pre); c != 0 {
+		return c
+	}
+	if c := compareOptional(x.hasPost, y.hasPost, x.post, y.post, -1); c != 0 {
+		return c
+	}
+	if c := compareOptional(x.hasDev, y.hasDev, x.dev, y.dev, 1); c != 0 {
+		return c
+	}
+	return compareGeneric(x.local, y.local)
+}
+
+// compareOptional compares optional numeric components, where a missing component
+// sorts before (absent is -1) or after (absent is 1) any present component.
+func compareOptional(hasA bool, hasB bool, a string, b string, absent int) int {
+	switch {
+	case !hasA && !hasB:
+		return 0
+	case !hasA:
+		return absent
+	case !hasB:
+		return -absent
+	}
+	return compareNumeric(a, b)
+}
+
+// mavenQualifiers orders well known Maven qualifiers. Unknown qualifiers sort after
+// all known qualifiers, lexically.
+var mavenQualifiers = map[string]int{
+	"alpha": 0, "a": 0,
+	"beta": 1, "b": 1,
+	"milestone": 2, "m": 2,
+	"rc": 3, "cr": 3,
+	"snapshot": 4,
+	"":         5, "ga": 5, "final": 5, "release": 5,
+	"sp": 6,
+}
+
+// mavenItems splits a Maven version into numeric and qualifier items, following the
+// rules of Maven's ComparableVersion.
+func mavenItems(v string) (items []string) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	var current strings.Builder
+	flush := func() {
+		items = append(items, current.String())
+		current.Reset()
+	}
+	for i, r := range v {
+		switch {
+		case r == '.' || r == '-' || r == '_':
+			flush()
+		case i > 0 && current.Len() > 0 && unicode.IsDigit(r) != isNumeric(current.String()):
+			flush()
+			current.WriteRune(r)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	// trailing zero and release qualifier items are insignificant
+	for len(items) > 1 {
+		last := items[len(items)-1]
+		if (isNumeric(last) && strings.Trim(last, "0") == "") || (!isNumeric(last) && mavenQualifiers[last] == 5 && last != "") || last == "" {
+			items = items[:len(items)-1]
+			continue
+		}
+		break
+	}
+	return
+}
+
+// compareMavenItem compares a single Maven item, where "" represents a missing item.
+func compareMavenItem(a string, b string) int {
+	numA, numB := isNumeric(a), isNumeric(b)
+	switch {
+	case numA && numB:
+		return compareNumeric(a, b)
+	case numA:
+		if b == "" {
+			return compareNumeric(a, "0")
+		}
+		return 1
+	case numB:
+		if a == "" {
+			return compareNumeric("0", b)
+		}
+		return -1
+	}
+	rankA, knownA := mavenQualifiers[a]
+	rankB, knownB := mavenQualifiers[b]
+	switch {
+	case knownA && knownB:
+		return compareInts(rankA, rankB)
+	case knownA:
+		return -1
+	case knownB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareMaven compares Maven artifact versions.
+func compareMaven(a string, b string) int {
+	x, y := mavenItems(a), mavenItems(b)
+	for i := 0; i < len(x) || i < len(y); i++ {
+		r, s := "", ""
+		if i < len(x) {
+			r = x[i]
+		}
+		if i < len(y) {
+			s = y[i]
+		}
+		if c := compareMavenItem(r, s); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareRubyGems compares RubyGems versions, where any segment containing letters
+// marks a pre-release.
+func compareRubyGems(a string, b string) int {
+	segments := func(v string) (s []string) {
+		for _, part := range strings.Split(strings.TrimSpace(v), ".") {
+			s = append(s, splitAlphaNumeric(part)...)
+		}
+		for len(s) > 1 && isNumeric(s[len(s)-1]) && strings.Trim(s[len(s)-1], "0") == "" {
+			s = s[:len(s)-1]
+		}
+		return
+	}
+	x, y := segments(a), segments(b)
+	for i := 0; i < len(x) || i < len(y); i++ {
+		r, s := "0", "0"
+		if i < len(x) {
+			r = x[i]
+		}
+		if i < len(y) {
+			s = y[i]
+		}
+		numR, numS := isNumeric(r), isNumeric(s)
+		switch {
+		case numR && numS:
+			if c := compareNumeric(r, s); c != 0 {
+				return c
+			}
+		case numR:
+			return 1
+		case numS:
+			return -1
+		default:
+			if c := strings.Compare(r, s); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// splitAlphaNumeric splits a string into runs of digits and runs of other characters.
+func splitAlphaNumeric(s string) (parts []string) {
+	start := 0
+	for i := 1; i <= len(s); i++ {
+		if i == len(s) || unicode.IsDigit(rune(s[i])) != unicode.IsDigit(rune(s[i-1])) {
+			parts = append(parts, s[start:i])
+			start = i
+		}
+	}
+	return
+}
+
+// compareDebian compares Debian package versions ([epoch:]upstream[-revision])
+// following the algorithm used by dpkg.
+func compareDebian(a string, b string) int {
+	parse := func(v string) (epoch string, upstream string, revision string) {
+		v = strings.TrimSpace(v)
+		epoch, rest, ok := strings.Cut(v, ":")
+		if !ok {
+			epoch, rest = "0", v
+		}
+		upstream, revision = rest, "0"
+		if i := strings.LastIndex(rest, "-"); i >= 0 {
+			upstream, revision = rest[:i], rest[i+1:]
+		}
+		return
+	}
+	epochA, upstreamA, revisionA := parse(a)
+	epochB, upstreamB, revisionB := parse(b)
+	if c := compareNumeric(epochA, epochB); c != 0 {
+		return c
+	}
+	if c := compareDebianPart(upstreamA, upstreamB); c != 0 {
+		return c
+	}
+	return compareDebianPart(revisionA, revisionB)
+}
+
+// debianOrder returns the sort weight of a character in a Debian version, where "~"
+// sorts before everything (even the end of the string) and letters sort before
+// other characters.
+func debianOrder(r byte) int {
+	switch {
+	case r == '~':
+		return -1
+	case unicode.IsDigit(rune(r)):
+		return 0
+	case unicode.IsLetter(rune(r)):
+		return int(r)
+	}
+	return int(r) + 256
+}
+
+// compareDebianPart compares an upstream version or revision following dpkg's verrevcmp.
+func compareDebianPart(a string, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !unicode.IsDigit(rune(a[i]))) || (j < len(b) && !unicode.IsDigit(rune(b[j]))) {
+			x, y := 0, 0
+			if i < len(a) {
+				x = debianOrder(a[i])
+			}
+			if j < len(b) {
+				y = debianOrder(b[j])
+			}
+			if x != y {
+				return compareInts(x, y)
+			}
+			i++
+			j++
+		}
+		startA, startB := i, j
+		for i < len(a) && unicode.IsDigit(rune(a[i])) {
+			i++
+		}
+		for j < len(b) && unicode.IsDigit(rune(b[j])) {
+			j++
+		}
+		if c := compareNumeric(a[startA:i], b[startB:j]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareGeneric compares versions by splitting them into runs of digits, which
+// compare numerically, and runs of letters, which compare lexically.
+func compareGeneric(a string, b string) int {
+	tokens := func(v string) (t []string) {
+		for _, field := range strings.FieldsFunc(strings.ToLower(v), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			t = append(t, splitAlphaNumeric(field)...)
+		}
+		return
+	}
+	x, y := tokens(a), tokens(b)
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if c := compareIdentifier(x[i], y[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(x), len(y))
+}
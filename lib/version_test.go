@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		a         string
+		b         string
+		expected  int
+	}{
+		{"npm", "1.2.3", "1.2.3", 0},
+		{"npm", "1.2.3", "1.10.0", -1},
+		{"npm", "1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"npm", "1.0.0-rc.1", "1.0.0", -1},
+		{"Go", "v1.2.3", "1.2.3", 0},
+		{"PyPI", "1.0.dev1", "1.0a1", -1},
+		{"PyPI", "1.0rc1", "1.0", -1},
+		{"PyPI", "1.0", "1.0.post1", -1},
+		{"PyPI", "1!0.1", "2.0", 1},
+		{"PyPI", "1.0.0", "1.0", 0},
+		{"Maven", "1.0-alpha", "1.0-beta", -1},
+		{"Maven", "1.0-SNAPSHOT", "1.0", -1},
+		{"Maven", "1.0.0", "1.0", 0},
+		{"Maven", "1.0", "1.0-sp1", -1},
+		{"RubyGems", "1.0.0.pre", "1.0.0", -1},
+		{"Debian:12", "1.0~rc1-1", "1.0-1", -1},
+		{"Debian", "1:0.9-1", "2.0-1", 1},
+		{"Ubuntu", "1.2-1ubuntu1", "1.2-1", 1},
+		{"Unknown", "1.2.10", "1.2.9", 1},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, compareVersions(test.ecosystem, test.a, test.b), "%s %s <=> %s", test.ecosystem, test.a, test.b)
+		assert.Equal(t, -test.expected, compareVersions(test.ecosystem, test.b, test.a), "%s %s <=> %s", test.ecosystem, test.b, test.a)
+	}
+}
@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// LoadVEX reads and combines the statements of CycloneDX VEX documents, OpenVEX
+// documents and YAML statements files.
+func LoadVEX(afs *afero.Afero, filenames ...string) (vex models.VEX, err error) {
+	for _, filename := range filenames {
+		data, err := afs.ReadFile(filename)
+		if err != nil {
+			return vex, err
+		}
+		document, err := models.ParseVEX(data)
+		if err != nil {
+			return vex, fmt.Errorf("%s: %w", filename, err)
+		}
+		log.Printf("loaded %v VEX statements from %s", len(document.Statements), filename)
+		vex.Statements = append(vex.Statements, document.Statements...)
+	}
+	return
+}
+
+// ApplyVEX sets the status of every vulnerability in the report that a VEX statement
+// applies to, matching vulnerabilities by identifier or alias.
+func (r *VulnReport) ApplyVEX(vex models.VEX) {
+	for i, v := range r.Vulnerabilities {
+		if statement, ok := vex.Statement(v.Purl, append([]string{v.ID}, v.Aliases...)...); ok {
+			r.Vulnerabilities[i].Status = statement.Status
+			r.Vulnerabilities[i].Justification = models.FirstNonEmpty(statement.Justification, statement.ImpactStatement)
+		}
+	}
+}
+
+// ExportVEX reads the provided CycloneDX or KissBOM file and saves an OpenVEX document
+// containing the statements of the YAML statements file, keyed by the PURLs of the
+// packages they apply to.
+func (c *Converter) ExportVEX(filename string, statementsFile string) error {
+	data, err := c.Afs.ReadFile(statementsFile)
+	if err != nil {
+		return err
+	}
+	vex, err := models.ParseVEX(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", statementsFile, err)
+	}
+
+	kissbom, err := c.load(filename)
+	if err != nil {
+		return err
+	}
+	c.Duplicates = kissbom.Deduplicate()
+
+	now := time.Now()
+	document, err := vex.OpenVEX(kissbom, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	c.OutputFileName = path.Join(c.OutputFolder, fmt.Sprintf("vex_%s.openvex.json", now.Format("20060102150405")))
+	log.Printf("saved: %v", c.OutputFileName)
+	return c.Afs.WriteFile(c.OutputFileName, document, 0644)
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+const testVEX = `
+statements:
+  - vulnerability: CVE-2020-8203
+    products: [pkg:npm/lodash]
+    status: not_affected
+    justification: vulnerable_code_not_in_execute_path
+`
+
+func TestVulnReport_ApplyVEX(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, afs.WriteFile("vex.yaml", []byte(testVEX), 0644))
+
+	vex, err := LoadVEX(afs, "vex.yaml")
+	assert.NoError(t, err)
+
+	report := VulnReport{
+		Vulnerabilities: []Vulnerability{
+			{Purl: "pkg:npm/lodash@4.17.15", ID: "GHSA-p6mc-m468-83gw", Aliases: []string{"CVE-2020-8203"}, Severity: SeverityHigh},
+			{Purl: "pkg:npm/minimist@1.2.0", ID: "CVE-2020-8203", Severity: SeverityLow},
+		},
+	}
+	report.ApplyVEX(vex)
+	assert.Equal(t, models.VEXNotAffected, report.Vulnerabilities[0].Status)
+	assert.Equal(t, "vulnerable_code_not_in_execute_path", report.Vulnerabilities[0].Justification)
+	assert.Empty(t, report.Vulnerabilities[1].Status)
+
+	data, err := report.SARIF()
+	assert.NoError(t, err)
+	var sarif sarifLog
+	assert.NoError(t, json.Unmarshal(data, &sarif))
+	assert.Equal(t, []sarifSuppression{{Kind: "external", Justification: "vulnerable_code_not_in_execute_path"}}, sarif.Runs[0].Results[0].Suppressions)
+	assert.Empty(t, sarif.Runs[0].Results[1].Suppressions)
+
+	_, err = LoadVEX(afs, "missing.yaml")
+	assert.Error(t, err)
+}
+
+func TestConverter_ExportVEX(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	assert.NoError(t, converter.Afs.WriteFile("vex.yaml", []byte(testVEX), 0644))
+	assert.NoError(t, converter.Afs.WriteFile("a.cdx.json", []byte(mergeCycloneDX), 0644))
+
+	err := converter.ExportVEX("a.cdx.json", "vex.yaml")
+	assert.NoError(t, err)
+	assert.Contains(t, converter.OutputFileName, ".openvex.json")
+
+	data, err := converter.Afs.ReadFile(converter.OutputFileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"@id": "pkg:npm/lodash@4.17.21"`)
+	assert.NotContains(t, string(data), "express")
+}
@@ -0,0 +1,556 @@
+package lib
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/spf13/afero"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+// Enumeration of vulnerability severities, from least to most severe.
+const (
+	SeverityUnknown  = "unknown"  // SeverityUnknown is for advisories that do not provide a severity.
+	SeverityLow      = "low"      // SeverityLow is for low severity advisories.
+	SeverityMedium   = "medium"   // SeverityMedium is for medium (moderate) severity advisories.
+	SeverityHigh     = "high"     // SeverityHigh is for high severity advisories.
+	SeverityCritical = "critical" // SeverityCritical is for critical severity advisories.
+)
+
+// osvEcosystems maps PURL types to OSV ecosystems. Distribution packages (deb, apk)
+// use the PURL namespace to determine the ecosystem instead.
+var osvEcosystems = map[string]string{
+	packageurl.TypeNPM:      "npm",
+	packageurl.TypePyPi:     "PyPI",
+	packageurl.TypeMaven:    "Maven",
+	packageurl.TypeGolang:   "Go",
+	packageurl.TypeCargo:    "crates.io",
+	packageurl.TypeNuget:    "NuGet",
+	packageurl.TypeGem:      "RubyGems",
+	packageurl.TypeComposer: "Packagist",
+	packageurl.TypeHex:      "Hex",
+	packageurl.TypePub:      "Pub",
+	packageurl.TypeSwift:    "SwiftURL",
+	packageurl.TypeHackage:  "Hackage",
+	packageurl.TypeCran:     "CRAN",
+}
+
+// osvDistributions maps the namespaces of deb and apk PURLs to OSV ecosystems.
+var osvDistributions = map[string]string{
+	"debian": "Debian",
+	"ubuntu": "Ubuntu",
+	"alpine": "Alpine",
+}
+
+// pypiSeparators matches the runs of separators that PEP 503 normalizes to "-".
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// osvAdvisory is the subset of the OSV schema used for matching.
+// See https://ossf.github.io/osv-schema/
+type osvAdvisory struct {
+	ID               string        `json:"id"`
+	Summary          string        `json:"summary"`
+	Details          string        `json:"details"`
+	Aliases          []string      `json:"aliases"`
+	Withdrawn        string        `json:"withdrawn"`
+	Affected         []osvAffected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []osvRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// version returns the version the event refers to.
+func (e osvEvent) version() string {
+	return models.FirstNonEmpty(e.Introduced, e.Fixed, e.LastAffected, e.Limit)
+}
+
+// osvEntry is a single affected package of an advisory.
+type osvEntry struct {
+	advisory *osvAdvisory
+	affected *osvAffected
+}
+
+// VulnDatabase is an in-memory index of OSV advisories, keyed by ecosystem and
+// package name.
+type VulnDatabase struct {
+	Advisories int // Number of advisories loaded.
+	entries    map[string][]osvEntry
+}
+
+// Vulnerability is an advisory that affects a package.
+type Vulnerability struct {
+	Purl          string   `json:"purl"`
+	ID            string   `json:"id"`
+	Aliases       []string `json:"aliases,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	Severity      string   `json:"severity"`
+	Fixed         []string `json:"fixed,omitempty"`
+	Status        string   `json:"status,omitempty"`
+	Justification string   `json:"justification,omitempty"`
+}
+
+// VulnReport contains the vulnerabilities found in the packages of a KissBOM.
+type VulnReport struct {
+	Source          string          `json:"source"`
+	Packages        int             `json:"packages"`
+	Skipped         int             `json:"skipped"`
+	Vulnerable      int             `json:"vulnerable"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// LoadVulnDatabase reads the advisories of one or more OSV export zip files, as
+// downloaded from https://osv-vulnerabilities.storage.googleapis.com/<ecosystem>/all.zip
+func LoadVulnDatabase(afs *afero.Afero, filenames ...string) (db *VulnDatabase, err error) {
+	db = &VulnDatabase{entries: map[string][]osvEntry{}}
+	for _, filename := range filenames {
+		if err = db.loadZip(afs, filename); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	return db, nil
+}
+
+// loadZip adds the advisories of a single OSV export zip file to the database.
+func (db *VulnDatabase) loadZip(afs *afero.Afero, filename string) error {
+	data, err := afs.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || path.Ext(file.Name) != ".json" {
+			continue
+		}
+		advisory, err := readAdvisory(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+		db.add(advisory)
+	}
+	log.Printf("loaded %v advisories from %s", db.Advisories, filename)
+	return nil
+}
+
+// readAdvisory decodes a single advisory of an OSV export.
+func readAdvisory(file *zip.File) (advisory *osvAdvisory, err error) {
+	reader, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return
+	}
+	advisory = &osvAdvisory{}
+	err = json.Unmarshal(data, advisory)
+	return
+}
+
+// add indexes every affected package of the advisory. Withdrawn advisories are ignored.
+func (db *VulnDatabase) add(advisory *osvAdvisory) {
+	if advisory.Withdrawn != "" {
+		return
+	}
+	db.Advisories++
+	for i := range advisory.Affected {
+		affected := &advisory.Affected[i]
+		ecosystem, _, _ := strings.Cut(affected.Package.Ecosystem, ":")
+		key := osvKey(ecosystem, affected.Package.Name)
+		db.entries[key] = append(db.entries[key], osvEntry{advisory: advisory, affected: affected})
+	}
+}
+
+// osvKey builds the index key for a package, matching names without regard to case
+// and normalizing PyPI names following PEP 503.
+func osvKey(ecosystem string, name string) string {
+	name = strings.ToLower(name)
+	if ecosystem == "PyPI" {
+		name = pypiSeparators.ReplaceAllString(name, "-")
+	}
+	return ecosystem + "/" + name
+}
+
+// osvPackage determines the OSV ecosystem, package name and version of a PURL.
+// Returns false if the PURL cannot be parsed, has no version or its type is not
+// supported by OSV.
+func osvPackage(purl string) (ecosystem string, name string, version string, distro string, ok bool) {
+	p, err := packageurl.FromString(strings.TrimSpace(purl))
+	if err != nil || p.Version == "" {
+		return
+	}
+	name = p.Name
+	switch p.Type {
+	case packageurl.TypeDebian, packageurl.TypeApk:
+		ecosystem = osvDistributions[strings.ToLower(p.Namespace)]
+		distro = p.Qualifiers.Map()["distro"]
+	case packageurl.TypeMaven:
+		ecosystem = osvEcosystems[p.Type]
+		name = p.Namespace + ":" + p.Name
+	default:
+		ecosystem = osvEcosystems[p.Type]
+		if p.Namespace != "" {
+			name = p.Namespace + "/" + p.Name
+		}
+	}
+	return ecosystem, name, p.Version, distro, ecosystem != ""
+}
+
+// Match returns the advisories that affect the package identified by the PURL.
+func (db *VulnDatabase) Match(purl string) (vulnerabilities []Vulnerability, supported bool) {
+	ecosystem, name, version, distro, ok := osvPackage(purl)
+	if !ok {
+		return nil, false
+	}
+
+	seen := map[string]int{}
+	for _, entry := range db.entries[osvKey(ecosystem, name)] {
+		if !distroMatches(entry.affected.Package.Ecosystem, distro) {
+			continue
+		}
+		affected, fixed := entry.affected.affects(entry.affected.Package.Ecosystem, version)
+		if !affected {
+			continue
+		}
+		i, ok := seen[entry.advisory.ID]
+		if !ok {
+			i = len(vulnerabilities)
+			seen[entry.advisory.ID] = i
+			vulnerabilities = append(vulnerabilities, Vulnerability{
+				Purl:     purl,
+				ID:       entry.advisory.ID,
+				Aliases:  entry.advisory.Aliases,
+				Summary:  models.FirstNonEmpty(entry.advisory.Summary, firstLine(entry.advisory.Details)),
+				Severity: normalizeSeverity(entry.advisory.DatabaseSpecific.Severity),
+			})
+		}
+		for _, f := range fixed {
+			vulnerabilities[i].Fixed = appendUnique(vulnerabilities[i].Fixed, f)
+		}
+	}
+	return vulnerabilities, true
+}
+
+// distroMatches returns true if an advisory for the OSV ecosystem (ex: Debian:12)
+// applies to the distro qualifier of a PURL (ex: debian-12). Advisories without a
+// release and PURLs without a distro qualifier always match.
+func distroMatches(ecosystem string, distro string) bool {
+	_, release, ok := strings.Cut(ecosystem, ":")
+	if !ok || distro == "" {
+		return true
+	}
+	release, _, _ = strings.Cut(release, ":")
+	return strings.Contains(strings.ToLower(distro), strings.TrimPrefix(strings.ToLower(release), "v"))
+}
+
+// affects returns true if the version is affected, either because it is listed
+// explicitly or because it falls within one of the ranges, along with the versions
+// that fix the affected ranges.
+func (a *osvAffected) affects(ecosystem string, version string) (affected bool, fixed []string) {
+	for _, v := range a.Versions {
+		if v == version || compareVersions(ecosystem, v, version) == 0 {
+			affected = true
+			break
+		}
+	}
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		compare := func(x string, y string) int {
+			if r.Type == "SEMVER" {
+				return compareSemver(x, y)
+			}
+			return compareVersions(ecosystem, x, y)
+		}
+		if r.affects(version, compare) {
+			affected = true
+			for _, e := range r.Events {
+				if e.Fixed != "" && compare(e.Fixed, version) > 0 {
+					fixed = append(fixed, e.Fixed)
+				}
+			}
+		}
+	}
+	return
+}
+
+// affects evaluates the events of the range in version order, following the OSV
+// specification, where "0" introduces a range starting at the lowest version.
+func (r osvRange) affects(version string, compare func(string, string) int) bool {
+	events := slices.Clone(r.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		x, y := events[i].version(), events[j].version()
+		switch {
+		case x == "0":
+			return y != "0"
+		case y == "0":
+			return false
+		}
+		return compare(x, y) < 0
+	})
+
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || compare(version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if compare(version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if compare(version, e.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+// normalizeSeverity converts the severity of an advisory (ex: GitHub's MODERATE)
+// to one of the kissbom severities.
+func normalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "low":
+		return SeverityLow
+	case "moderate", "medium":
+		return SeverityMedium
+	case "high":
+		return SeverityHigh
+	case "critical":
+		return SeverityCritical
+	}
+	return SeverityUnknown
+}
+
+// firstLine returns the first non-empty line of the text.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Scan matches every package of the KissBOM against the database. Packages
+// without a version or with a PURL type that OSV does not support are skipped.
+func (db *VulnDatabase) Scan(kissbom models.KissBOM) (report VulnReport) {
+	report.Vulnerabilities = []Vulnerability{}
+	for _, pkg := range kissbom.Packages {
+		report.Packages++
+		vulnerabilities, supported := db.Match(pkg.Purl)
+		if !supported {
+			log.Printf("skipped: %s", pkg.Purl)
+			report.Skipped++
+			continue
+		}
+		if len(vulnerabilities) > 0 {
+			report.Vulnerable++
+		}
+		report.Vulnerabilities = append(report.Vulnerabilities, vulnerabilities...)
+	}
+	return
+}
+
+// Table converts the vulnerability report to a plain text table.
+func (r VulnReport) Table() ([]byte, error) {
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PURL\tID\tSEVERITY\tFIXED\tSTATUS\tSUMMARY")
+	for _, v := range r.Vulnerabilities {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", v.Purl, v.ID, v.Severity, strings.Join(v.Fixed, ", "), models.FirstNonEmpty(v.Status, "-"), v.Summary)
+	}
+	err := w.Flush()
+	return buffer.Bytes(), err
+}
+
+// JSON converts the vulnerability report to JSON format
+func (r VulnReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "    ")
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri"`
+	Properties       struct {
+		Severity string `json:"severity"`
+	} `json:"properties"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifLevels maps severities to SARIF result levels.
+var sarifLevels = map[string]string{
+	SeverityCritical: "error",
+	SeverityHigh:     "error",
+	SeverityMedium:   "warning",
+	SeverityLow:      "note",
+	SeverityUnknown:  "warning",
+}
+
+// SARIF converts the vulnerability report to SARIF 2.1.0, with one rule per
+// advisory and one result per affected package. Results with a VEX status of
+// not_affected or fixed are reported as suppressed.
+func (r VulnReport) SARIF() ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "kissbom",
+			InformationURI: "https://github.com/devops-kung-fu/kissbom",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := map[string]bool{}
+	for _, v := range r.Vulnerabilities {
+		if !rules[v.ID] {
+			rules[v.ID] = true
+			rule := sarifRule{
+				ID:               v.ID,
+				ShortDescription: sarifMessage{Text: models.FirstNonEmpty(v.Summary, v.ID)},
+				HelpURI:          fmt.Sprintf("https://osv.dev/vulnerability/%s", v.ID),
+			}
+			rule.Properties.Severity = v.Severity
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		message := fmt.Sprintf("%s is affected by %s (%s)", v.Purl, v.ID, v.Severity)
+		if len(v.Fixed) > 0 {
+			message += fmt.Sprintf(", fixed in %s", strings.Join(v.Fixed, ", "))
+		}
+		location := sarifLocation{}
+		location.PhysicalLocation.ArtifactLocation.URI = r.Source
+		location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: v.Purl}}
+		result := sarifResult{
+			RuleID:    v.ID,
+			Level:     sarifLevels[v.Severity],
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{location},
+		}
+		if v.Status == models.VEXNotAffected || v.Status == models.VEXFixed {
+			result.Suppressions = []sarifSuppression{{Kind: "external", Justification: models.FirstNonEmpty(v.Justification, v.Status)}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "    ")
+}
+
+// ScanVulnerabilities reads the provided CycloneDX or KissBOM file and matches every
+// package against the advisories of the OSV export zip files, without network access.
+func (c *Converter) ScanVulnerabilities(filename string, databases []string) (report VulnReport, err error) {
+	if len(databases) == 0 {
+		err = fmt.Errorf("no vulnerability database provided")
+		return
+	}
+
+	db, err := LoadVulnDatabase(c.Afs, databases...)
+	if err != nil {
+		return
+	}
+
+	kissbom, err := c.load(filename)
+	if err != nil {
+		return
+	}
+	c.Duplicates = kissbom.Deduplicate()
+
+	report = db.Scan(kissbom)
+	report.Source = filename
+	report.ApplyVEX(c.VEX)
+	return
+}
@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devops-kung-fu/kissbom/models"
+)
+
+var testAdvisories = map[string]string{
+	"GHSA-lodash.json": `{
+		"id": "GHSA-lodash",
+		"summary": "Prototype pollution in lodash",
+		"aliases": ["CVE-2020-8203"],
+		"database_specific": {"severity": "HIGH"},
+		"affected": [{
+			"package": {"ecosystem": "npm", "name": "lodash"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.19"}]}]
+		}]
+	}`,
+	"GHSA-requests.json": `{
+		"id": "GHSA-requests",
+		"details": "Proxy-Authorization header leak.\nMore details.",
+		"database_specific": {"severity": "MODERATE"},
+		"affected": [{
+			"package": {"ecosystem": "PyPI", "name": "Requests"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}]
+		}]
+	}`,
+	"GHSA-log4j.json": `{
+		"id": "GHSA-log4j",
+		"summary": "Log4Shell",
+		"database_specific": {"severity": "CRITICAL"},
+		"affected": [{
+			"package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-core"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [
+				{"introduced": "2.13.0"}, {"fixed": "2.15.0"},
+				{"introduced": "2.0-beta9"}, {"fixed": "2.12.2"}
+			]}]
+		}]
+	}`,
+	"DSA-openssl.json": `{
+		"id": "DSA-openssl",
+		"affected": [{
+			"package": {"ecosystem": "Debian:12", "name": "openssl"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"last_affected": "3.0.11-1~deb12u1"}]}]
+		}]
+	}`,
+	"GHSA-withdrawn.json": `{
+		"id": "GHSA-withdrawn",
+		"withdrawn": "2023-01-01T00:00:00Z",
+		"affected": [{
+			"package": {"ecosystem": "npm", "name": "lodash"},
+			"versions": ["4.17.15"]
+		}]
+	}`,
+}
+
+// writeTestDatabase saves the test advisories as an OSV export zip file.
+func writeTestDatabase(t *testing.T, afs *afero.Afero, filename string) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for name, advisory := range testAdvisories {
+		w, err := archive.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(advisory))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, archive.Close())
+	assert.NoError(t, afs.WriteFile(filename, buffer.Bytes(), 0644))
+}
+
+func TestVulnDatabase_Scan(t *testing.T) {
+	afs := &afero.Afero{Fs: afero.NewMemMapFs()}
+	writeTestDatabase(t, afs, "all.zip")
+
+	db, err := LoadVulnDatabase(afs, "all.zip")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, db.Advisories)
+
+	kissbom := models.KissBOM{
+		Packages: []models.Package{
+			{Purl: "pkg:npm/lodash@4.17.15"},
+			{Purl: "pkg:npm/lodash@4.17.21"},
+			{Purl: "pkg:pypi/requests@2.26.0"},
+			{Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+			{Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.12.4"},
+			{Purl: "pkg:deb/debian/openssl@3.0.9-1?distro=debian-12"},
+			{Purl: "pkg:deb/debian/openssl@3.0.9-1?distro=debian-11"},
+			{Purl: "pkg:npm/express"},
+			{Purl: "pkg:generic/thing@1.0.0"},
+		},
+	}
+
+	report := db.Scan(kissbom)
+	assert.Equal(t, 9, report.Packages)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 4, report.Vulnerable)
+
+	found := map[string]string{}
+	for _, v := range report.Vulnerabilities {
+		found[v.Purl] = v.ID
+	}
+	assert.Equal(t, map[string]string{
+		"pkg:npm/lodash@4.17.15":                               "GHSA-lodash",
+		"pkg:pypi/requests@2.26.0":                             "GHSA-requests",
+		"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1": "GHSA-log4j",
+		"pkg:deb/debian/openssl@3.0.9-1?distro=debian-12":      "DSA-openssl",
+	}, found)
+
+	assert.Equal(t, SeverityHigh, report.Vulnerabilities[0].Severity)
+	assert.Equal(t, []string{"4.17.19"}, report.Vulnerabilities[0].Fixed)
+	assert.Equal(t, "Proxy-Authorization header leak.", report.Vulnerabilities[1].Summary)
+	assert.Equal(t, SeverityMedium, report.Vulnerabilities[1].Severity)
+	assert.Equal(t, []string{"2.15.0"}, report.Vulnerabilities[2].Fixed)
+	assert.Equal(t, SeverityUnknown, report.Vulnerabilities[3].Severity)
+}
+
+func TestVulnReport_Outputs(t *testing.T) {
+	report := VulnReport{
+		Source:   "test.json",
+		Packages: 2,
+		Vulnerabilities: []Vulnerability{
+			{Purl: "pkg:npm/lodash@4.17.15", ID: "GHSA-lodash", Summary: "Prototype pollution", Severity: SeverityHigh, Fixed: []string{"4.17.19"}},
+			{Purl: "pkg:npm/minimist@1.2.0", ID: "GHSA-minimist", Severity: SeverityLow},
+		},
+	}
+
+	table, err := report.Table()
+	assert.NoError(t, err)
+	assert.Contains(t, string(table), "pkg:npm/lodash@4.17.15  GHSA-lodash    high      4.17.19  -       Prototype pollution")
+
+	data, err := report.SARIF()
+	assert.NoError(t, err)
+	var sarif sarifLog
+	assert.NoError(t, json.Unmarshal(data, &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	assert.Len(t, sarif.Runs[0].Tool.Driver.Rules, 2)
+	assert.Equal(t, "error", sarif.Runs[0].Results[0].Level)
+	assert.Equal(t, "note", sarif.Runs[0].Results[1].Level)
+	assert.Equal(t, "pkg:npm/lodash@4.17.15 is affected by GHSA-lodash (high), fixed in 4.17.19", sarif.Runs[0].Results[0].Message.Text)
+	assert.Equal(t, "test.json", sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestConverter_ScanVulnerabilities(t *testing.T) {
+	converter := NewConverter()
+	converter.Afs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	writeTestDatabase(t, converter.Afs, "all.zip")
+	assert.NoError(t, converter.Afs.WriteFile("b.json", []byte(mergeKissBOM), 0644))
+
+	_, err := converter.ScanVulnerabilities("b.json", nil)
+	assert.Error(t, err)
+
+	_, err = converter.ScanVulnerabilities("b.json", []string{"missing.zip"})
+	assert.Error(t, err)
+
+	report, err := converter.ScanVulnerabilities("b.json", []string{"all.zip"})
+	assert.NoError(t, err)
+	assert.Equal(t, "b.json", report.Source)
+	assert.Equal(t, 1, report.Vulnerable)
+}
@@ -0,0 +1,193 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+	"gopkg.in/yaml.v3"
+)
+
+// yarnBerryMetadata matches the __metadata entry that yarn berry lockfiles start with.
+var yarnBerryMetadata = regexp.MustCompile(`(?m)^__metadata:`)
+
+// yarnEntry is a package of a yarn lockfile, along with the descriptors (ex: lodash@^4.17.0)
+// that resolve to it.
+type yarnEntry struct {
+	Descriptors  []string
+	Name         string
+	Version      string
+	Integrity    string
+	Dependencies map[string]string // Version ranges of the dependencies, keyed by name.
+	Local        bool              // True for workspaces and linked directories.
+	Root         bool              // True for the workspace of the project itself.
+}
+
+// yarnBerryEntry is a package of a yarn berry lockfile.
+type yarnBerryEntry struct {
+	Version      string            `yaml:"version"`
+	Resolution   string            `yaml:"resolution"`
+	Dependencies map[string]string `yaml:"dependencies"`
+}
+
+// parseYarnLock parses a yarn classic or berry lockfile. Packages are identified by
+// name and version. Since yarn does not flag development packages, they are found from
+// the development dependencies of the manifest.
+func parseYarnLock(source []byte, manifest npmManifest) (*lockfile, error) {
+	var entries []*yarnEntry
+	var err error
+	if yarnBerryMetadata.Match(source) {
+		entries, err = parseYarnBerry(source)
+	} else {
+		entries, err = parseYarnClassic(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := newLockfile(packageurl.TypeNPM)
+	descriptors := map[string]string{}
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.Name + "@" + e.Version
+		l.Packages[ids[i]] = &lockPackage{Name: e.Name, Version: e.Version, Integrity: e.Integrity, Local: e.Local}
+		for _, d := range e.Descriptors {
+			descriptors[d] = ids[i]
+		}
+	}
+	resolve := func(name string, version string) string {
+		for _, d := range []string{name + "@" + version, name + "@npm:" + version} {
+			if id, ok := descriptors[d]; ok {
+				return id
+			}
+		}
+		return ""
+	}
+
+	root := false
+	for i, e := range entries {
+		for name, version := range e.Dependencies {
+			id := resolve(name, version)
+			switch {
+			case id == "":
+			case e.Root:
+				_, regular := manifest.Dependencies[name]
+				_, dev := manifest.DevDependencies[name]
+				l.addDirect(id, dev && !regular)
+			default:
+				l.Dependencies[ids[i]] = append(l.Dependencies[ids[i]], id)
+			}
+		}
+		root = root || e.Root
+	}
+	if !root {
+		l.addManifestDependencies(manifest, resolve)
+	}
+	l.markDev()
+	return l, nil
+}
+
+// parseYarnClassic parses a yarn classic lockfile, whose entries are keyed by their
+// comma separated descriptors, with indented fields and dependencies. Packages installed
+// under an alias (ex: string-width-cjs@npm:string-width@^4.2.0) take the name of the
+// package they alias.
+func parseYarnClassic(source []byte) (entries []*yarnEntry, err error) {
+	var current *yarnEntry
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, _ := strings.Cut(trimmed, " ")
+		key, value = yarnUnquote(key), yarnUnquote(value)
+
+		switch indent := len(text) - len(strings.TrimLeft(text, " ")); {
+		case indent == 0:
+			current = &yarnEntry{Dependencies: map[string]string{}}
+			for _, d := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				current.Descriptors = append(current.Descriptors, yarnUnquote(strings.TrimSpace(d)))
+			}
+			name := yarnDescriptorName(current.Descriptors[0])
+			version := strings.TrimPrefix(current.Descriptors[0], name+"@")
+			current.Name, _ = npmAlias(name, version)
+			current.Local = yarnLocalProtocol(version)
+			entries = append(entries, current)
+			section = ""
+		case current == nil:
+			return nil, fmt.Errorf("line %v: unexpected indentation", line)
+		case indent <= 2 && value == "" && strings.HasSuffix(key, ":"):
+			section = strings.TrimSuffix(key, ":")
+		case indent <= 2:
+			section = ""
+			switch key {
+			case "version":
+				current.Version = value
+			case "integrity":
+				current.Integrity = value
+			}
+		case section == "dependencies" || section == "optionalDependencies":
+			current.Dependencies[key] = value
+		}
+	}
+	return
+}
+
+// parseYarnBerry parses a yarn berry lockfile, which is a YAML document keyed by the
+// comma separated descriptors of each package. Packages resolved from the npm registry,
+// or patched, are kept, while other protocols such as workspaces are local.
+func parseYarnBerry(source []byte) (entries []*yarnEntry, err error) {
+	var lock map[string]yarnBerryEntry
+	if err = yaml.Unmarshal(source, &lock); err != nil {
+		return
+	}
+	for key, e := range lock {
+		if key == "__metadata" {
+			continue
+		}
+		entry := &yarnEntry{Name: yarnDescriptorName(e.Resolution), Version: e.Version, Dependencies: e.Dependencies}
+		for _, d := range strings.Split(key, ",") {
+			entry.Descriptors = append(entry.Descriptors, strings.TrimSpace(d))
+		}
+		protocol := strings.TrimPrefix(e.Resolution, entry.Name+"@")
+		entry.Local = !strings.HasPrefix(protocol, "npm:") && !strings.HasPrefix(protocol, "patch:")
+		entry.Root = protocol == "workspace:."
+		entries = append(entries, entry)
+	}
+	return
+}
+
+// yarnDescriptorName returns the package name of a descriptor or resolution, which may
+// be scoped (ex: @babel/core@npm:^7.0.0 is @babel/core).
+func yarnDescriptorName(descriptor string) string {
+	if len(descriptor) > 1 {
+		if i := strings.Index(descriptor[1:], "@"); i >= 0 {
+			return descriptor[:i+1]
+		}
+	}
+	return descriptor
+}
+
+// yarnLocalProtocol returns true if a version range refers to a local directory.
+func yarnLocalProtocol(version string) bool {
+	for _, protocol := range []string{"file:", "link:", "portal:", "workspace:"} {
+		if strings.HasPrefix(version, protocol) {
+			return true
+		}
+	}
+	return false
+}
+
+// yarnUnquote removes the quotes around a yarn classic value, if any.
+func yarnUnquote(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
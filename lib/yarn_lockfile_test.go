@@ -0,0 +1,180 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testYarnClassic = `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.24.0"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.24.0.tgz#abc"
+  integrity sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8=
+  dependencies:
+    debug "^4.1.0"
+
+debug@^4.1.0:
+  version "4.3.4"
+  dependencies:
+    ms "2.1.2"
+
+jest@^29.0.0:
+  version "29.7.0"
+  dependencies:
+    ms "^2.0.0"
+  optionalDependencies:
+    fsevents "^2.3.2"
+
+ms@2.1.2:
+  version "2.1.2"
+
+ms@^2.0.0:
+  version "2.0.0"
+
+fsevents@^2.3.2:
+  version "2.3.3"
+
+"lib@file:./packages/lib":
+  version "0.1.0"
+`
+
+const testYarnBerry = `# This file is generated by running "yarn install" inside your project.
+
+__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"@babel/core@npm:^7.0.0":
+  version: 7.24.0
+  resolution: "@babel/core@npm:7.24.0"
+  dependencies:
+    debug: "npm:^4.1.0"
+  checksum: 10c0/abc
+  languageName: node
+  linkType: hard
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+  dependencies:
+    "@babel/core": "npm:^7.0.0"
+    jest: ^29.0.0
+    lib: "workspace:^"
+  languageName: unknown
+  linkType: soft
+
+"debug@npm:^4.1.0":
+  version: 4.3.4
+  resolution: "debug@npm:4.3.4"
+  dependencies:
+    ms: "npm:2.1.2"
+  languageName: node
+  linkType: hard
+
+"jest@npm:^29.0.0":
+  version: 29.7.0
+  resolution: "jest@npm:29.7.0"
+  languageName: node
+  linkType: hard
+
+"lib@workspace:^, lib@workspace:packages/lib":
+  version: 0.0.0-use.local
+  resolution: "lib@workspace:packages/lib"
+  dependencies:
+    ms: "npm:2.1.2"
+  languageName: unknown
+  linkType: soft
+
+"ms@npm:2.1.2":
+  version: 2.1.2
+  resolution: "ms@npm:2.1.2"
+  languageName: node
+  linkType: hard
+
+"resolve@patch:resolve@npm%3A^1.22.0#~builtin<compat/resolve>":
+  version: 1.22.8
+  resolution: "resolve@patch:resolve@npm%3A1.22.8#~builtin<compat/resolve>::version=1.22.8&hash=c3c19d"
+  languageName: node
+  linkType: hard
+`
+
+func TestParseYarnLock_Classic(t *testing.T) {
+	manifest := npmManifest{
+		Dependencies:    map[string]string{"@babel/core": "^7.1.0", "lib": "file:./packages/lib"},
+		DevDependencies: map[string]string{"jest": "^29.0.0"},
+	}
+	l, err := parseYarnLock([]byte(testYarnClassic), manifest)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &lockPackage{Name: "@babel/core", Version: "7.24.0", Integrity: "sha1-qUr1Sg6f8bLcPL9z19CQrkNCeG8="}, l.Packages["@babel/core@7.24.0"])
+	assert.True(t, l.Packages["lib@0.1.0"].Local)
+	assert.Equal(t, []string{"debug@4.3.4"}, l.Dependencies["@babel/core@7.24.0"])
+	assert.ElementsMatch(t, []string{"ms@2.0.0", "fsevents@2.3.3"}, l.Dependencies["jest@29.7.0"])
+	assert.ElementsMatch(t, []string{"@babel/core@7.24.0", "lib@0.1.0", "jest@29.7.0"}, l.Dependencies[""])
+
+	assert.False(t, l.Packages["ms@2.1.2"].Dev)
+	assert.True(t, l.Packages["jest@29.7.0"].Dev)
+	assert.True(t, l.Packages["ms@2.0.0"].Dev)
+	assert.True(t, l.Packages["fsevents@2.3.3"].Dev)
+}
+
+func TestParseYarnLock_ClassicWithoutManifest(t *testing.T) {
+	l, err := parseYarnLock([]byte(testYarnClassic), npmManifest{})
+	assert.NoError(t, err)
+	assert.Len(t, l.Packages, 7)
+	assert.Empty(t, l.Dependencies[""])
+	assert.False(t, l.Packages["jest@29.7.0"].Dev)
+}
+
+func TestParseYarnLock_ClassicAlias(t *testing.T) {
+	l, err := parseYarnLock([]byte(`"string-width-cjs@npm:string-width@^4.2.0":
+  version "4.2.3"
+  resolved "https://registry.yarnpkg.com/string-width/-/string-width-4.2.3.tgz#269c7117"
+
+cliui@^8.0.1:
+  version "8.0.1"
+  dependencies:
+    string-width-cjs "npm:string-width@^4.2.0"
+`), npmManifest{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, &lockPackage{Name: "string-width", Version: "4.2.3"}, l.Packages["string-width@4.2.3"])
+	assert.NotContains(t, l.Packages, "string-width-cjs@4.2.3")
+	assert.Equal(t, []string{"string-width@4.2.3"}, l.Dependencies["cliui@8.0.1"])
+}
+
+func TestParseYarnLock_Berry(t *testing.T) {
+	manifest := npmManifest{DevDependencies: map[string]string{"jest": "^29.0.0"}}
+	l, err := parseYarnLock([]byte(testYarnBerry), manifest)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &lockPackage{Name: "@babel/core", Version: "7.24.0"}, l.Packages["@babel/core@7.24.0"])
+	assert.Equal(t, &lockPackage{Name: "resolve", Version: "1.22.8"}, l.Packages["resolve@1.22.8"])
+	assert.True(t, l.Packages["app@0.0.0-use.local"].Local)
+	assert.True(t, l.Packages["lib@0.0.0-use.local"].Local)
+
+	assert.ElementsMatch(t, []string{"@babel/core@7.24.0", "jest@29.7.0", "lib@0.0.0-use.local"}, l.Dependencies[""])
+	assert.Equal(t, []string{"ms@2.1.2"}, l.Dependencies["lib@0.0.0-use.local"])
+	assert.Equal(t, []string{"ms@2.1.2"}, l.Dependencies["debug@4.3.4"])
+	assert.True(t, l.Packages["jest@29.7.0"].Dev)
+	assert.False(t, l.Packages["ms@2.1.2"].Dev)
+}
+
+func TestParseYarnLock_Errors(t *testing.T) {
+	_, err := parseYarnLock([]byte("  version \"1.0.0\"\n"), npmManifest{})
+	assert.EqualError(t, err, "line 1: unexpected indentation")
+
+	_, err = parseYarnLock([]byte("__metadata:\n  version: [\n"), npmManifest{})
+	assert.Error(t, err)
+}
+
+func TestYarnDescriptorName(t *testing.T) {
+	assert.Equal(t, "@babel/core", yarnDescriptorName("@babel/core@npm:^7.0.0"))
+	assert.Equal(t, "lodash", yarnDescriptorName("lodash@^4.17.21"))
+	assert.Equal(t, "resolve", yarnDescriptorName("resolve@patch:resolve@npm%3A1.22.8"))
+	assert.Equal(t, "lodash", yarnDescriptorName("lodash"))
+}
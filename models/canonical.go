@@ -0,0 +1,84 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// Sort orders the packages of the KissBOM canonically by PURL type, namespace, name
+// and version, using the canonical PURL to break any remaining ties.
+func (k *KissBOM) Sort() {
+	type sortKey struct {
+		typ, namespace, name, version, canonical string
+	}
+	keys := make(map[string]sortKey, len(k.Packages))
+	for _, p := range k.Packages {
+		if _, ok := keys[p.Purl]; ok {
+			continue
+		}
+		key := sortKey{canonical: CanonicalPurl(p.Purl)}
+		if purl, err := packageurl.FromString(key.canonical); err == nil {
+			key.typ, key.namespace, key.name, key.version = purl.Type, purl.Namespace, purl.Name, purl.Version
+		}
+		keys[p.Purl] = key
+	}
+
+	sort.SliceStable(k.Packages, func(i, j int) bool {
+		a, b := keys[k.Packages[i].Purl], keys[k.Packages[j].Purl]
+		switch {
+		case a.typ != b.typ:
+			return a.typ < b.typ
+		case a.namespace != b.namespace:
+			return a.namespace < b.namespace
+		case a.name != b.name:
+			return a.name < b.name
+		case a.version != b.version:
+			return a.version < b.version
+		}
+		return a.canonical < b.canonical
+	})
+}
+
+// canonicalized returns a copy of the KissBOM with canonical PURLs and canonically
+// ordered packages and dependencies. The receiver is left untouched.
+func (k *KissBOM) canonicalized() KissBOM {
+	kissbom := *k
+	kissbom.Dependencies = canonicalDependencies(k.Dependencies)
+	kissbom.Packages = make([]Package, len(k.Packages))
+	for i, p := range k.Packages {
+		p.Purl = CanonicalPurl(p.Purl)
+		kissbom.Packages[i] = p
+	}
+	kissbom.Sort()
+	return kissbom
+}
+
+// canonicalJSON encodes v as compact JSON with lexicographically sorted object keys,
+// no HTML escaping and a trailing newline, so that identical content always yields
+// byte-identical output.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalizeJSON(data)
+}
+
+// canonicalizeJSON re-encodes already marshalled JSON in canonical form.
+func canonicalizeJSON(data []byte) ([]byte, error) {
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(generic)
+	return buf.Bytes(), err
+}
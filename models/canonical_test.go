@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKissBOM_Sort(t *testing.T) {
+	kissBOM := KissBOM{
+		Packages: []Package{
+			{Purl: "pkg:pypi/requests@2.26.0"},
+			{Purl: "pkg:npm/lodash@4.17.21"},
+			{Purl: "pkg:npm/%40babel/core@7.0.0"},
+			{Purl: "pkg:npm/lodash@4.17.20"},
+		},
+	}
+
+	kissBOM.Sort()
+
+	assert.Equal(t, "pkg:npm/lodash@4.17.20", kissBOM.Packages[0].Purl)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", kissBOM.Packages[1].Purl)
+	assert.Equal(t, "pkg:npm/%40babel/core@7.0.0", kissBOM.Packages[2].Purl)
+	assert.Equal(t, "pkg:pypi/requests@2.26.0", kissBOM.Packages[3].Purl)
+}
+
+func TestKissBOM_Canonical(t *testing.T) {
+	a := KissBOM{
+		Canonical: true,
+		Packages: []Package{
+			{Purl: "pkg:pypi/Requests@2.26.0", License: "Apache-2.0"},
+			{Purl: "pkg:npm/lodash@4.17.21", Notes: "<utility> & more"},
+		},
+	}
+	b := KissBOM{
+		Canonical: true,
+		Packages: []Package{
+			{Purl: "pkg:npm/lodash@4.17.21", Notes: "<utility> & more"},
+			{Purl: "pkg:pypi/requests@2.26.0", License: "Apache-2.0"},
+		},
+	}
+
+	encoders := map[string]func(k *KissBOM) ([]byte, error){
+		"json":       (*KissBOM).JSON,
+		"yaml":       (*KissBOM).YAML,
+		"csv":        (*KissBOM).CSV,
+		"minimal":    (*KissBOM).Minimal,
+		"compatible": (*KissBOM).Compatible,
+	}
+	for name, encode := range encoders {
+		first, err := encode(&a)
+		assert.NoError(t, err, name)
+		second, err := encode(&b)
+		assert.NoError(t, err, name)
+		assert.Equal(t, string(first), string(second), name)
+	}
+
+	data, err := a.JSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"packages":[{"notes":"<utility> & more","purl":"pkg:npm/lodash@4.17.21"},{"license":"Apache-2.0","purl":"pkg:pypi/requests@2.26.0"}]}`+"\n", string(data))
+	assert.Equal(t, "pkg:pypi/Requests@2.26.0", a.Packages[0].Purl, "receiver should not be modified")
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	_ "embed"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Enumeration of license categories, from least to most restrictive.
+const (
+	CategoryPublicDomain    = "public-domain"    // CategoryPublicDomain is for licenses that dedicate works to the public domain.
+	CategoryPermissive      = "permissive"       // CategoryPermissive is for licenses that only require attribution.
+	CategoryWeakCopyleft    = "weak-copyleft"    // CategoryWeakCopyleft is for licenses whose copyleft is limited to the licensed files or library.
+	CategoryStrongCopyleft  = "strong-copyleft"  // CategoryStrongCopyleft is for licenses whose copyleft extends to derived works.
+	CategoryNetworkCopyleft = "network-copyleft" // CategoryNetworkCopyleft is for licenses whose copyleft extends to use over a network.
+	CategoryProprietary     = "proprietary"      // CategoryProprietary is for licenses that restrict use, modification or distribution.
+	CategoryUnknown         = "unknown"          // CategoryUnknown is for missing or unrecognized licenses.
+)
+
+// LicenseCategories contains all license categories, from least to most restrictive.
+// Unknown licenses are treated as the most restrictive.
+var LicenseCategories = []string{
+	CategoryPublicDomain,
+	CategoryPermissive,
+	CategoryWeakCopyleft,
+	CategoryStrongCopyleft,
+	CategoryNetworkCopyleft,
+	CategoryProprietary,
+	CategoryUnknown,
+}
+
+//go:embed license_categories.yaml
+var licenseCategoriesYAML []byte
+
+// licenseClassification is the embedded classification table.
+var licenseClassification = loadLicenseClassification()
+
+type classification struct {
+	categories        map[string]string
+	identifiers       map[string]string
+	linkingExceptions map[string]bool
+}
+
+// loadLicenseClassification parses the embedded classification table, keyed by
+// lowercase SPDX identifier, along with the correctly cased identifiers.
+func loadLicenseClassification() (c classification) {
+	var table struct {
+		Categories        map[string][]string `yaml:"categories"`
+		LinkingExceptions []string            `yaml:"linkingExceptions"`
+	}
+	if err := yaml.Unmarshal(licenseCategoriesYAML, &table); err != nil {
+		panic(err)
+	}
+
+	c.categories = map[string]string{}
+	c.identifiers = map[string]string{}
+	for category, ids := range table.Categories {
+		for _, id := range ids {
+			c.categories[strings.ToLower(id)] = category
+			c.identifiers[strings.ToLower(id)] = id
+		}
+	}
+	c.linkingExceptions = map[string]bool{}
+	for _, id := range table.LinkingExceptions {
+		c.linkingExceptions[strings.ToLower(id)] = true
+	}
+	return
+}
+
+// LicenseCategory classifies a license expression, returning the most restrictive
+// category of all of the licenses it contains. Empty or unparseable expressions are
+// classified as CategoryUnknown.
+func LicenseCategory(expression string) string {
+	e, err := ParseLicenseExpression(expression)
+	if err != nil {
+		return CategoryUnknown
+	}
+	rank := 0
+	for _, license := range e.Licenses() {
+		if r := categoryRank(license.Category()); r > rank {
+			rank = r
+		}
+	}
+	return LicenseCategories[rank]
+}
+
+// Category classifies a single license of an expression. Strong copyleft licenses
+// used with a linking exception are classified as CategoryWeakCopyleft.
+func (e *LicenseExpression) Category() string {
+	if e.IsCompound() {
+		return LicenseCategory(e.String())
+	}
+	category, ok := licenseClassification.categories[strings.ToLower(e.ID)]
+	if !ok {
+		return CategoryUnknown
+	}
+	if category == CategoryStrongCopyleft && licenseClassification.linkingExceptions[strings.ToLower(e.Exception)] {
+		return CategoryWeakCopyleft
+	}
+	return category
+}
+
+// categoryRank returns the position of the category in LicenseCategories.
+func categoryRank(category string) int {
+	for i, c := range LicenseCategories {
+		if c == category {
+			return i
+		}
+	}
+	return len(LicenseCategories) - 1
+}
+
+// CategorySummary counts the packages of the KissBOM in each license category.
+func (k *KissBOM) CategorySummary() map[string]int {
+	summary := map[string]int{}
+	for _, p := range k.Packages {
+		summary[LicenseCategory(p.License)]++
+	}
+	return summary
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLicenseCategory(t *testing.T) {
+	tests := map[string]string{
+		"":                          CategoryUnknown,
+		"Apache 2.0":                CategoryUnknown,
+		"CC0-1.0":                   CategoryPublicDomain,
+		"mit":                       CategoryPermissive,
+		"(AFL-2.1 OR BSD-3-Clause)": CategoryPermissive,
+		"MPL-2.0 OR MIT":            CategoryWeakCopyleft,
+		"GPL-2.0+":                  CategoryStrongCopyleft,
+		"GPL-2.0-only WITH Classpath-exception-2.0": CategoryWeakCopyleft,
+		"AGPL-3.0-only AND MIT":                     CategoryNetworkCopyleft,
+		"BUSL-1.1":                                  CategoryProprietary,
+		"MIT AND LicenseRef-Custom":                 CategoryUnknown,
+	}
+	for expression, expected := range tests {
+		assert.Equal(t, expected, LicenseCategory(expression), expression)
+	}
+}
+
+func TestKissBOM_CategorySummary(t *testing.T) {
+	kissBOM := KissBOM{
+		Packages: []Package{
+			{Purl: "pkg:npm/lodash@4.17.21", License: "MIT"},
+			{Purl: "pkg:npm/express@4.18.2", License: "MIT"},
+			{Purl: "pkg:npm/unknown@1.0.0"},
+			{Purl: "pkg:npm/agpl@1.0.0", License: "AGPL-3.0-only"},
+		},
+	}
+
+	assert.Equal(t, map[string]int{
+		CategoryPermissive:      2,
+		CategoryUnknown:         1,
+		CategoryNetworkCopyleft: 1,
+	}, kissBOM.CategorySummary())
+}
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// Enumeration of the SPDX relationship types that describe dependencies.
+const (
+	SPDXDependsOn    = "DEPENDS_ON"    // SPDXDependsOn means the element depends on the related element.
+	SPDXDependencyOf = "DEPENDENCY_OF" // SPDXDependencyOf means the element is a dependency of the related element.
+)
+
+// extractDependencies returns the dependencies of a CycloneDX BOM keyed by PURL, or nil
+// if the BOM has none. References to components without a PURL are dropped.
+func extractDependencies(cdx *cyclonedx.BOM) map[string][]string {
+	if cdx.Dependencies == nil {
+		return nil
+	}
+	purls := map[string]string{}
+	if cdx.Metadata != nil && cdx.Metadata.Component != nil {
+		addBOMRef(purls, *cdx.Metadata.Component)
+	}
+	if cdx.Components != nil {
+		for _, component := range *cdx.Components {
+			addBOMRef(purls, component)
+		}
+	}
+
+	dependencies := map[string][]string{}
+	for _, d := range *cdx.Dependencies {
+		if purls[d.Ref] == "" || d.Dependencies == nil {
+			continue
+		}
+		for _, ref := range *d.Dependencies {
+			if purls[ref] != "" {
+				dependencies[purls[d.Ref]] = append(dependencies[purls[d.Ref]], purls[ref])
+			}
+		}
+	}
+	return normalizeDependencies(dependencies)
+}
+
+// addBOMRef records the PURL of a component under its bom-ref, which defaults to the
+// PURL when the component has none.
+func addBOMRef(purls map[string]string, component cyclonedx.Component) {
+	if component.PackageURL == "" {
+		return
+	}
+	purls[FirstNonEmpty(component.BOMRef, component.PackageURL)] = component.PackageURL
+}
+
+// spdxDependencies returns the DEPENDS_ON and DEPENDENCY_OF relationships of an SPDX
+// document keyed by PURL, or nil if the document has none.
+func spdxDependencies(spdx *SPDXDocument) map[string][]string {
+	purls := map[string]string{}
+	for _, p := range spdx.Packages {
+		if purl := p.Purl(); purl != "" {
+			purls[p.SPDXID] = purl
+		}
+	}
+
+	dependencies := map[string][]string{}
+	for _, r := range spdx.Relationships {
+		from, to := purls[r.Element], purls[r.Related]
+		if from == "" || to == "" {
+			continue
+		}
+		switch r.Type {
+		case SPDXDependsOn:
+			dependencies[from] = append(dependencies[from], to)
+		case SPDXDependencyOf:
+			dependencies[to] = append(dependencies[to], from)
+		}
+	}
+	return normalizeDependencies(dependencies)
+}
+
+// MergeDependencies combines two dependency graphs, unioning the dependencies of PURLs
+// that appear in both.
+func MergeDependencies(existing map[string][]string, incoming map[string][]string) map[string][]string {
+	merged := map[string][]string{}
+	for _, graph := range []map[string][]string{existing, incoming} {
+		for purl, dependencies := range graph {
+			merged[purl] = append(merged[purl], dependencies...)
+		}
+	}
+	return normalizeDependencies(merged)
+}
+
+// normalizeDependencies sorts the dependencies of each PURL and removes duplicates,
+// returning nil if the graph is empty.
+func normalizeDependencies(dependencies map[string][]string) map[string][]string {
+	for purl, values := range dependencies {
+		sort.Strings(values)
+		unique := values[:0]
+		for i, v := range values {
+			if i == 0 || v != values[i-1] {
+				unique = append(unique, v)
+			}
+		}
+		dependencies[purl] = unique
+	}
+	if len(dependencies) == 0 {
+		return nil
+	}
+	return dependencies
+}
+
+// Depths returns the depth of each package in the dependency graph, keyed by canonical
+// PURL. Direct dependencies of the subject have a depth of 1, their dependencies a depth
+// of 2, and so on. Without a subject in the graph, packages that no other package depends
+// on are treated as direct dependencies. Packages that cannot be reached are omitted, and
+// the result is empty when the KissBOM has no dependencies.
+func (k *KissBOM) Depths() map[string]int {
+	depths := map[string]int{}
+	if len(k.Dependencies) == 0 {
+		return depths
+	}
+
+	graph := map[string][]string{}
+	dependents := map[string]bool{}
+	for purl, dependencies := range k.Dependencies {
+		key := CanonicalPurl(purl)
+		for _, d := range dependencies {
+			graph[key] = append(graph[key], CanonicalPurl(d))
+			dependents[CanonicalPurl(d)] = true
+		}
+	}
+
+	queue := []string{}
+	if k.Metadata != nil && graph[CanonicalPurl(k.Metadata.Subject)] != nil {
+		subject := CanonicalPurl(k.Metadata.Subject)
+		for _, d := range graph[subject] {
+			if _, ok := depths[d]; !ok && d != subject {
+				depths[d] = 1
+				queue = append(queue, d)
+			}
+		}
+	} else {
+		for _, p := range k.Packages {
+			key := CanonicalPurl(p.Purl)
+			if _, ok := depths[key]; !ok && !dependents[key] {
+				depths[key] = 1
+				queue = append(queue, key)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		purl := queue[0]
+		queue = queue[1:]
+		for _, d := range graph[purl] {
+			if _, ok := depths[d]; !ok {
+				depths[d] = depths[purl] + 1
+				queue = append(queue, d)
+			}
+		}
+	}
+	return depths
+}
+
+// SetDepths records the depth of each package in the dependency graph, as computed by
+// Depths, returning the number of packages with a depth.
+func (k *KissBOM) SetDepths() (count int) {
+	depths := k.Depths()
+	for i, p := range k.Packages {
+		k.Packages[i].Depth = depths[CanonicalPurl(p.Purl)]
+		if k.Packages[i].Depth > 0 {
+			count++
+		}
+	}
+	return
+}
+
+// DirectOnly removes every package that is not a direct dependency, returning the
+// number of packages removed. Packages are left untouched when the KissBOM has no
+// dependencies, since direct dependencies cannot be told apart from the others.
+func (k *KissBOM) DirectOnly() (removed int) {
+	if len(k.Dependencies) == 0 {
+		return
+	}
+	depths := k.Depths()
+	packages := []Package{}
+	for _, p := range k.Packages {
+		if depths[CanonicalPurl(p.Purl)] == 1 {
+			packages = append(packages, p)
+		} else {
+			removed++
+		}
+	}
+	k.Packages = packages
+	return
+}
+
+// PruneDependencies removes the dependencies of and on PURLs that are neither a package
+// of the KissBOM nor its subject, such as packages that were filtered out.
+func (k *KissBOM) PruneDependencies() {
+	if len(k.Dependencies) == 0 {
+		return
+	}
+	known := map[string]bool{}
+	for _, p := range k.Packages {
+		known[CanonicalPurl(p.Purl)] = true
+	}
+	if k.Metadata != nil && k.Metadata.Subject != "" {
+		known[CanonicalPurl(k.Metadata.Subject)] = true
+	}
+
+	pruned := map[string][]string{}
+	for purl, dependencies := range k.Dependencies {
+		if !known[CanonicalPurl(purl)] {
+			continue
+		}
+		for _, d := range dependencies {
+			if known[CanonicalPurl(d)] {
+				pruned[purl] = append(pruned[purl], d)
+			}
+		}
+	}
+	k.Dependencies = normalizeDependencies(pruned)
+}
+
+// canonicalDependencies returns a copy of the dependency graph with canonical PURLs.
+func canonicalDependencies(dependencies map[string][]string) map[string][]string {
+	if dependencies == nil {
+		return nil
+	}
+	canonical := map[string][]string{}
+	for purl, values := range dependencies {
+		key := CanonicalPurl(purl)
+		for _, v := range values {
+			canonical[key] = append(canonical[key], CanonicalPurl(v))
+		}
+	}
+	return normalizeDependencies(canonical)
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// testDependencyBOM returns a KissBOM for an application that depends on express and
+// lodash, where express depends on debug, which depends on ms.
+func testDependencyBOM() KissBOM {
+	return KissBOM{
+		Metadata: &Metadata{Subject: "pkg:npm/app@1.0.0"},
+		Packages: []Package{
+			{Purl: "pkg:npm/express@4.18.2"},
+			{Purl: "pkg:npm/debug@2.6.9"},
+			{Purl: "pkg:npm/ms@2.0.0"},
+			{Purl: "pkg:npm/lodash@4.17.21"},
+			{Purl: "pkg:npm/unused@1.0.0"},
+		},
+		Dependencies: map[string][]string{
+			"pkg:npm/app@1.0.0":      {"pkg:npm/express@4.18.2", "pkg:npm/lodash@4.17.21"},
+			"pkg:npm/express@4.18.2": {"pkg:npm/debug@2.6.9", "pkg:npm/lodash@4.17.21"},
+			"pkg:npm/debug@2.6.9":    {"pkg:npm/ms@2.0.0"},
+			"pkg:npm/ms@2.0.0":       {"pkg:npm/debug@2.6.9"},
+		},
+	}
+}
+
+func TestExtractDependencies(t *testing.T) {
+	bom := &cyclonedx.BOM{
+		Metadata: &cyclonedx.Metadata{Component: &cyclonedx.Component{BOMRef: "app", PackageURL: "pkg:npm/app@1.0.0"}},
+		Components: &[]cyclonedx.Component{
+			{BOMRef: "express", PackageURL: "pkg:npm/express@4.18.2"},
+			{PackageURL: "pkg:npm/debug@2.6.9"},
+			{BOMRef: "file", Name: "README.md"},
+		},
+		Dependencies: &[]cyclonedx.Dependency{
+			{Ref: "app", Dependencies: &[]string{"express", "file"}},
+			{Ref: "express", Dependencies: &[]string{"pkg:npm/debug@2.6.9", "pkg:npm/debug@2.6.9"}},
+			{Ref: "pkg:npm/debug@2.6.9"},
+			{Ref: "missing", Dependencies: &[]string{"express"}},
+		},
+	}
+
+	assert.Equal(t, map[string][]string{
+		"pkg:npm/app@1.0.0":      {"pkg:npm/express@4.18.2"},
+		"pkg:npm/express@4.18.2": {"pkg:npm/debug@2.6.9"},
+	}, extractDependencies(bom))
+	assert.Equal(t, extractDependencies(bom), NewKissBOMFromCycloneDX(bom).Dependencies)
+
+	bom.Dependencies = &[]cyclonedx.Dependency{}
+	assert.Nil(t, extractDependencies(bom))
+	bom.Dependencies = nil
+	assert.Nil(t, extractDependencies(bom))
+}
+
+func TestSPDXDependencies(t *testing.T) {
+	purl := func(id string, purl string) SPDXPackage {
+		return SPDXPackage{SPDXID: id, ExternalRefs: []SPDXExternalRef{{Type: "purl", Locator: purl}}}
+	}
+	spdx := &SPDXDocument{
+		Packages: []SPDXPackage{
+			purl("SPDXRef-app", "pkg:npm/app@1.0.0"),
+			purl("SPDXRef-express", "pkg:npm/express@4.18.2"),
+			purl("SPDXRef-debug", "pkg:npm/debug@2.6.9"),
+		},
+		Relationships: []SPDXRelationship{
+			{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-app"},
+			{Element: "SPDXRef-app", Type: SPDXDependsOn, Related: "SPDXRef-express"},
+			{Element: "SPDXRef-debug", Type: SPDXDependencyOf, Related: "SPDXRef-express"},
+			{Element: "SPDXRef-app", Type: "CONTAINS", Related: "SPDXRef-debug"},
+		},
+	}
+
+	assert.Equal(t, map[string][]string{
+		"pkg:npm/app@1.0.0":      {"pkg:npm/express@4.18.2"},
+		"pkg:npm/express@4.18.2": {"pkg:npm/debug@2.6.9"},
+	}, spdxDependencies(spdx))
+	assert.Nil(t, spdxDependencies(&SPDXDocument{}))
+}
+
+func TestMergeDependencies(t *testing.T) {
+	merged := MergeDependencies(
+		map[string][]string{"pkg:npm/a@1": {"pkg:npm/c@1", "pkg:npm/b@1"}},
+		map[string][]string{"pkg:npm/a@1": {"pkg:npm/b@1"}, "pkg:npm/b@1": {"pkg:npm/c@1"}},
+	)
+	assert.Equal(t, map[string][]string{
+		"pkg:npm/a@1": {"pkg:npm/b@1", "pkg:npm/c@1"},
+		"pkg:npm/b@1": {"pkg:npm/c@1"},
+	}, merged)
+	assert.Nil(t, MergeDependencies(nil, nil))
+}
+
+func TestKissBOM_Depths(t *testing.T) {
+	kissbom := testDependencyBOM()
+	assert.Equal(t, map[string]int{
+		"pkg:npm/express@4.18.2": 1,
+		"pkg:npm/lodash@4.17.21": 1,
+		"pkg:npm/debug@2.6.9":    2,
+		"pkg:npm/ms@2.0.0":       3,
+	}, kissbom.Depths())
+
+	assert.Equal(t, 4, kissbom.SetDepths())
+	assert.Equal(t, 1, kissbom.Packages[0].Depth)
+	assert.Equal(t, 3, kissbom.Packages[2].Depth)
+	assert.Equal(t, 0, kissbom.Packages[4].Depth)
+
+	// without a subject, packages that nothing depends on are direct dependencies
+	kissbom = testDependencyBOM()
+	kissbom.Metadata = nil
+	delete(kissbom.Dependencies, "pkg:npm/app@1.0.0")
+	assert.Equal(t, map[string]int{
+		"pkg:npm/express@4.18.2": 1,
+		"pkg:npm/unused@1.0.0":   1,
+		"pkg:npm/debug@2.6.9":    2,
+		"pkg:npm/lodash@4.17.21": 2,
+		"pkg:npm/ms@2.0.0":       3,
+	}, kissbom.Depths())
+
+	assert.Empty(t, (&KissBOM{Packages: kissbom.Packages}).Depths())
+}
+
+func TestKissBOM_DirectOnly(t *testing.T) {
+	kissbom := testDependencyBOM()
+	assert.Equal(t, 3, kissbom.DirectOnly())
+	assert.Equal(t, []Package{{Purl: "pkg:npm/express@4.18.2"}, {Purl: "pkg:npm/lodash@4.17.21"}}, kissbom.Packages)
+
+	kissbom.PruneDependencies()
+	assert.Equal(t, map[string][]string{
+		"pkg:npm/app@1.0.0":      {"pkg:npm/express@4.18.2", "pkg:npm/lodash@4.17.21"},
+		"pkg:npm/express@4.18.2": {"pkg:npm/lodash@4.17.21"},
+	}, kissbom.Dependencies)
+
+	kissbom = KissBOM{Packages: []Package{{Purl: "pkg:npm/a@1"}}}
+	assert.Equal(t, 0, kissbom.DirectOnly())
+	assert.Len(t, kissbom.Packages, 1)
+}
+
+func TestKissBOM_Dependencies_Output(t *testing.T) {
+	kissbom := testDependencyBOM()
+	kissbom.Dependencies["pkg:NPM/Debug@2.6.9"] = []string{"pkg:npm/ms@2.0.0"}
+	kissbom.SetDepths()
+	kissbom.Canonical = true
+
+	data, err := kissbom.JSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"dependencies":{"pkg:npm/app@1.0.0":["pkg:npm/express@4.18.2","pkg:npm/lodash@4.17.21"],"pkg:npm/debug@2.6.9":["pkg:npm/ms@2.0.0"]`)
+	assert.Contains(t, string(data), `{"depth":3,"purl":"pkg:npm/ms@2.0.0"}`)
+
+	csvData, err := kissbom.CSV()
+	assert.NoError(t, err)
+	assert.Contains(t, string(csvData), "purl,license,copyright,notes,license_category,hashes,depth\n")
+	assert.Contains(t, string(csvData), "pkg:npm/ms@2.0.0,,,,unknown,,3\n")
+	assert.Contains(t, string(csvData), "pkg:npm/unused@1.0.0,,,,unknown,,\n")
+
+	kissbom.Strict = true
+	data, err = kissbom.JSON()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "depth")
+	assert.NotContains(t, string(data), "dependencies")
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+)
+
+// Filter determines which packages are kept when building a KissBOM. Empty
+// criteria are ignored, so the zero value allows every package.
+type Filter struct {
+	IncludeTypes   []string // PURL types to keep, with or without the "pkg:" prefix (e.g. "npm" or "pkg:npm").
+	ExcludeScopes  []string // Component scopes to drop (e.g. "optional", "excluded"). An empty scope is treated as "required".
+	ComponentTypes []string // CycloneDX component types to keep (e.g. "library", "framework").
+	Names          []string // Glob patterns, one of which the package name must match.
+	Namespaces     []string // Glob patterns, one of which the package namespace must match.
+}
+
+// IsEmpty returns true if the filter has no criteria.
+func (f Filter) IsEmpty() bool {
+	return len(f.IncludeTypes) == 0 && len(f.ExcludeScopes) == 0 && len(f.ComponentTypes) == 0 &&
+		len(f.Names) == 0 && len(f.Namespaces) == 0
+}
+
+// Allows determines if a package should be kept.
+//
+// Parameters:
+//   - purl: The Package URL of the package.
+//   - scope: The scope of the component, if known.
+//   - componentType: The CycloneDX component type, if known. Unknown types are not filtered.
+//
+// Returns:
+//   - true if the package satisfies every criterion of the filter.
+func (f Filter) Allows(purl string, scope string, componentType string) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	if scope == "" {
+		scope = string(cyclonedx.ScopeRequired)
+	}
+	if containsFold(f.ExcludeScopes, scope) {
+		return false
+	}
+
+	if componentType != "" && len(f.ComponentTypes) != 0 && !containsFold(f.ComponentTypes, componentType) {
+		return false
+	}
+
+	if len(f.IncludeTypes) == 0 && len(f.Names) == 0 && len(f.Namespaces) == 0 {
+		return true
+	}
+
+	p, err := packageurl.FromString(purl)
+	if err != nil {
+		return false
+	}
+
+	if len(f.IncludeTypes) != 0 {
+		types := make([]string, len(f.IncludeTypes))
+		for i, t := range f.IncludeTypes {
+			types[i] = strings.TrimPrefix(strings.ToLower(t), "pkg:")
+		}
+		if !containsFold(types, p.Type) {
+			return false
+		}
+	}
+
+	return matchesAny(f.Names, p.Name) && matchesAny(f.Namespaces, p.Namespace)
+}
+
+// containsFold returns true if values contains value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny returns true if there are no patterns or if value matches one of them.
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if GlobMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobMatch matches value against a glob pattern where "*" matches any sequence of
+// characters (including "/") and "?" matches a single character. Matching backtracks
+// to the last "*" only, so it runs in linear time for most patterns without building
+// a regular expression.
+func GlobMatch(pattern string, value string) bool {
+	p, v := []rune(strings.TrimSpace(pattern)), []rune(value)
+	i, j := 0, 0
+	star, next := -1, 0 // Position of the last "*" in the pattern, and of the value it resumes from.
+	for j < len(v) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, next = i, j
+			i++
+		case i < len(p) && (p[i] == '?' || p[i] == v[j]):
+			i++
+			j++
+		case star >= 0:
+			next++
+			i, j = star+1, next
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
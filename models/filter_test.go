@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Allows(t *testing.T) {
+	assert.True(t, Filter{}.Allows("not a purl", "", ""))
+
+	f := Filter{IncludeTypes: []string{"pkg:npm", "PyPI"}}
+	assert.True(t, f.Allows("pkg:npm/lodash@4.17.21", "", ""))
+	assert.True(t, f.Allows("pkg:pypi/requests@2.26.0", "", ""))
+	assert.False(t, f.Allows("pkg:maven/org.apache.tomcat/tomcat-catalina@9.0.14", "", ""))
+	assert.False(t, f.Allows("not a purl", "", ""))
+
+	f = Filter{ExcludeScopes: []string{"optional", "excluded"}}
+	assert.True(t, f.Allows("pkg:npm/lodash@4.17.21", "", ""))
+	assert.True(t, f.Allows("pkg:npm/lodash@4.17.21", "required", ""))
+	assert.False(t, f.Allows("pkg:npm/mocha@10.0.0", "optional", ""))
+	assert.False(t, Filter{ExcludeScopes: []string{"required"}}.Allows("pkg:npm/lodash@4.17.21", "", ""))
+
+	f = Filter{ComponentTypes: []string{"library", "framework"}}
+	assert.True(t, f.Allows("pkg:npm/lodash@4.17.21", "", "library"))
+	assert.False(t, f.Allows("pkg:generic/readme@1", "", "file"))
+	assert.True(t, f.Allows("pkg:generic/readme@1", "", ""))
+
+	f = Filter{Names: []string{"lodash*"}, Namespaces: []string{"", "@types"}}
+	assert.True(t, f.Allows("pkg:npm/lodash.merge@4.6.2", "", ""))
+	assert.True(t, f.Allows("pkg:npm/%40types/lodash@4.14.0", "", ""))
+	assert.False(t, f.Allows("pkg:npm/express@4.18.2", "", ""))
+	assert.False(t, f.Allows("pkg:npm/%40babel/lodash@1.0.0", "", ""))
+
+	f = Filter{Namespaces: []string{"github.com/*"}}
+	assert.True(t, f.Allows("pkg:golang/github.com/spf13/cobra@v1.8.0", "", ""))
+	assert.False(t, f.Allows("pkg:golang/golang.org/x/sys@v0.20.0", "", ""))
+}
+
+func TestNewKissBOMFromCycloneDX_Filter(t *testing.T) {
+	components := []cyclonedx.Component{
+		{Type: cyclonedx.ComponentTypeLibrary, PackageURL: "pkg:npm/lodash@4.17.21"},
+		{Type: cyclonedx.ComponentTypeLibrary, PackageURL: "pkg:npm/mocha@10.0.0", Scope: cyclonedx.ScopeOptional},
+		{Type: cyclonedx.ComponentTypeFile, PackageURL: "pkg:generic/readme@1"},
+		{Type: cyclonedx.ComponentTypeLibrary, PackageURL: "pkg:pypi/requests@2.26.0"},
+	}
+
+	kissBOM := NewKissBOMFromCycloneDX(&cyclonedx.BOM{Components: &components}, Filter{
+		IncludeTypes:   []string{"pkg:npm", "pkg:generic"},
+		ExcludeScopes:  []string{"optional"},
+		ComponentTypes: []string{"library"},
+	})
+
+	assert.Len(t, kissBOM.Packages, 1)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", kissBOM.Packages[0].Purl)
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		matched bool
+	}{
+		{"lodash", "lodash", true},
+		{"lodash", "lodash.merge", false},
+		{" lodash* ", "lodash.merge", true},
+		{"*", "", true},
+		{"", "", true},
+		{"", "a", false},
+		{"@babel/*", "@babel/core", true},
+		{"pkg:npm/*@4.*", "pkg:npm/@types/node@4.1.0", true},
+		{"a*b*c", "axxbyybzc", true},
+		{"a*b*c", "axxbyybz", false},
+		{"ms?", "ms", false},
+		{"l?b", "läb", true},
+		{"**x", "abcx", true},
+		{"*.json", "sboms/a.cdx.json", true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.matched, GlobMatch(test.pattern, test.value), "%s %s", test.pattern, test.value)
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// spdxChecksumAlgorithms maps SPDX checksum algorithms to the CycloneDX hash
+// algorithm names used as keys of Package.Hashes.
+var spdxChecksumAlgorithms = map[string]string{
+	"SHA1":     string(cyclonedx.HashAlgoSHA1),
+	"SHA256":   string(cyclonedx.HashAlgoSHA256),
+	"SHA384":   string(cyclonedx.HashAlgoSHA384),
+	"SHA512":   string(cyclonedx.HashAlgoSHA512),
+	"SHA3_256": string(cyclonedx.HashAlgoSHA3_256),
+	"SHA3_384": string(cyclonedx.HashAlgoSHA3_384),
+	"SHA3_512": string(cyclonedx.HashAlgoSHA3_512),
+}
+
+// SPDXChecksumAlgorithm returns the CycloneDX name of an SPDX checksum algorithm
+// (ex: SHA256 becomes SHA-256). Algorithms without a CycloneDX equivalent, or that
+// are already spelled the same way such as MD5 and BLAKE2b-256, are returned as is.
+func SPDXChecksumAlgorithm(algorithm string) string {
+	algorithm = strings.TrimSpace(algorithm)
+	if name, ok := spdxChecksumAlgorithms[strings.ToUpper(algorithm)]; ok {
+		return name
+	}
+	return algorithm
+}
+
+// extractHashes returns the hashes of a CycloneDX component keyed by algorithm, or
+// nil if the component has none.
+func extractHashes(component cyclonedx.Component) map[string]string {
+	if component.Hashes == nil {
+		return nil
+	}
+	hashes := map[string]string{}
+	for _, h := range *component.Hashes {
+		if h.Algorithm != "" && h.Value != "" {
+			hashes[string(h.Algorithm)] = h.Value
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes
+}
+
+// cycloneDXHashes returns the hashes as CycloneDX hashes ordered by algorithm, or nil
+// if there are none.
+func cycloneDXHashes(hashes map[string]string) *[]cyclonedx.Hash {
+	if len(hashes) == 0 {
+		return nil
+	}
+	result := []cyclonedx.Hash{}
+	for _, algorithm := range hashAlgorithms(hashes) {
+		result = append(result, cyclonedx.Hash{Algorithm: cyclonedx.HashAlgorithm(algorithm), Value: hashes[algorithm]})
+	}
+	return &result
+}
+
+// formatHashes encodes the hashes as a single value ordered by algorithm, such as
+// "SHA-1:da39a3ee;SHA-256:e3b0c442".
+func formatHashes(hashes map[string]string) string {
+	parts := []string{}
+	for _, algorithm := range hashAlgorithms(hashes) {
+		parts = append(parts, algorithm+":"+hashes[algorithm])
+	}
+	return strings.Join(parts, ";")
+}
+
+// hashAlgorithms returns the algorithms of the hashes in sorted order.
+func hashAlgorithms(hashes map[string]string) []string {
+	algorithms := make([]string, 0, len(hashes))
+	for algorithm := range hashes {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	return algorithms
+}
+
+// mergeHashes combines the hashes of two packages, keeping the existing value when
+// both have a hash for the same algorithm.
+func mergeHashes(existing map[string]string, incoming map[string]string) map[string]string {
+	if len(incoming) == 0 {
+		return existing
+	}
+	if len(existing) == 0 {
+		return incoming
+	}
+	merged := make(map[string]string, len(existing)+len(incoming))
+	for algorithm, value := range incoming {
+		merged[algorithm] = value
+	}
+	for algorithm, value := range existing {
+		merged[algorithm] = value
+	}
+	return merged
+}
+
+// conflictingHash returns an error if the packages have different values for the
+// same hash algorithm.
+func conflictingHash(existing Package, incoming Package) error {
+	for _, algorithm := range hashAlgorithms(existing.Hashes) {
+		a, b := existing.Hashes[algorithm], incoming.Hashes[algorithm]
+		if b != "" && !strings.EqualFold(a, b) {
+			return fmt.Errorf("conflicting %s hash for %s: %q and %q", algorithm, existing.Purl, a, b)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPDXChecksumAlgorithm(t *testing.T) {
+	tests := map[string]string{
+		"SHA1":        "SHA-1",
+		"sha256":      "SHA-256",
+		"SHA3_512":    "SHA3-512",
+		"MD5":         "MD5",
+		"BLAKE2b-256": "BLAKE2b-256",
+		"SHA224":      "SHA224",
+	}
+	for algorithm, expected := range tests {
+		assert.Equal(t, expected, SPDXChecksumAlgorithm(algorithm), algorithm)
+	}
+}
+
+func TestExtractHashes(t *testing.T) {
+	component := cyclonedx.Component{Hashes: &[]cyclonedx.Hash{
+		{Algorithm: cyclonedx.HashAlgoSHA512, Value: "def456"},
+		{Algorithm: cyclonedx.HashAlgoSHA1, Value: "abc123"},
+		{Algorithm: cyclonedx.HashAlgoMD5},
+	}}
+	hashes := extractHashes(component)
+	assert.Equal(t, map[string]string{"SHA-512": "def456", "SHA-1": "abc123"}, hashes)
+	assert.Equal(t, "SHA-1:abc123;SHA-512:def456", formatHashes(hashes))
+	assert.Equal(t, &[]cyclonedx.Hash{
+		{Algorithm: cyclonedx.HashAlgoSHA1, Value: "abc123"},
+		{Algorithm: cyclonedx.HashAlgoSHA512, Value: "def456"},
+	}, cycloneDXHashes(hashes))
+
+	assert.Nil(t, extractHashes(cyclonedx.Component{}))
+	assert.Nil(t, extractHashes(cyclonedx.Component{Hashes: &[]cyclonedx.Hash{}}))
+	assert.Nil(t, cycloneDXHashes(nil))
+	assert.Empty(t, formatHashes(nil))
+}
+
+func TestMergeHashes(t *testing.T) {
+	existing := map[string]string{"SHA-1": "abc123"}
+	incoming := map[string]string{"SHA-1": "other", "SHA-512": "def456"}
+
+	assert.Equal(t, map[string]string{"SHA-1": "abc123", "SHA-512": "def456"}, mergeHashes(existing, incoming))
+	assert.Equal(t, existing, mergeHashes(existing, nil))
+	assert.Equal(t, incoming, mergeHashes(nil, incoming))
+	assert.Equal(t, map[string]string{"SHA-1": "abc123"}, existing)
+}
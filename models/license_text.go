@@ -0,0 +1,119 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// licenseNames maps common license names, as found in package metadata such as POM
+// files and Python classifiers, to SPDX identifiers. Names are matched in lowercase
+// with punctuation collapsed to single spaces.
+var licenseNames = map[string]string{
+	"apache 2":                                              "Apache-2.0",
+	"apache 2 0":                                            "Apache-2.0",
+	"apache license 2 0":                                    "Apache-2.0",
+	"apache license version 2 0":                            "Apache-2.0",
+	"apache software license":                               "Apache-2.0",
+	"the apache license version 2 0":                        "Apache-2.0",
+	"the apache software license version 2 0":               "Apache-2.0",
+	"mit license":                                           "MIT",
+	"the mit license":                                       "MIT",
+	"new bsd license":                                       "BSD-3-Clause",
+	"the new bsd license":                                   "BSD-3-Clause",
+	"bsd 3 clause license":                                  "BSD-3-Clause",
+	"revised bsd license":                                   "BSD-3-Clause",
+	"bsd 2 clause license":                                  "BSD-2-Clause",
+	"simplified bsd license":                                "BSD-2-Clause",
+	"isc license":                                           "ISC",
+	"isc license iscl":                                      "ISC",
+	"mozilla public license 2 0":                            "MPL-2.0",
+	"mozilla public license 2 0 mpl 2 0":                    "MPL-2.0",
+	"mozilla public license version 2 0":                    "MPL-2.0",
+	"eclipse public license 1 0":                            "EPL-1.0",
+	"eclipse public license v 1 0":                          "EPL-1.0",
+	"eclipse public license 2 0":                            "EPL-2.0",
+	"eclipse public license v 2 0":                          "EPL-2.0",
+	"eclipse distribution license v 1 0":                    "BSD-3-Clause",
+	"gnu general public license v2 gplv2":                   "GPL-2.0-only",
+	"gnu general public license v3 gplv3":                   "GPL-3.0-only",
+	"gnu general public license v2 or later gplv2":          "GPL-2.0-or-later",
+	"gnu general public license v3 or later gplv3":          "GPL-3.0-or-later",
+	"gnu lesser general public license v2 lgplv2":           "LGPL-2.0-only",
+	"gnu lesser general public license v3 lgplv3":           "LGPL-3.0-only",
+	"gnu library or lesser general public license lgpl":     "LGPL-2.1-or-later",
+	"gnu affero general public license v3":                  "AGPL-3.0-only",
+	"gnu affero general public license v3 or later agplv3":  "AGPL-3.0-or-later",
+	"python software foundation license":                    "PSF-2.0",
+	"the unlicense unlicense":                               "Unlicense",
+	"cc0 1 0 universal":                                     "CC0-1.0",
+	"common development and distribution license cddl v1 0": "CDDL-1.0",
+	"cddl 1 1":                     "CDDL-1.1",
+	"boost software license 1 0":   "BSL-1.0",
+	"zlib libpng license":          "Zlib",
+	"universal permissive license": "UPL-1.0",
+}
+
+// licenseTextPatterns identifies licenses from the text of license files, in order
+// of precedence.
+var licenseTextPatterns = []struct {
+	id      string
+	pattern *regexp.Regexp
+}{
+	{"AGPL-3.0-only", regexp.MustCompile(`(?i)GNU AFFERO GENERAL PUBLIC LICENSE\s+Version 3`)},
+	{"LGPL-3.0-only", regexp.MustCompile(`(?i)GNU LESSER GENERAL PUBLIC LICENSE\s+Version 3`)},
+	{"LGPL-2.1-only", regexp.MustCompile(`(?i)GNU LESSER GENERAL PUBLIC LICENSE\s+Version 2\.1`)},
+	{"GPL-3.0-only", regexp.MustCompile(`(?i)GNU GENERAL PUBLIC LICENSE\s+Version 3`)},
+	{"GPL-2.0-only", regexp.MustCompile(`(?i)GNU GENERAL PUBLIC LICENSE\s+Version 2`)},
+	{"MPL-2.0", regexp.MustCompile(`(?i)Mozilla Public License,?\s+(Version|v\.?)\s*2\.0`)},
+	{"EPL-2.0", regexp.MustCompile(`(?i)Eclipse Public License\s*-?\s*v(ersion)?\s*2\.0`)},
+	{"Apache-2.0", regexp.MustCompile(`(?i)Apache License,?\s+Version 2\.0`)},
+	{"BSD-3-Clause", regexp.MustCompile(`(?is)Redistribution and use in source and binary forms.*Neither the name`)},
+	{"BSD-2-Clause", regexp.MustCompile(`(?i)Redistribution and use in source and binary forms`)},
+	{"MIT", regexp.MustCompile(`(?i)Permission is hereby granted, free of charge, to any person obtaining a copy`)},
+	{"ISC", regexp.MustCompile(`(?i)Permission to use, copy, modify, and(/or)? distribute this software for any purpose with or without fee`)},
+	{"Unlicense", regexp.MustCompile(`(?i)This is free and unencumbered software released into the public domain`)},
+	{"CC0-1.0", regexp.MustCompile(`(?i)CC0 1\.0 Universal`)},
+}
+
+// copyrightPattern matches copyright statements in license files.
+var copyrightPattern = regexp.MustCompile(`(?im)^\s*(Copyright\s+(\(c\)|©|\d{4}).*)$`)
+
+// nonAlphanumeric matches runs of characters that are ignored when matching license names.
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// NormalizeLicense converts a license name or identifier found in package metadata to
+// an SPDX identifier. Valid SPDX expressions are returned as written, with known
+// identifiers correctly cased. Unrecognized names are returned trimmed.
+func NormalizeLicense(name string) string {
+	name = strings.TrimSpace(name)
+	if id, ok := licenseClassification.identifiers[strings.ToLower(name)]; ok {
+		return id
+	}
+	if e, err := ParseLicenseExpression(name); err == nil && LicenseCategory(name) != CategoryUnknown {
+		return e.String()
+	}
+	key := strings.TrimSpace(nonAlphanumeric.ReplaceAllString(strings.ToLower(name), " "))
+	if id, ok := licenseNames[key]; ok {
+		return id
+	}
+	return name
+}
+
+// IdentifyLicenseText identifies the SPDX license of the text of a license file,
+// returning an empty string if the license is not recognized.
+func IdentifyLicenseText(text string) string {
+	for _, p := range licenseTextPatterns {
+		if p.pattern.MatchString(text) {
+			return p.id
+		}
+	}
+	return ""
+}
+
+// FindCopyright returns the first copyright statement of the text of a license file.
+func FindCopyright(text string) string {
+	if m := copyrightPattern.FindStringSubmatch(text); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
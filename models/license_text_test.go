@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLicense(t *testing.T) {
+	tests := map[string]string{
+		"mit":               "MIT",
+		" apache-2.0 ":      "Apache-2.0",
+		"MIT OR Apache-2.0": "MIT OR Apache-2.0",
+		"The Apache Software License, Version 2.0": "Apache-2.0",
+		"Eclipse Public License - v 2.0":           "EPL-2.0",
+		"MIT License":                              "MIT",
+		"Some Custom License":                      "Some Custom License",
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, NormalizeLicense(name), name)
+	}
+}
+
+func TestIdentifyLicenseText(t *testing.T) {
+	assert.Equal(t, "MIT", IdentifyLicenseText("Permission is hereby granted, free of charge, to any person obtaining a copy"))
+	assert.Equal(t, "Apache-2.0", IdentifyLicenseText("Apache License\n                           Version 2.0, January 2004"))
+	assert.Equal(t, "BSD-3-Clause", IdentifyLicenseText("Redistribution and use in source and binary forms...\n3. Neither the name of the copyright holder"))
+	assert.Equal(t, "BSD-2-Clause", IdentifyLicenseText("Redistribution and use in source and binary forms..."))
+	assert.Equal(t, "LGPL-2.1-only", IdentifyLicenseText("GNU LESSER GENERAL PUBLIC LICENSE\n Version 2.1, February 1999"))
+	assert.Equal(t, "", IdentifyLicenseText("All rights reserved."))
+}
+
+func TestFindCopyright(t *testing.T) {
+	assert.Equal(t, "Copyright (c) 2021 Example Authors", FindCopyright("MIT License\n\n  Copyright (c) 2021 Example Authors\n\nPermission..."))
+	assert.Equal(t, "Copyright 2019 Kenneth Reitz", FindCopyright("Copyright 2019 Kenneth Reitz"))
+	assert.Equal(t, "", FindCopyright("Copyright notice must be retained"))
+}
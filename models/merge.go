@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Enumeration of valid strategies for reconciling packages that share a canonical PURL.
+const (
+	MergeFirstWins = "first-wins" // MergeFirstWins keeps the first non-empty value encountered for each field.
+	MergeUnion     = "union"      // MergeUnion combines all distinct values for each field.
+	MergeFail      = "fail"       // MergeFail returns an error when two non-empty values conflict.
+)
+
+// MergeStrategies contains all of the valid merge strategies.
+var MergeStrategies = []string{MergeFirstWins, MergeUnion, MergeFail}
+
+// MergePackage reconciles two packages that share a canonical PURL according to the
+// provided strategy. The PURL of the existing package is always kept, and hashes are
+// combined by algorithm with the existing value taking precedence.
+//
+// Parameters:
+//   - existing: The package already present in the KissBOM.
+//   - incoming: The package being merged into the KissBOM.
+//   - strategy: One of MergeFirstWins, MergeUnion or MergeFail.
+//
+// Returns:
+//   - The reconciled package.
+//   - An error if the strategy is unknown or, with MergeFail, if any field conflicts.
+func MergePackage(existing Package, incoming Package, strategy string) (Package, error) {
+	merged := existing
+	switch strategy {
+	case MergeFirstWins:
+		merged.License = FirstNonEmpty(existing.License, incoming.License)
+		merged.Copyright = FirstNonEmpty(existing.Copyright, incoming.Copyright)
+		merged.Notes = FirstNonEmpty(existing.Notes, incoming.Notes)
+		merged.Hashes = mergeHashes(existing.Hashes, incoming.Hashes)
+	case MergeUnion:
+		merged.License = unionValues(existing.License, incoming.License, " AND ", true)
+		merged.Copyright = unionValues(existing.Copyright, incoming.Copyright, "; ", false)
+		merged.Notes = unionValues(existing.Notes, incoming.Notes, "; ", false)
+		merged.Hashes = mergeHashes(existing.Hashes, incoming.Hashes)
+	case MergeFail:
+		fields := []struct {
+			name, a, b string
+		}{
+			{"license", existing.License, incoming.License},
+			{"copyright", existing.Copyright, incoming.Copyright},
+			{"notes", existing.Notes, incoming.Notes},
+		}
+		for _, f := range fields {
+			if f.a != "" && f.b != "" && f.a != f.b {
+				return existing, fmt.Errorf("conflicting %s for %s: %q and %q", f.name, existing.Purl, f.a, f.b)
+			}
+		}
+		if err := conflictingHash(existing, incoming); err != nil {
+			return existing, err
+		}
+		merged.License = FirstNonEmpty(existing.License, incoming.License)
+		merged.Copyright = FirstNonEmpty(existing.Copyright, incoming.Copyright)
+		merged.Notes = FirstNonEmpty(existing.Notes, incoming.Notes)
+		merged.Hashes = mergeHashes(existing.Hashes, incoming.Hashes)
+	default:
+		return existing, fmt.Errorf("unsupported merge strategy: %s", strategy)
+	}
+	return merged, nil
+}
+
+// FirstNonEmpty returns the first value that is not an empty string.
+func FirstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// unionValues combines the distinct parts of a and b using the provided separator.
+// Parts are sorted so that the result does not depend on the order in which
+// values were encountered. When wrap is true, compound parts are wrapped in
+// parentheses so that the result remains a valid SPDX expression.
+func unionValues(a string, b string, separator string, wrap bool) string {
+	seen := map[string]bool{}
+	parts := []string{}
+	for _, value := range []string{a, b} {
+		for _, part := range splitTopLevel(value, separator) {
+			if wrap && strings.ContainsRune(part, ' ') && !isWrapped(part) {
+				part = "(" + part + ")"
+			}
+			if part == "" || seen[part] {
+				continue
+			}
+			seen[part] = true
+			parts = append(parts, part)
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, separator)
+}
+
+// splitTopLevel splits value on separator, ignoring separators nested within parentheses.
+func splitTopLevel(value string, separator string) (parts []string) {
+	depth := 0
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 && strings.HasPrefix(value[i:], separator) {
+			parts = append(parts, strings.TrimSpace(value[start:i]))
+			i += len(separator) - 1
+			start = i + 1
+		}
+	}
+	parts = append(parts, strings.TrimSpace(value[start:]))
+	return
+}
+
+// isWrapped returns true if the entire value is enclosed in a single pair of parentheses.
+func isWrapped(value string) bool {
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return false
+	}
+	depth := 0
+	for i, r := range value {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(value)-1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Deduplicate collapses packages that share a canonical PURL into a single package,
+// keeping the position of the first occurrence and combining license, copyright,
+// notes and hash values with the MergeUnion strategy.
+//
+// Returns:
+//   - The number of duplicate packages that were collapsed.
+func (k *KissBOM) Deduplicate() (collapsed int) {
+	index := map[string]int{}
+	packages := []Package{}
+	for _, p := range k.Packages {
+		key := CanonicalPurl(p.Purl)
+		if i, ok := index[key]; ok {
+			packages[i], _ = MergePackage(packages[i], p, MergeUnion)
+			collapsed++
+			continue
+		}
+		index[key] = len(packages)
+		packages = append(packages, p)
+	}
+	if collapsed > 0 {
+		k.Packages = packages
+	}
+	return
+}
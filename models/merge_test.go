@@ -0,0 +1,74 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePackage(t *testing.T) {
+	existing := Package{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Notes: "first"}
+	incoming := Package{Purl: "pkg:npm/lodash@4.17.21", License: "(Apache-2.0 OR MIT)", Copyright: "Copyright 2023", Notes: "second"}
+
+	merged, err := MergePackage(existing, incoming, MergeFirstWins)
+	assert.NoError(t, err)
+	assert.Equal(t, "MIT", merged.License)
+	assert.Equal(t, "Copyright 2023", merged.Copyright)
+	assert.Equal(t, "first", merged.Notes)
+
+	merged, err = MergePackage(existing, incoming, MergeUnion)
+	assert.NoError(t, err)
+	assert.Equal(t, "(Apache-2.0 OR MIT) AND MIT", merged.License)
+	assert.Equal(t, "Copyright 2023", merged.Copyright)
+	assert.Equal(t, "first; second", merged.Notes)
+
+	merged, err = MergePackage(merged, existing, MergeUnion)
+	assert.NoError(t, err)
+	assert.Equal(t, "(Apache-2.0 OR MIT) AND MIT", merged.License)
+
+	_, err = MergePackage(existing, incoming, MergeFail)
+	assert.Error(t, err)
+
+	merged, err = MergePackage(existing, Package{Purl: existing.Purl, Copyright: "Copyright 2023"}, MergeFail)
+	assert.NoError(t, err)
+	assert.Equal(t, "Copyright 2023", merged.Copyright)
+
+	existing.Hashes = map[string]string{"SHA-1": "abc123"}
+	merged, err = MergePackage(existing, Package{Purl: existing.Purl, Hashes: map[string]string{"SHA-1": "ABC123", "SHA-512": "def456"}}, MergeFail)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"SHA-1": "abc123", "SHA-512": "def456"}, merged.Hashes)
+
+	_, err = MergePackage(existing, Package{Purl: existing.Purl, Hashes: map[string]string{"SHA-1": "other"}}, MergeFail)
+	assert.ErrorContains(t, err, "conflicting SHA-1 hash")
+
+	merged, err = MergePackage(existing, Package{Purl: existing.Purl, Hashes: map[string]string{"SHA-1": "other", "MD5": "789"}}, MergeUnion)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"SHA-1": "abc123", "MD5": "789"}, merged.Hashes)
+
+	_, err = MergePackage(existing, incoming, "barf")
+	assert.Error(t, err)
+}
+
+func TestCanonicalPurl(t *testing.T) {
+	assert.Equal(t, CanonicalPurl("pkg:npm/%40angular/core@1.0.0"), CanonicalPurl("pkg:NPM/@Angular/Core@1.0.0"))
+	assert.Equal(t, CanonicalPurl("pkg:maven/a/b@1?type=jar&classifier=x"), CanonicalPurl("pkg:maven/a/b@1?classifier=x&type=jar"))
+	assert.Equal(t, "not a purl", CanonicalPurl(" not a purl "))
+}
+
+func TestKissBOM_Deduplicate(t *testing.T) {
+	kissBOM := KissBOM{
+		Packages: []Package{
+			{Purl: "pkg:npm/lodash@4.17.21", License: "MIT"},
+			{Purl: "pkg:pypi/requests@2.26.0"},
+			{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Copyright: "Copyright JS Foundation"},
+			{Purl: "pkg:NPM/Lodash@4.17.21", Notes: "hoisted"},
+		},
+	}
+
+	assert.Equal(t, 2, kissBOM.Deduplicate())
+	assert.Len(t, kissBOM.Packages, 2)
+	assert.Equal(t, Package{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Copyright: "Copyright JS Foundation", Notes: "hoisted"}, kissBOM.Packages[0])
+	assert.Equal(t, "pkg:pypi/requests@2.26.0", kissBOM.Packages[1].Purl)
+
+	assert.Equal(t, 0, kissBOM.Deduplicate())
+}
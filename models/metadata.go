@@ -0,0 +1,211 @@
+package models
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+// Metadata describes the subject of a KissBOM, who produced it and when, and the source
+// document it was derived from. Every field is optional.
+type Metadata struct {
+	Subject      string `json:"subject,omitempty" yaml:"subject,omitempty"`           // Subject is the PURL of the component the KissBOM describes.
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`                 // Name is the name of the component the KissBOM describes.
+	Version      string `json:"version,omitempty" yaml:"version,omitempty"`           // Version is the version of the component the KissBOM describes.
+	Author       string `json:"author,omitempty" yaml:"author,omitempty"`             // Author is the person or organization that authored the source document.
+	Supplier     string `json:"supplier,omitempty" yaml:"supplier,omitempty"`         // Supplier is the organization that supplied the component.
+	Timestamp    string `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`       // Timestamp is when the source document was created, in ISO 8601 format.
+	SerialNumber string `json:"serialNumber,omitempty" yaml:"serialNumber,omitempty"` // SerialNumber identifies the source document (CycloneDX serial number or SPDX namespace).
+	Digest       string `json:"digest,omitempty" yaml:"digest,omitempty"`             // Digest of the source document (ex: sha256:e3b0c442...).
+	Tool         string `json:"tool,omitempty" yaml:"tool,omitempty"`                 // Tool is the name of the tool that generated the source document.
+	ToolVersion  string `json:"toolVersion,omitempty" yaml:"toolVersion,omitempty"`   // ToolVersion is the version of the tool that generated the source document.
+}
+
+// Enumeration of the CycloneDX properties recording where a compatible BOM came from.
+const (
+	PropertySerialNumber = "kissbom:source:serialNumber" // PropertySerialNumber holds the serial number of the source document.
+	PropertyDigest       = "kissbom:source:digest"       // PropertyDigest holds the digest of the source document.
+)
+
+// DocumentDigest returns the SHA-256 digest of a source document, prefixed with the
+// algorithm (ex: sha256:e3b0c442...).
+func DocumentDigest(source []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(source))
+}
+
+// NewMetadataFromCycloneDX returns the metadata of a CycloneDX BOM, or nil if the BOM
+// has none.
+func NewMetadataFromCycloneDX(cdx *cyclonedx.BOM) *Metadata {
+	m := &Metadata{SerialNumber: cdx.SerialNumber}
+	if cdx.Metadata != nil {
+		m.Timestamp = cdx.Metadata.Timestamp
+		if c := cdx.Metadata.Component; c != nil {
+			m.Subject, m.Name, m.Version = c.PackageURL, c.Name, c.Version
+			m.Author = FirstNonEmpty(c.Author, c.Publisher)
+			if c.Supplier != nil {
+				m.Supplier = c.Supplier.Name
+			}
+		}
+		if cdx.Metadata.Authors != nil && len(*cdx.Metadata.Authors) > 0 {
+			m.Author = FirstNonEmpty((*cdx.Metadata.Authors)[0].Name, m.Author)
+		}
+		if s := cdx.Metadata.Supplier; s != nil {
+			m.Supplier = FirstNonEmpty(s.Name, m.Supplier)
+		} else if s := cdx.Metadata.Manufacture; s != nil {
+			m.Supplier = FirstNonEmpty(m.Supplier, s.Name)
+		}
+		m.Tool, m.ToolVersion = cycloneDXTool(cdx.Metadata.Tools)
+	}
+	if m.IsEmpty() {
+		return nil
+	}
+	return m
+}
+
+// cycloneDXTool returns the name and version of the first tool, whether it is recorded
+// as a legacy tool or as a component.
+func cycloneDXTool(tools *cyclonedx.ToolsChoice) (name string, version string) {
+	switch {
+	case tools == nil:
+	case tools.Tools != nil && len(*tools.Tools) > 0:
+		tool := (*tools.Tools)[0]
+		return tool.Name, tool.Version
+	case tools.Components != nil && len(*tools.Components) > 0:
+		tool := (*tools.Components)[0]
+		return tool.Name, tool.Version
+	}
+	return
+}
+
+// NewMetadataFromSPDX returns the metadata of an SPDX document, or nil if the document
+// has none. The subject is the first package the document describes.
+func NewMetadataFromSPDX(spdx *SPDXDocument) *Metadata {
+	m := &Metadata{SerialNumber: spdx.DocumentNamespace}
+	if p := spdx.describedPackage(); p != nil {
+		m.Subject, m.Name, m.Version = p.Purl(), p.Name, p.VersionInfo
+		m.Supplier = spdxParty(p.Supplier)
+	} else {
+		m.Name = spdx.Name
+	}
+	if spdx.CreationInfo != nil {
+		m.Timestamp = spdx.CreationInfo.Created
+		for _, creator := range spdx.CreationInfo.Creators {
+			kind, value, _ := strings.Cut(creator, ":")
+			value = strings.TrimSpace(value)
+			switch strings.TrimSpace(kind) {
+			case "Tool":
+				if m.Tool == "" {
+					m.Tool, m.ToolVersion = splitToolVersion(value)
+				}
+			case "Person", "Organization":
+				m.Author = FirstNonEmpty(m.Author, spdxParty(creator))
+			}
+		}
+	}
+	if m.IsEmpty() {
+		return nil
+	}
+	return m
+}
+
+// spdxParty returns the name of an SPDX person or organization such as
+// "Organization: Example Inc. (security@example.com)", without its kind or email.
+func spdxParty(value string) string {
+	value = spdxValue(value)
+	if _, name, ok := strings.Cut(value, ":"); ok {
+		value = name
+	}
+	if i := strings.Index(value, "("); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// splitToolVersion splits an SPDX tool such as "syft-0.98.0" into its name and version.
+func splitToolVersion(tool string) (name string, version string) {
+	i := strings.LastIndex(tool, "-")
+	if i <= 0 || i == len(tool)-1 || tool[i+1] < '0' || tool[i+1] > '9' {
+		return tool, ""
+	}
+	return tool[:i], tool[i+1:]
+}
+
+// IsEmpty returns true if none of the metadata fields are set.
+func (m *Metadata) IsEmpty() bool {
+	return m == nil || *m == Metadata{}
+}
+
+// Fields returns the names and values of the metadata fields that are set, in the
+// order they are declared.
+func (m *Metadata) Fields() (fields [][2]string) {
+	if m == nil {
+		return
+	}
+	for _, f := range [][2]string{
+		{"subject", m.Subject},
+		{"name", m.Name},
+		{"version", m.Version},
+		{"author", m.Author},
+		{"supplier", m.Supplier},
+		{"timestamp", m.Timestamp},
+		{"serialNumber", m.SerialNumber},
+		{"digest", m.Digest},
+		{"tool", m.Tool},
+		{"toolVersion", m.ToolVersion},
+	} {
+		if f[1] != "" {
+			fields = append(fields, f)
+		}
+	}
+	return
+}
+
+// csvHeader returns the metadata as comment lines placed before the CSV header row.
+func (m *Metadata) csvHeader() string {
+	var b strings.Builder
+	for _, f := range m.Fields() {
+		fmt.Fprintf(&b, "# %s: %s\n", f[0], strings.ReplaceAll(f[1], "\n", " "))
+	}
+	return b.String()
+}
+
+// cycloneDX returns the metadata as CycloneDX metadata, recording the serial number
+// and digest of the source document as properties. Returns nil if it is empty.
+func (m *Metadata) cycloneDX() *cyclonedx.Metadata {
+	if m.IsEmpty() {
+		return nil
+	}
+	metadata := &cyclonedx.Metadata{Timestamp: m.Timestamp}
+	if m.Subject != "" || m.Name != "" || m.Version != "" {
+		metadata.Component = &cyclonedx.Component{
+			BOMRef:     m.Subject,
+			Name:       m.Name,
+			Version:    m.Version,
+			PackageURL: m.Subject,
+		}
+	}
+	if m.Author != "" {
+		metadata.Authors = &[]cyclonedx.OrganizationalContact{{Name: m.Author}}
+	}
+	if m.Supplier != "" {
+		metadata.Supplier = &cyclonedx.OrganizationalEntity{Name: m.Supplier}
+	}
+	if m.Tool != "" {
+		metadata.Tools = &cyclonedx.ToolsChoice{Components: &[]cyclonedx.Component{
+			{Type: cyclonedx.ComponentTypeApplication, Name: m.Tool, Version: m.ToolVersion},
+		}}
+	}
+	properties := []cyclonedx.Property{}
+	if m.SerialNumber != "" {
+		properties = append(properties, cyclonedx.Property{Name: PropertySerialNumber, Value: m.SerialNumber})
+	}
+	if m.Digest != "" {
+		properties = append(properties, cyclonedx.Property{Name: PropertyDigest, Value: m.Digest})
+	}
+	if len(properties) > 0 {
+		metadata.Properties = &properties
+	}
+	return metadata
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetadataFromCycloneDX(t *testing.T) {
+	bom := &cyclonedx.BOM{
+		SerialNumber: "urn:uuid:1f860713-54b9-4253-ba5a-9554851904af",
+		Metadata: &cyclonedx.Metadata{
+			Timestamp: "2020-08-03T03:20:53.771Z",
+			Tools:     &cyclonedx.ToolsChoice{Tools: &[]cyclonedx.Tool{{Vendor: "CycloneDX", Name: "Node.js module", Version: "2.0.0"}}},
+			Component: &cyclonedx.Component{
+				Name:       "juice-shop",
+				Version:    "11.1.2",
+				PackageURL: "pkg:npm/juice-shop@11.1.2",
+				Publisher:  "OWASP",
+			},
+			Manufacture: &cyclonedx.OrganizationalEntity{Name: "OWASP Foundation"},
+		},
+	}
+
+	assert.Equal(t, &Metadata{
+		Subject:      "pkg:npm/juice-shop@11.1.2",
+		Name:         "juice-shop",
+		Version:      "11.1.2",
+		Author:       "OWASP",
+		Supplier:     "OWASP Foundation",
+		Timestamp:    "2020-08-03T03:20:53.771Z",
+		SerialNumber: "urn:uuid:1f860713-54b9-4253-ba5a-9554851904af",
+		Tool:         "Node.js module",
+		ToolVersion:  "2.0.0",
+	}, NewMetadataFromCycloneDX(bom))
+
+	bom.Metadata.Authors = &[]cyclonedx.OrganizationalContact{{Name: "Björn Kimminich"}}
+	bom.Metadata.Supplier = &cyclonedx.OrganizationalEntity{Name: "Juice Shop"}
+	bom.Metadata.Tools = &cyclonedx.ToolsChoice{Components: &[]cyclonedx.Component{{Name: "cdxgen", Version: "10.0.0"}}}
+	metadata := NewMetadataFromCycloneDX(bom)
+	assert.Equal(t, "Björn Kimminich", metadata.Author)
+	assert.Equal(t, "Juice Shop", metadata.Supplier)
+	assert.Equal(t, "cdxgen", metadata.Tool)
+
+	assert.Nil(t, NewMetadataFromCycloneDX(&cyclonedx.BOM{}))
+}
+
+func TestNewMetadataFromSPDX(t *testing.T) {
+	spdx := &SPDXDocument{
+		Name:              "juice-shop",
+		DocumentNamespace: "https://example.com/spdx/juice-shop-1234",
+		CreationInfo: &SPDXCreation{
+			Created:  "2024-01-02T03:04:05Z",
+			Creators: []string{"Organization: Example Inc. (security@example.com)", "Tool: syft-0.98.0", "Tool: other"},
+		},
+		Packages: []SPDXPackage{
+			{SPDXID: "SPDXRef-lodash", Name: "lodash"},
+			{
+				SPDXID:       "SPDXRef-juice-shop",
+				Name:         "juice-shop",
+				VersionInfo:  "11.1.2",
+				Supplier:     "Organization: OWASP",
+				ExternalRefs: []SPDXExternalRef{{Type: "purl", Locator: "pkg:npm/juice-shop@11.1.2"}},
+			},
+		},
+		Relationships: []SPDXRelationship{{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-juice-shop"}},
+	}
+
+	assert.Equal(t, &Metadata{
+		Subject:      "pkg:npm/juice-shop@11.1.2",
+		Name:         "juice-shop",
+		Version:      "11.1.2",
+		Author:       "Example Inc.",
+		Supplier:     "OWASP",
+		Timestamp:    "2024-01-02T03:04:05Z",
+		SerialNumber: "https://example.com/spdx/juice-shop-1234",
+		Tool:         "syft",
+		ToolVersion:  "0.98.0",
+	}, NewMetadataFromSPDX(spdx))
+
+	spdx.Relationships = nil
+	spdx.DocumentDescribes = []string{"SPDXRef-lodash"}
+	assert.Equal(t, "lodash", NewMetadataFromSPDX(spdx).Name)
+
+	spdx.DocumentDescribes = nil
+	assert.Equal(t, "juice-shop", NewMetadataFromSPDX(spdx).Name)
+	assert.Empty(t, NewMetadataFromSPDX(spdx).Subject)
+
+	assert.Nil(t, NewMetadataFromSPDX(&SPDXDocument{}))
+}
+
+func TestSplitToolVersion(t *testing.T) {
+	tests := map[string][2]string{
+		"syft-0.98.0":         {"syft", "0.98.0"},
+		"spdx-sbom-generator": {"spdx-sbom-generator", ""},
+		"trivy":               {"trivy", ""},
+		"tool-":               {"tool-", ""},
+	}
+	for tool, expected := range tests {
+		name, version := splitToolVersion(tool)
+		assert.Equal(t, expected, [2]string{name, version}, tool)
+	}
+}
+
+func TestMetadata_CycloneDX(t *testing.T) {
+	metadata := &Metadata{
+		Subject:      "pkg:npm/juice-shop@11.1.2",
+		Name:         "juice-shop",
+		Author:       "OWASP",
+		SerialNumber: "urn:uuid:1234",
+		Digest:       DocumentDigest([]byte("{}")),
+		Tool:         "cdxgen",
+	}
+
+	cdx := metadata.cycloneDX()
+	assert.Equal(t, "pkg:npm/juice-shop@11.1.2", cdx.Component.PackageURL)
+	assert.Equal(t, "OWASP", (*cdx.Authors)[0].Name)
+	assert.Nil(t, cdx.Supplier)
+	assert.Equal(t, "cdxgen", (*cdx.Tools.Components)[0].Name)
+	assert.Equal(t, []cyclonedx.Property{
+		{Name: PropertySerialNumber, Value: "urn:uuid:1234"},
+		{Name: PropertyDigest, Value: "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"},
+	}, *cdx.Properties)
+
+	assert.Nil(t, (&Metadata{}).cycloneDX())
+	assert.Nil(t, (*Metadata)(nil).cycloneDX())
+	assert.Empty(t, (*Metadata)(nil).csvHeader())
+}
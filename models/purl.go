@@ -0,0 +1,18 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// CanonicalPurl returns the canonical string form of a Package URL so that
+// equivalent PURLs (differing only in casing, qualifier order or encoding)
+// compare as equal. If the PURL cannot be parsed, the trimmed input is returned.
+func CanonicalPurl(purl string) string {
+	p, err := packageurl.FromString(strings.TrimSpace(purl))
+	if err != nil {
+		return strings.TrimSpace(purl)
+	}
+	return p.ToString()
+}
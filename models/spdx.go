@@ -0,0 +1,193 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Enumeration of SPDX license expression operators.
+const (
+	SPDXAnd = "AND" // SPDXAnd requires both licenses to be satisfied.
+	SPDXOr  = "OR"  // SPDXOr allows a choice between licenses.
+)
+
+// LicenseExpression is a parsed SPDX license expression. Leaf nodes hold a single
+// license identifier, while compound nodes combine their Left and Right operands
+// with the AND or OR operator.
+type LicenseExpression struct {
+	Operator  string             // SPDXAnd or SPDXOr for compound expressions, empty for a single license.
+	Left      *LicenseExpression // Left operand of a compound expression.
+	Right     *LicenseExpression // Right operand of a compound expression.
+	ID        string             // License identifier (or LicenseRef) of a single license.
+	OrLater   bool               // True if the license identifier was followed by "+".
+	Exception string             // License exception identifier following WITH, if any.
+}
+
+// ParseLicenseExpression parses an SPDX license expression such as
+// "(MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0".
+// Operators are matched without regard to case.
+func ParseLicenseExpression(expression string) (*LicenseExpression, error) {
+	tokens := tokenizeLicenseExpression(expression)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	parser := &licenseParser{tokens: tokens}
+	e, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected %q in license expression %q", tokens[parser.pos], expression)
+	}
+	return e, nil
+}
+
+// IsCompound returns true if the expression combines licenses with AND or OR.
+func (e *LicenseExpression) IsCompound() bool {
+	return e.Operator != ""
+}
+
+// Licenses returns every single license of the expression, from left to right.
+func (e *LicenseExpression) Licenses() (licenses []*LicenseExpression) {
+	if !e.IsCompound() {
+		return []*LicenseExpression{e}
+	}
+	return append(e.Left.Licenses(), e.Right.Licenses()...)
+}
+
+// Evaluate ranks the expression using the provided function for single licenses.
+// An AND expression takes the highest rank of its operands (every license applies),
+// while an OR expression takes the lowest rank (the best choice may be made).
+func (e *LicenseExpression) Evaluate(rank func(license *LicenseExpression) int) int {
+	if !e.IsCompound() {
+		return rank(e)
+	}
+	left, right := e.Left.Evaluate(rank), e.Right.Evaluate(rank)
+	if (e.Operator == SPDXAnd) == (left > right) {
+		return left
+	}
+	return right
+}
+
+// String returns the expression in normalized SPDX form.
+func (e *LicenseExpression) String() string {
+	if e.IsCompound() {
+		return fmt.Sprintf("%s %s %s", e.Left.operand(e.Operator), e.Operator, e.Right.operand(e.Operator))
+	}
+	s := e.ID
+	if e.OrLater {
+		s += "+"
+	}
+	if e.Exception != "" {
+		s += " WITH " + e.Exception
+	}
+	return s
+}
+
+// operand returns the expression as an operand of parent, adding parentheses
+// where operator precedence requires them.
+func (e *LicenseExpression) operand(parent string) string {
+	if e.IsCompound() && e.Operator != parent {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// tokenizeLicenseExpression splits an expression into parentheses and words.
+func tokenizeLicenseExpression(expression string) (tokens []string) {
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range expression {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return
+}
+
+// licenseParser is a recursive descent parser for SPDX license expressions.
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *licenseParser) parseOr() (*LicenseExpression, error) {
+	left, err := p.parseAnd()
+	for err == nil && strings.EqualFold(p.peek(), SPDXOr) {
+		p.pos++
+		var right *LicenseExpression
+		right, err = p.parseAnd()
+		left = &LicenseExpression{Operator: SPDXOr, Left: left, Right: right}
+	}
+	return left, err
+}
+
+func (p *licenseParser) parseAnd() (*LicenseExpression, error) {
+	left, err := p.parsePrimary()
+	for err == nil && strings.EqualFold(p.peek(), SPDXAnd) {
+		p.pos++
+		var right *LicenseExpression
+		right, err = p.parsePrimary()
+		left = &LicenseExpression{Operator: SPDXAnd, Left: left, Right: right}
+	}
+	return left, err
+}
+
+func (p *licenseParser) parsePrimary() (*LicenseExpression, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of license expression")
+	case token == "(":
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing \")\" in license expression")
+		}
+		p.pos++
+		return e, nil
+	case token == ")" || isLicenseOperator(token):
+		return nil, fmt.Errorf("unexpected %q in license expression", token)
+	}
+
+	p.pos++
+	e := &LicenseExpression{ID: strings.TrimSuffix(token, "+"), OrLater: strings.HasSuffix(token, "+")}
+	if strings.EqualFold(p.peek(), "WITH") {
+		p.pos++
+		exception := p.peek()
+		if exception == "" || exception == "(" || exception == ")" || isLicenseOperator(exception) {
+			return nil, fmt.Errorf("missing exception after WITH in license expression")
+		}
+		p.pos++
+		e.Exception = exception
+	}
+	return e, nil
+}
+
+// isLicenseOperator returns true if the token is an SPDX operator keyword.
+func isLicenseOperator(token string) bool {
+	return strings.EqualFold(token, SPDXAnd) || strings.EqualFold(token, SPDXOr) || strings.EqualFold(token, "WITH")
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"strings"
+)
+
+// Enumeration of SPDX values that stand in for missing information.
+const (
+	SPDXNoAssertion = "NOASSERTION" // SPDXNoAssertion means the value was not determined.
+	SPDXNone        = "NONE"        // SPDXNone means there is no value.
+)
+
+// SPDXDocument contains the parts of an SPDX 2.x JSON document used by kissbom.
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`       // Version of the SPDX specification (ex: SPDX-2.3).
+	Name              string             `json:"name"`              // Name of the document.
+	DocumentNamespace string             `json:"documentNamespace"` // Unique URI of the document.
+	DocumentDescribes []string           `json:"documentDescribes"` // SPDX identifiers of the packages the document describes.
+	CreationInfo      *SPDXCreation      `json:"creationInfo,omitempty"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+// SPDXCreation records when and by whom an SPDX document was created.
+type SPDXCreation struct {
+	Created  string   `json:"created"`  // ISO 8601 timestamp of when the document was created.
+	Creators []string `json:"creators"` // Creators of the document (ex: "Tool: syft-0.98.0").
+}
+
+// SPDXPackage is a package of an SPDX document.
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo"`
+	Supplier         string            `json:"supplier"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Summary          string            `json:"summary"`
+	Description      string            `json:"description"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose"`
+	Checksums        []SPDXChecksum    `json:"checksums"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs"`
+}
+
+// SPDXChecksum is a checksum of an SPDX package.
+type SPDXChecksum struct {
+	Algorithm string `json:"algorithm"`     // Checksum algorithm (ex: SHA256).
+	Value     string `json:"checksumValue"` // Hexadecimal checksum value.
+}
+
+// SPDXExternalRef is a reference from an SPDX package to an external identifier.
+type SPDXExternalRef struct {
+	Category string `json:"referenceCategory"` // Category of the reference (ex: PACKAGE-MANAGER).
+	Type     string `json:"referenceType"`     // Type of the reference (ex: purl).
+	Locator  string `json:"referenceLocator"`  // The identifier itself.
+}
+
+// SPDXRelationship relates two elements of an SPDX document.
+type SPDXRelationship struct {
+	Element string `json:"spdxElementId"`      // SPDX identifier of the element the relationship is from.
+	Type    string `json:"relationshipType"`   // Type of the relationship (ex: DESCRIBES).
+	Related string `json:"relatedSpdxElement"` // SPDX identifier of the element the relationship is to.
+}
+
+// describedPackage returns the first package the document describes, or nil if it
+// describes none.
+func (d *SPDXDocument) describedPackage() *SPDXPackage {
+	described := append([]string{}, d.DocumentDescribes...)
+	for _, r := range d.Relationships {
+		if r.Element == "SPDXRef-DOCUMENT" && r.Type == "DESCRIBES" {
+			described = append(described, r.Related)
+		}
+	}
+	for _, id := range described {
+		for i := range d.Packages {
+			if d.Packages[i].SPDXID == id {
+				return &d.Packages[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Purl returns the Package URL of the package, or an empty string if it has none.
+func (p SPDXPackage) Purl() string {
+	for _, ref := range p.ExternalRefs {
+		if strings.EqualFold(ref.Type, "purl") {
+			return strings.TrimSpace(ref.Locator)
+		}
+	}
+	return ""
+}
+
+// NewKissBOMFromSPDX converts an SPDX document to a KissBOM, including its metadata and
+// dependencies. Packages without a Package URL, or that are not allowed by every provided
+// Filter, are skipped. The concluded license is preferred over the declared license, and
+// checksums become hashes.
+func NewKissBOMFromSPDX(spdx *SPDXDocument, filters ...Filter) (kissbom KissBOM) {
+	kissbom.Metadata = NewMetadataFromSPDX(spdx)
+	kissbom.Dependencies = spdxDependencies(spdx)
+	for _, p := range spdx.Packages {
+		purl := p.Purl()
+		if purl == "" || !allowedSPDX(purl, p, filters) {
+			continue
+		}
+		kissbom.Packages = append(kissbom.Packages, Package{
+			Purl:      purl,
+			License:   FirstNonEmpty(spdxValue(p.LicenseConcluded), spdxValue(p.LicenseDeclared)),
+			Copyright: spdxValue(p.CopyrightText),
+			Notes:     FirstNonEmpty(p.Summary, p.Description),
+			Hashes:    spdxHashes(p.Checksums),
+		})
+	}
+	return
+}
+
+// allowedSPDX returns true if the package is allowed by every provided filter. The
+// primary package purpose is used as the component type.
+func allowedSPDX(purl string, p SPDXPackage, filters []Filter) bool {
+	for _, f := range filters {
+		if !f.Allows(purl, "", strings.ToLower(p.PrimaryPurpose)) {
+			return false
+		}
+	}
+	return true
+}
+
+// spdxValue returns the value, or an empty string if it is NOASSERTION or NONE.
+func spdxValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == SPDXNoAssertion || value == SPDXNone {
+		return ""
+	}
+	return value
+}
+
+// spdxHashes returns the checksums keyed by CycloneDX algorithm name, or nil if there
+// are none.
+func spdxHashes(checksums []SPDXChecksum) map[string]string {
+	hashes := map[string]string{}
+	for _, c := range checksums {
+		if c.Algorithm != "" && c.Value != "" {
+			hashes[SPDXChecksumAlgorithm(c.Algorithm)] = c.Value
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKissBOMFromSPDX(t *testing.T) {
+	spdx := &SPDXDocument{
+		SPDXVersion: "SPDX-2.3",
+		Packages: []SPDXPackage{
+			{
+				Name:             "lodash",
+				LicenseConcluded: "MIT",
+				LicenseDeclared:  "NOASSERTION",
+				CopyrightText:    "Copyright OpenJS Foundation",
+				Summary:          "Lodash modular utilities.",
+				PrimaryPurpose:   "LIBRARY",
+				Checksums:        []SPDXChecksum{{Algorithm: "SHA1", Value: "abc123"}, {Algorithm: "SHA512", Value: "def456"}},
+				ExternalRefs: []SPDXExternalRef{
+					{Category: "SECURITY", Type: "cpe23Type", Locator: "cpe:2.3:a:lodash:lodash:4.17.21:*:*:*:*:*:*:*"},
+					{Category: "PACKAGE-MANAGER", Type: "purl", Locator: "pkg:npm/lodash@4.17.21"},
+				},
+			},
+			{
+				Name:             "requests",
+				LicenseConcluded: "NOASSERTION",
+				LicenseDeclared:  "Apache-2.0",
+				CopyrightText:    "NONE",
+				PrimaryPurpose:   "APPLICATION",
+				ExternalRefs:     []SPDXExternalRef{{Category: "PACKAGE_MANAGER", Type: "purl", Locator: "pkg:pypi/requests@2.26.0"}},
+			},
+			{Name: "no-purl"},
+		},
+	}
+
+	kissbom := NewKissBOMFromSPDX(spdx)
+	assert.Equal(t, []Package{
+		{
+			Purl:      "pkg:npm/lodash@4.17.21",
+			License:   "MIT",
+			Copyright: "Copyright OpenJS Foundation",
+			Notes:     "Lodash modular utilities.",
+			Hashes:    map[string]string{"SHA-1": "abc123", "SHA-512": "def456"},
+		},
+		{Purl: "pkg:pypi/requests@2.26.0", License: "Apache-2.0"},
+	}, kissbom.Packages)
+
+	kissbom = NewKissBOMFromSPDX(spdx, Filter{ComponentTypes: []string{"library"}})
+	assert.Len(t, kissbom.Packages, 1)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", kissbom.Packages[0].Purl)
+
+	assert.Empty(t, NewKissBOMFromSPDX(&SPDXDocument{}).Packages)
+}
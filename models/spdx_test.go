@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := map[string]string{
+		"MIT":                                            "MIT",
+		"(AFL-2.1 OR BSD-3-Clause)":                      "AFL-2.1 OR BSD-3-Clause",
+		"MIT or Apache-2.0 and BSD-2-Clause":             "MIT OR (Apache-2.0 AND BSD-2-Clause)",
+		"(MIT OR Apache-2.0) AND BSD-2-Clause":           "(MIT OR Apache-2.0) AND BSD-2-Clause",
+		"GPL-2.0+ WITH Classpath-exception-2.0":          "GPL-2.0+ WITH Classpath-exception-2.0",
+		"LicenseRef-Proprietary AND (((ISC)))":           "LicenseRef-Proprietary AND ISC",
+		"Apache-2.0 AND MIT AND BSD-3-Clause":            "Apache-2.0 AND MIT AND BSD-3-Clause",
+		"GPL-2.0-only with Classpath-exception-2.0 OR X": "GPL-2.0-only WITH Classpath-exception-2.0 OR X",
+	}
+	for input, expected := range tests {
+		e, err := ParseLicenseExpression(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, e.String(), input)
+	}
+
+	for _, input := range []string{"", "MIT OR", "(MIT", "MIT)", "AND MIT", "MIT WITH", "MIT Apache-2.0"} {
+		_, err := ParseLicenseExpression(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestLicenseExpression_Evaluate(t *testing.T) {
+	ranks := map[string]int{"MIT": 0, "LGPL-2.1-only": 1, "AGPL-3.0-only": 2}
+	rank := func(license *LicenseExpression) int {
+		return ranks[license.ID]
+	}
+
+	e, _ := ParseLicenseExpression("MIT OR AGPL-3.0-only")
+	assert.Equal(t, 0, e.Evaluate(rank))
+	assert.Len(t, e.Licenses(), 2)
+
+	e, _ = ParseLicenseExpression("MIT AND AGPL-3.0-only")
+	assert.Equal(t, 2, e.Evaluate(rank))
+
+	e, _ = ParseLicenseExpression("(MIT OR AGPL-3.0-only) AND LGPL-2.1-only")
+	assert.Equal(t, 1, e.Evaluate(rank))
+}
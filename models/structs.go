@@ -4,6 +4,7 @@ package models
 import (
 	"bytes"
 	"encoding/json"
+	"strconv"
 
 	"github.com/CycloneDX/cyclonedx-go"
 	"github.com/gocarina/gocsv"
@@ -21,15 +22,21 @@ const (
 
 // KissBOM represents a collection of packages.
 type KissBOM struct {
-	Packages []Package `json:"packages"` // Packages is a slice of Package structs, serialized as "packages" in JSON.
+	Metadata     *Metadata           `json:"metadata,omitempty" yaml:"metadata,omitempty"`         // Metadata optionally describes the subject and source of the KissBOM, omitted in strict mode.
+	Packages     []Package           `json:"packages"`                                             // Packages is a slice of Package structs, serialized as "packages" in JSON.
+	Dependencies map[string][]string `json:"dependencies,omitempty" yaml:"dependencies,omitempty"` // Dependencies optionally maps PURLs to the PURLs they depend on, omitted in strict mode.
+	Canonical    bool                `json:"-" yaml:"-"`                                           // Canonical enables sorted packages, canonical PURLs and canonical JSON encoding.
+	Strict       bool                `json:"-" yaml:"-"`                                           // Strict limits output to the packages and the purl, license, copyright and notes fields of the specification.
 }
 
 // Package represents information about a software package.
 type Package struct {
-	Purl      string `json:"purl" csv:"purl" yaml:"purl"`                                    // Purl is the Package URL, a unique identifier for the package.
-	License   string `json:"license,omitempty" csv:"license" yaml:"license,omitempty"`       // License is the software license associated with the package, omitempty allows for optional serialization.
-	Copyright string `json:"copyright,omitempty" csv:"copyright" yaml:"copyright,omitempty"` // Copyright is information about the package's copyright, omitempty allows for optional serialization.
-	Notes     string `json:"notes,omitempty" csv:"notes" yaml:"notes,omitempty"`             // Notes is additional notes or comments about the package, omitempty allows for optional serialization.
+	Purl      string            `json:"purl" csv:"purl" yaml:"purl"`                                    // Purl is the Package URL, a unique identifier for the package.
+	License   string            `json:"license,omitempty" csv:"license" yaml:"license,omitempty"`       // License is the software license associated with the package, omitempty allows for optional serialization.
+	Copyright string            `json:"copyright,omitempty" csv:"copyright" yaml:"copyright,omitempty"` // Copyright is information about the package's copyright, omitempty allows for optional serialization.
+	Notes     string            `json:"notes,omitempty" csv:"notes" yaml:"notes,omitempty"`             // Notes is additional notes or comments about the package, omitempty allows for optional serialization.
+	Hashes    map[string]string `json:"hashes,omitempty" csv:"-" yaml:"hashes,omitempty"`               // Hashes maps hash algorithms (ex: SHA-256) to values, omitted in strict mode.
+	Depth     int               `json:"depth,omitempty" csv:"-" yaml:"depth,omitempty"`                 // Depth is the distance from the subject in the dependency graph, 1 for direct dependencies and 0 when unknown, omitted in strict mode.
 }
 
 // NewKissBOMFromCycloneDX creates a new KissBOM (Keep It Simple Software Bill of Materials)
@@ -39,13 +46,19 @@ type Package struct {
 //
 // Parameters:
 //   - cdx: A pointer to a CycloneDX BOM containing information about software components.
+//   - filters: Optional filters that determine which components are kept.
 //
 // Returns:
 //   - kissbom: A KissBOM representation derived from the CycloneDX BOM.
 //
 // NewKissBOMFromCycloneDX converts a CycloneDX BOM (Bill of Materials) to a KissBOM
 // (KISS Build of Materials) by extracting relevant information from each component.
-func NewKissBOMFromCycloneDX(cdx *cyclonedx.BOM) (kissbom KissBOM) {
+// Components that are not allowed by every provided Filter are skipped, and the BOM
+// metadata and dependencies are kept as the KissBOM Metadata and Dependencies.
+func NewKissBOMFromCycloneDX(cdx *cyclonedx.BOM, filters ...Filter) (kissbom KissBOM) {
+	kissbom.Metadata = NewMetadataFromCycloneDX(cdx)
+	kissbom.Dependencies = extractDependencies(cdx)
+
 	// Check if the Components list is nil
 	if cdx.Components == nil {
 		// If nil, return an empty KissBOM
@@ -54,12 +67,13 @@ func NewKissBOMFromCycloneDX(cdx *cyclonedx.BOM) (kissbom KissBOM) {
 
 	// Iterate through each component and populate the KissBOM Packages
 	for _, component := range *cdx.Components {
-		if component.PackageURL != "" {
+		if component.PackageURL != "" && allowed(component, filters) {
 			kissbom.Packages = append(kissbom.Packages, Package{
 				Purl:      component.PackageURL,
 				License:   extractLicense(component),
 				Copyright: component.Copyright,
 				Notes:     component.Description,
+				Hashes:    extractHashes(component),
 			})
 		}
 	}
@@ -68,6 +82,16 @@ func NewKissBOMFromCycloneDX(cdx *cyclonedx.BOM) (kissbom KissBOM) {
 	return
 }
 
+// allowed returns true if the component is allowed by every provided filter.
+func allowed(component cyclonedx.Component, filters []Filter) bool {
+	for _, f := range filters {
+		if !f.Allows(component.PackageURL, string(component.Scope), string(component.Type)) {
+			return false
+		}
+	}
+	return true
+}
+
 // extractLicense extracts the license expression from a CycloneDX component.
 // If the component has licenses, it returns the expression of the first license;
 // otherwise, it returns an empty string.
@@ -81,19 +105,47 @@ func extractLicense(component cyclonedx.Component) string {
 
 // JSON converts the KissBOM struct to JSON format
 func (k *KissBOM) JSON() ([]byte, error) {
-	return json.MarshalIndent(k, "", "    ")
+	kissbom := k.output()
+	if k.Canonical {
+		return canonicalJSON(kissbom)
+	}
+	return json.MarshalIndent(kissbom, "", "    ")
 }
 
 // YAML converts the KissBOM struct to YAML format
 func (k *KissBOM) YAML() ([]byte, error) {
-	return yaml.Marshal(k)
+	kissbom := k.output()
+	return yaml.Marshal(&kissbom)
 }
 
-// CSV converts the KissBOM struct to CSV format using gocsv
+// csvPackage is a row of the CSV output format.
+type csvPackage struct {
+	Package
+	LicenseCategory string `csv:"license_category"` // LicenseCategory is the category of the package license.
+	HashList        string `csv:"hashes"`           // HashList is the package hashes as algorithm:value pairs separated by semicolons.
+	Depth           string `csv:"depth"`            // Depth is the depth of the package in the dependency graph, empty when unknown.
+}
+
+// CSV converts the KissBOM struct to CSV format using gocsv. Metadata is written as
+// "# name: value" comment lines before the header row. In strict mode only the purl,
+// license, copyright and notes columns are written.
 func (k *KissBOM) CSV() ([]byte, error) {
+	kissbom := k.output()
+	packages := kissbom.Packages
+	if k.Strict {
+		c, err := gocsv.MarshalString(&packages)
+		return []byte(c), err
+	}
+	rows := make([]csvPackage, len(packages))
+	for i, p := range packages {
+		rows[i] = csvPackage{Package: p, LicenseCategory: LicenseCategory(p.License), HashList: formatHashes(p.Hashes)}
+		if p.Depth > 0 {
+			rows[i].Depth = strconv.Itoa(p.Depth)
+		}
+	}
 	// Encode KissBOM to CSV
-	c, err := gocsv.MarshalString(&k.Packages)
-	return []byte(c), err
+	c, err := gocsv.MarshalString(&rows)
+	return []byte(kissbom.Metadata.csvHeader() + c), err
 }
 
 // Minimal converts the KissBOM struct to a JSON format with only the PURLs
@@ -104,23 +156,32 @@ func (k *KissBOM) Minimal() ([]byte, error) {
 			Purl: p.Purl,
 		})
 	}
+	if k.Canonical {
+		return canonicalJSON(kissbom.canonicalized())
+	}
 	return json.MarshalIndent(kissbom, "", "    ")
 }
 
 // Compatible generates a CycloneDX Bill of Materials (BOM) based on the packages
 // stored in the KissBOM instance. Each package's PackageURL is used to create
-// corresponding CycloneDX components, and these components are added to the BOM.
+// corresponding CycloneDX components, and these components are added to the BOM
+// along with any Metadata.
 // The resulting BOM is then encoded to a byte slice using the JSON format.
 //
 // Returns:
 //   - The encoded BOM as a byte slice.
 //   - An error if there was any issue during encoding.
 func (k *KissBOM) Compatible() ([]byte, error) {
+	kissbom := k.output()
+	packages := kissbom.Packages
+
 	bom := cyclonedx.NewBOM()
+	bom.Metadata = kissbom.Metadata.cycloneDX()
 	components := []cyclonedx.Component{}
-	for _, c := range k.Packages {
+	for _, c := range packages {
 		component := cyclonedx.Component{
 			PackageURL: c.Purl,
+			Hashes:     cycloneDXHashes(c.Hashes),
 		}
 		components = append(components, component)
 	}
@@ -129,6 +190,28 @@ func (k *KissBOM) Compatible() ([]byte, error) {
 	return k.encodeBOM(bom)
 }
 
+// output returns a copy of the KissBOM as it should be encoded: canonicalized when
+// Canonical is set, and without metadata, dependencies, hashes or depths when Strict
+// is set.
+func (k *KissBOM) output() KissBOM {
+	kissbom := *k
+	if k.Canonical {
+		kissbom = k.canonicalized()
+	}
+	if k.Strict {
+		kissbom.Metadata = nil
+		kissbom.Dependencies = nil
+		packages := make([]Package, len(kissbom.Packages))
+		for i, p := range kissbom.Packages {
+			p.Hashes = nil
+			p.Depth = 0
+			packages[i] = p
+		}
+		kissbom.Packages = packages
+	}
+	return kissbom
+}
+
 // encodeBOM encodes a given CycloneDX BOM to a byte slice using the JSON format.
 //
 // Parameters:
@@ -141,5 +224,8 @@ func (k *KissBOM) encodeBOM(bom *cyclonedx.BOM) ([]byte, error) {
 	var buf bytes.Buffer
 	encoder := cyclonedx.NewBOMEncoder(&buf, cyclonedx.BOMFileFormatJSON)
 	err := encoder.Encode(bom)
+	if err == nil && k.Canonical {
+		return canonicalizeJSON(buf.Bytes())
+	}
 	return buf.Bytes(), err
 }
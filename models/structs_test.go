@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/CycloneDX/cyclonedx-go"
@@ -35,10 +36,20 @@ func TestNewKissBOMFromCycloneDX(t *testing.T) {
 	assert.Equal(t, "pkg:pypi/requests@2.26.1", kissBOM.Packages[1].Purl)
 	assert.Equal(t, "Package 2 description", kissBOM.Packages[1].Notes)
 
+	assert.Nil(t, kissBOM.Packages[0].Hashes)
+
+	mockComponents[0].Hashes = &[]cyclonedx.Hash{{Algorithm: cyclonedx.HashAlgoSHA256, Value: "abc123"}}
+	kissBOM = NewKissBOMFromCycloneDX(mockBOM)
+	assert.Equal(t, map[string]string{"SHA-256": "abc123"}, kissBOM.Packages[0].Hashes)
+
+	assert.Nil(t, kissBOM.Metadata)
+
 	mockBOM.Components = nil
+	mockBOM.SerialNumber = "urn:uuid:1234"
 
 	kissBOM = NewKissBOMFromCycloneDX(mockBOM)
 	assert.Len(t, kissBOM.Packages, 0)
+	assert.Equal(t, "urn:uuid:1234", kissBOM.Metadata.SerialNumber)
 }
 
 func TestExtractLicense(t *testing.T) {
@@ -107,9 +118,10 @@ func TestKissBOM_CSV(t *testing.T) {
 
 	// Add assertions based on the expected CSV format
 	// For simplicity, we'll just check if certain keywords are present in the CSV data
-	assert.Contains(t, string(csvData), "purl,license,copyright,notes")
+	assert.Contains(t, string(csvData), "purl,license,copyright,notes,license_category")
 	assert.Contains(t, string(csvData), "pkg:pypi/requests@2.26.0,MIT,Copyright 2023,Some notes")
-	assert.Contains(t, string(csvData), "pkg:pypi/requests@2.26.1,Apache 2.0,Copyright 2023,")
+	assert.Contains(t, string(csvData), "pkg:pypi/requests@2.26.0,MIT,Copyright 2023,Some notes,permissive")
+	assert.Contains(t, string(csvData), "pkg:pypi/requests@2.26.1,Apache 2.0,Copyright 2023,,unknown")
 
 	// You may also consider using a CSV parsing library to validate the CSV structure more precisely
 }
@@ -208,3 +220,91 @@ func TestEncodeBOM_Success(t *testing.T) {
 	assert.NotNil(t, result, "Expected result to be not nil")
 	// Add additional assertions as needed
 }
+
+func TestKissBOM_Hashes(t *testing.T) {
+	kissBOM := KissBOM{
+		Packages: []Package{
+			{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Hashes: map[string]string{"SHA-512": "def456", "SHA-1": "abc123"}},
+			{Purl: "pkg:npm/ms@2.0.0"},
+		},
+	}
+
+	jsonData, err := kissBOM.JSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(jsonData), `"hashes": {`)
+	var unmarshalledBOM KissBOM
+	assert.NoError(t, json.Unmarshal(jsonData, &unmarshalledBOM))
+	assert.Equal(t, kissBOM, unmarshalledBOM)
+
+	yamlData, err := kissBOM.YAML()
+	assert.NoError(t, err)
+	assert.Contains(t, string(yamlData), "SHA-1: abc123")
+
+	csvData, err := kissBOM.CSV()
+	assert.NoError(t, err)
+	assert.Contains(t, string(csvData), "purl,license,copyright,notes,license_category,hashes")
+	assert.Contains(t, string(csvData), "pkg:npm/lodash@4.17.21,MIT,,,permissive,SHA-1:abc123;SHA-512:def456")
+	assert.Contains(t, string(csvData), "pkg:npm/ms@2.0.0,,,,unknown,")
+
+	compatibleData, err := kissBOM.Compatible()
+	assert.NoError(t, err)
+	assert.Contains(t, string(compatibleData), `{"alg":"SHA-1","content":"abc123"}`)
+}
+
+func TestKissBOM_Strict(t *testing.T) {
+	kissBOM := KissBOM{
+		Strict: true,
+		Packages: []Package{
+			{Purl: "pkg:npm/lodash@4.17.21", License: "MIT", Hashes: map[string]string{"SHA-1": "abc123"}},
+		},
+	}
+
+	for _, encode := range []func() ([]byte, error){kissBOM.JSON, kissBOM.YAML, kissBOM.CSV, kissBOM.Compatible} {
+		data, err := encode()
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "abc123")
+	}
+
+	csvData, err := kissBOM.CSV()
+	assert.NoError(t, err)
+	assert.Equal(t, "purl,license,copyright,notes\npkg:npm/lodash@4.17.21,MIT,,\n", string(csvData))
+	assert.Equal(t, map[string]string{"SHA-1": "abc123"}, kissBOM.Packages[0].Hashes)
+
+	kissBOM.Canonical = true
+	jsonData, err := kissBOM.JSON()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(jsonData), "hashes")
+}
+
+func TestKissBOM_Metadata(t *testing.T) {
+	kissBOM := KissBOM{
+		Metadata: &Metadata{Subject: "pkg:npm/juice-shop@11.1.2", Name: "juice-shop", Timestamp: "2020-08-03T03:20:53.771Z"},
+		Packages: []Package{{Purl: "pkg:npm/lodash@4.17.21", License: "MIT"}},
+	}
+
+	jsonData, err := kissBOM.JSON()
+	assert.NoError(t, err)
+	var unmarshalledBOM KissBOM
+	assert.NoError(t, json.Unmarshal(jsonData, &unmarshalledBOM))
+	assert.Equal(t, kissBOM, unmarshalledBOM)
+
+	yamlData, err := kissBOM.YAML()
+	assert.NoError(t, err)
+	assert.Contains(t, string(yamlData), "metadata:\n    subject: pkg:npm/juice-shop@11.1.2")
+
+	csvData, err := kissBOM.CSV()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(csvData), "# subject: pkg:npm/juice-shop@11.1.2\n# name: juice-shop\n# timestamp: 2020-08-03T03:20:53.771Z\npurl,license,"))
+
+	compatibleData, err := kissBOM.Compatible()
+	assert.NoError(t, err)
+	assert.Contains(t, string(compatibleData), `"timestamp":"2020-08-03T03:20:53.771Z"`)
+	assert.Contains(t, string(compatibleData), `"purl":"pkg:npm/juice-shop@11.1.2"`)
+
+	kissBOM.Strict = true
+	for _, encode := range []func() ([]byte, error){kissBOM.JSON, kissBOM.YAML, kissBOM.CSV, kissBOM.Compatible} {
+		data, err := encode()
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "juice-shop")
+	}
+}
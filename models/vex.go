@@ -0,0 +1,359 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Enumeration of VEX statuses, following OpenVEX.
+const (
+	VEXNotAffected        = "not_affected"        // VEXNotAffected means the product is not affected by the vulnerability.
+	VEXAffected           = "affected"            // VEXAffected means actions are recommended to remediate the vulnerability.
+	VEXFixed              = "fixed"               // VEXFixed means the product contains a fix for the vulnerability.
+	VEXUnderInvestigation = "under_investigation" // VEXUnderInvestigation means it is not yet known if the product is affected.
+)
+
+// VEXStatuses contains all of the valid VEX statuses.
+var VEXStatuses = []string{VEXNotAffected, VEXAffected, VEXFixed, VEXUnderInvestigation}
+
+// VEXJustifications contains all of the valid OpenVEX justifications for the
+// not_affected status.
+var VEXJustifications = []string{
+	"component_not_present",
+	"vulnerable_code_not_present",
+	"vulnerable_code_not_in_execute_path",
+	"vulnerable_code_cannot_be_controlled_by_adversary",
+	"inline_mitigations_already_exist",
+}
+
+// OpenVEXContext is the JSON-LD context of OpenVEX documents produced by kissbom.
+const OpenVEXContext = "https://openvex.dev/ns/v0.2.0"
+
+// cdxStates maps CycloneDX impact analysis states to VEX statuses.
+var cdxStates = map[cyclonedx.ImpactAnalysisState]string{
+	cyclonedx.IASResolved:             VEXFixed,
+	cyclonedx.IASResolvedWithPedigree: VEXFixed,
+	cyclonedx.IASExploitable:          VEXAffected,
+	cyclonedx.IASInTriage:             VEXUnderInvestigation,
+	cyclonedx.IASFalsePositive:        VEXNotAffected,
+	cyclonedx.IASNotAffected:          VEXNotAffected,
+}
+
+// cdxJustifications maps CycloneDX impact analysis justifications to the closest
+// OpenVEX justification.
+var cdxJustifications = map[cyclonedx.ImpactAnalysisJustification]string{
+	cyclonedx.IAJCodeNotPresent:               "vulnerable_code_not_present",
+	cyclonedx.IAJCodeNotReachable:             "vulnerable_code_not_in_execute_path",
+	cyclonedx.IAJRequiresConfiguration:        "vulnerable_code_cannot_be_controlled_by_adversary",
+	cyclonedx.IAJRequiresDependency:           "component_not_present",
+	cyclonedx.IAJRequiresEnvironment:          "vulnerable_code_cannot_be_controlled_by_adversary",
+	cyclonedx.IAJProtectedByCompiler:          "inline_mitigations_already_exist",
+	cyclonedx.IAJProtectedAtRuntime:           "inline_mitigations_already_exist",
+	cyclonedx.IAJProtectedAtPerimeter:         "inline_mitigations_already_exist",
+	cyclonedx.IAJProtectedByMitigatingControl: "inline_mitigations_already_exist",
+}
+
+// VEX is a set of statements about the exploitability of vulnerabilities in packages.
+// Statements are maintained in a local YAML file, or read from CycloneDX VEX and
+// OpenVEX documents.
+type VEX struct {
+	ID         string         `yaml:"id"`         // Identifier (IRI) of the produced OpenVEX document.
+	Author     string         `yaml:"author"`     // Author of the produced OpenVEX document.
+	Timestamp  string         `yaml:"timestamp"`  // Time (RFC 3339) the statements were issued.
+	Statements []VEXStatement `yaml:"statements"` // Exploitability statements.
+}
+
+// VEXStatement declares the status of a vulnerability in one or more packages.
+type VEXStatement struct {
+	Vulnerability   string   `yaml:"vulnerability"` // Vulnerability identifier (ex: CVE-2020-8203 or GHSA-p6mc-m468-83gw).
+	Aliases         []string `yaml:"aliases"`       // Other identifiers of the vulnerability.
+	Products        []string `yaml:"products"`      // PURLs or glob patterns of the packages the statement applies to.
+	Status          string   `yaml:"status"`        // One of VEXStatuses.
+	Justification   string   `yaml:"justification"` // One of VEXJustifications, for not_affected statements.
+	ImpactStatement string   `yaml:"impact"`        // Why the packages are not affected.
+	ActionStatement string   `yaml:"action"`        // What should be done to remediate affected packages.
+	Timestamp       string   `yaml:"timestamp"`     // Time (RFC 3339) the statement was issued, if different from the document.
+}
+
+// ParseVEX decodes a CycloneDX VEX document, an OpenVEX document or a YAML statements file.
+func ParseVEX(data []byte) (vex VEX, err error) {
+	var probe struct {
+		BOMFormat string `json:"bomFormat"`
+		Context   string `json:"@context"`
+	}
+	if json.Unmarshal(data, &probe) == nil {
+		switch {
+		case probe.BOMFormat == cyclonedx.BOMFormat:
+			return parseCycloneDXVEX(data)
+		case strings.HasPrefix(probe.Context, "https://openvex.dev/ns"):
+			return parseOpenVEX(data)
+		}
+	}
+
+	if err = yaml.Unmarshal(data, &vex); err != nil {
+		return
+	}
+	return vex, vex.Validate()
+}
+
+// parseCycloneDXVEX reads the analysis of every vulnerability in a CycloneDX
+// document. Affected references are resolved to the PURLs of components in the
+// document, or used directly when they are PURLs.
+func parseCycloneDXVEX(data []byte) (vex VEX, err error) {
+	var cdx cyclonedx.BOM
+	if err = cyclonedx.NewBOMDecoder(bytes.NewReader(data), cyclonedx.BOMFileFormatJSON).Decode(&cdx); err != nil {
+		return
+	}
+	if cdx.Vulnerabilities == nil {
+		return
+	}
+
+	purls := map[string]string{}
+	if cdx.Components != nil {
+		for _, c := range *cdx.Components {
+			purls[c.BOMRef] = c.PackageURL
+		}
+	}
+
+	for _, v := range *cdx.Vulnerabilities {
+		if v.Analysis == nil || v.Affects == nil {
+			continue
+		}
+		statement := VEXStatement{
+			Vulnerability:   v.ID,
+			Status:          FirstNonEmpty(cdxStates[v.Analysis.State], VEXUnderInvestigation),
+			Justification:   cdxJustifications[v.Analysis.Justification],
+			ImpactStatement: v.Analysis.Detail,
+			Timestamp:       FirstNonEmpty(v.Analysis.LastUpdated, v.Analysis.FirstIssued),
+		}
+		if v.Analysis.Response != nil && statement.Status == VEXAffected {
+			responses := []string{}
+			for _, r := range *v.Analysis.Response {
+				responses = append(responses, string(r))
+			}
+			statement.ActionStatement = strings.Join(responses, ", ")
+		}
+		for _, a := range *v.Affects {
+			ref := a.Ref
+			if _, fragment, ok := strings.Cut(ref, "#"); ok && strings.HasPrefix(ref, "urn:cdx:") {
+				ref = fragment
+			}
+			if purl, ok := purls[ref]; ok && purl != "" {
+				ref = purl
+			}
+			if strings.HasPrefix(ref, "pkg:") {
+				statement.Products = append(statement.Products, ref)
+			}
+		}
+		if len(statement.Products) > 0 {
+			vex.Statements = append(vex.Statements, statement)
+		}
+	}
+	return
+}
+
+// openVEXDocument is an OpenVEX document.
+// See https://github.com/openvex/spec
+type openVEXDocument struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  string             `json:"timestamp"`
+	Version    int                `json:"version"`
+	Statements []openVEXStatement `json:"statements"`
+}
+
+type openVEXStatement struct {
+	Vulnerability struct {
+		ID      string   `json:"@id,omitempty"`
+		Name    string   `json:"name"`
+		Aliases []string `json:"aliases,omitempty"`
+	} `json:"vulnerability"`
+	Products        []openVEXProduct `json:"products"`
+	Status          string           `json:"status"`
+	Justification   string           `json:"justification,omitempty"`
+	ImpactStatement string           `json:"impact_statement,omitempty"`
+	ActionStatement string           `json:"action_statement,omitempty"`
+	Timestamp       string           `json:"timestamp,omitempty"`
+}
+
+type openVEXProduct struct {
+	ID          string `json:"@id"`
+	Identifiers struct {
+		Purl string `json:"purl,omitempty"`
+	} `json:"identifiers"`
+}
+
+// parseOpenVEX reads the statements of an OpenVEX document.
+func parseOpenVEX(data []byte) (vex VEX, err error) {
+	var doc openVEXDocument
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return
+	}
+	vex.ID, vex.Author, vex.Timestamp = doc.ID, doc.Author, doc.Timestamp
+	for _, s := range doc.Statements {
+		statement := VEXStatement{
+			Vulnerability:   FirstNonEmpty(s.Vulnerability.Name, s.Vulnerability.ID),
+			Aliases:         s.Vulnerability.Aliases,
+			Status:          s.Status,
+			Justification:   s.Justification,
+			ImpactStatement: s.ImpactStatement,
+			ActionStatement: s.ActionStatement,
+			Timestamp:       s.Timestamp,
+		}
+		for _, p := range s.Products {
+			statement.Products = append(statement.Products, FirstNonEmpty(p.Identifiers.Purl, p.ID))
+		}
+		vex.Statements = append(vex.Statements, statement)
+	}
+	return
+}
+
+// Validate checks that every statement has a vulnerability, products and a valid
+// status, and that not_affected and affected statements are explained as required
+// by OpenVEX.
+func (v *VEX) Validate() error {
+	for i, s := range v.Statements {
+		switch {
+		case s.Vulnerability == "":
+			return fmt.Errorf("statement %v has no vulnerability", i+1)
+		case len(s.Products) == 0:
+			return fmt.Errorf("statement for %s has no products", s.Vulnerability)
+		case !slices.Contains(VEXStatuses, s.Status):
+			return fmt.Errorf("invalid status %q for %s, valid options are: %s", s.Status, s.Vulnerability, VEXStatuses)
+		case s.Justification != "" && !slices.Contains(VEXJustifications, s.Justification):
+			return fmt.Errorf("invalid justification %q for %s, valid options are: %s", s.Justification, s.Vulnerability, VEXJustifications)
+		case s.Status == VEXNotAffected && s.Justification == "" && s.ImpactStatement == "":
+			return fmt.Errorf("not_affected statement for %s requires a justification or impact", s.Vulnerability)
+		case s.Status == VEXAffected && s.ActionStatement == "":
+			return fmt.Errorf("affected statement for %s requires an action", s.Vulnerability)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the statement applies to the package identified by the PURL.
+func (s VEXStatement) Matches(purl string) bool {
+	for _, product := range s.Products {
+		if productMatches(product, purl) {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the vulnerability identifier and aliases of the statement.
+func (s VEXStatement) Names() []string {
+	return append([]string{s.Vulnerability}, s.Aliases...)
+}
+
+// productMatches compares a product with a PURL. Products may be glob patterns, and
+// products without a version apply to every version of the package.
+func productMatches(product string, purl string) bool {
+	if strings.ContainsAny(product, "*?") {
+		return GlobMatch(product, purl) || GlobMatch(product, CanonicalPurl(purl))
+	}
+	if CanonicalPurl(product) == CanonicalPurl(purl) {
+		return true
+	}
+	p, err := packageurl.FromString(strings.TrimSpace(product))
+	if err != nil || p.Version != "" {
+		return false
+	}
+	versioned, err := packageurl.FromString(strings.TrimSpace(purl))
+	if err != nil {
+		return false
+	}
+	versioned.Version, versioned.Qualifiers, versioned.Subpath = "", nil, ""
+	return CanonicalPurl(product) == versioned.ToString()
+}
+
+// Statement returns the last statement about the vulnerability (matched by
+// identifier or alias) that applies to the package, as later statements supersede
+// earlier ones.
+func (v *VEX) Statement(purl string, vulnerabilities ...string) (statement VEXStatement, ok bool) {
+	for _, s := range v.Statements {
+		if !s.Matches(purl) {
+			continue
+		}
+		for _, name := range s.Names() {
+			if slices.ContainsFunc(vulnerabilities, func(v string) bool { return strings.EqualFold(v, name) }) {
+				statement, ok = s, true
+				break
+			}
+		}
+	}
+	return
+}
+
+// ApplyVEX records the status of every statement that applies to a package in its
+// Notes, returning the number of packages with at least one statement.
+func (k *KissBOM) ApplyVEX(vex VEX) (annotated int) {
+	for i, p := range k.Packages {
+		statuses := []string{}
+		for _, s := range vex.Statements {
+			if s.Matches(p.Purl) {
+				statuses = append(statuses, fmt.Sprintf("%s %s", s.Vulnerability, s.Status))
+			}
+		}
+		if len(statuses) > 0 {
+			k.Packages[i].Notes = strings.TrimSpace(fmt.Sprintf("%s [vex: %s]", p.Notes, strings.Join(statuses, ", ")))
+			annotated++
+		}
+	}
+	return
+}
+
+// OpenVEX produces an OpenVEX document from the statements, where the products of
+// each statement are the PURLs of the KissBOM packages it applies to. Statements that
+// do not apply to any package are omitted. When the VEX has no ID, one is derived
+// from the content of the statements.
+func (v *VEX) OpenVEX(kissbom KissBOM, timestamp string) ([]byte, error) {
+	doc := openVEXDocument{
+		Context:    OpenVEXContext,
+		ID:         v.ID,
+		Author:     FirstNonEmpty(v.Author, "kissbom"),
+		Timestamp:  FirstNonEmpty(v.Timestamp, timestamp),
+		Version:    1,
+		Statements: []openVEXStatement{},
+	}
+	for _, s := range v.Statements {
+		statement := openVEXStatement{
+			Products:        []openVEXProduct{},
+			Status:          s.Status,
+			Justification:   s.Justification,
+			ImpactStatement: s.ImpactStatement,
+			ActionStatement: s.ActionStatement,
+			Timestamp:       s.Timestamp,
+		}
+		statement.Vulnerability.Name = s.Vulnerability
+		statement.Vulnerability.Aliases = s.Aliases
+		for _, p := range kissbom.Packages {
+			if s.Matches(p.Purl) {
+				product := openVEXProduct{ID: p.Purl}
+				product.Identifiers.Purl = p.Purl
+				statement.Products = append(statement.Products, product)
+			}
+		}
+		if len(statement.Products) > 0 {
+			doc.Statements = append(doc.Statements, statement)
+		}
+	}
+
+	if doc.ID == "" {
+		content, err := json.Marshal(doc.Statements)
+		if err != nil {
+			return nil, err
+		}
+		doc.ID = fmt.Sprintf("https://openvex.dev/docs/public/kissbom-%x", sha256.Sum256(content))
+	}
+	return json.MarshalIndent(doc, "", "    ")
+}
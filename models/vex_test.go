@@ -0,0 +1,152 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCycloneDXVEX = `{
+	"bomFormat": "CycloneDX",
+	"specVersion": "1.5",
+	"components": [{"type": "library", "bom-ref": "lodash", "name": "lodash", "purl": "pkg:npm/lodash@4.17.15"}],
+	"vulnerabilities": [
+		{
+			"id": "CVE-2020-8203",
+			"analysis": {"state": "not_affected", "justification": "code_not_reachable", "detail": "merge is never called"},
+			"affects": [{"ref": "lodash"}]
+		},
+		{
+			"id": "CVE-2021-23337",
+			"analysis": {"state": "exploitable", "response": ["update"]},
+			"affects": [{"ref": "urn:cdx:3e671687-395b-41f5-a30f-a58921a69b79/1#pkg:npm/lodash@4.17.15"}]
+		},
+		{
+			"id": "CVE-2000-0001",
+			"affects": [{"ref": "lodash"}]
+		}
+	]
+}`
+
+const testOpenVEX = `{
+	"@context": "https://openvex.dev/ns/v0.2.0",
+	"@id": "https://example.com/vex/1",
+	"author": "Security Team",
+	"timestamp": "2024-01-01T00:00:00Z",
+	"version": 1,
+	"statements": [{
+		"vulnerability": {"name": "CVE-2020-8203"},
+		"products": [{"@id": "pkg:npm/lodash"}],
+		"status": "fixed"
+	}]
+}`
+
+const testVEXStatements = `
+author: Security Team
+statements:
+  - vulnerability: CVE-2020-8203
+    aliases: [GHSA-p6mc-m468-83gw]
+    products: [pkg:npm/lodash@*]
+    status: not_affected
+    justification: vulnerable_code_not_in_execute_path
+  - vulnerability: CVE-2021-23337
+    products: [pkg:npm/lodash@4.17.15]
+    status: affected
+    action: Upgrade to 4.17.21
+`
+
+func TestParseVEX(t *testing.T) {
+	vex, err := ParseVEX([]byte(testCycloneDXVEX))
+	assert.NoError(t, err)
+	assert.Equal(t, []VEXStatement{
+		{
+			Vulnerability:   "CVE-2020-8203",
+			Products:        []string{"pkg:npm/lodash@4.17.15"},
+			Status:          VEXNotAffected,
+			Justification:   "vulnerable_code_not_in_execute_path",
+			ImpactStatement: "merge is never called",
+		},
+		{
+			Vulnerability:   "CVE-2021-23337",
+			Products:        []string{"pkg:npm/lodash@4.17.15"},
+			Status:          VEXAffected,
+			ActionStatement: "update",
+		},
+	}, vex.Statements)
+
+	vex, err = ParseVEX([]byte(testOpenVEX))
+	assert.NoError(t, err)
+	assert.Equal(t, "Security Team", vex.Author)
+	assert.Len(t, vex.Statements, 1)
+	assert.Equal(t, VEXFixed, vex.Statements[0].Status)
+	assert.True(t, vex.Statements[0].Matches("pkg:npm/lodash@4.17.15"))
+	assert.False(t, vex.Statements[0].Matches("pkg:npm/express@4.18.2"))
+
+	vex, err = ParseVEX([]byte(testVEXStatements))
+	assert.NoError(t, err)
+	assert.Len(t, vex.Statements, 2)
+
+	_, err = ParseVEX([]byte("statements:\n  - vulnerability: CVE-1\n    products: [pkg:npm/a@1]\n    status: not_affected\n"))
+	assert.Error(t, err)
+	_, err = ParseVEX([]byte("statements:\n  - vulnerability: CVE-1\n    products: [pkg:npm/a@1]\n    status: unknown\n"))
+	assert.Error(t, err)
+	_, err = ParseVEX([]byte("statements:\n  - vulnerability: CVE-1\n    products: [pkg:npm/a@1]\n    status: affected\n"))
+	assert.Error(t, err)
+}
+
+func TestVEX_Statement(t *testing.T) {
+	vex, err := ParseVEX([]byte(testVEXStatements))
+	assert.NoError(t, err)
+
+	statement, ok := vex.Statement("pkg:npm/lodash@4.17.20", "GHSA-p6mc-m468-83gw")
+	assert.True(t, ok)
+	assert.Equal(t, VEXNotAffected, statement.Status)
+
+	_, ok = vex.Statement("pkg:npm/lodash@4.17.20", "CVE-2021-23337")
+	assert.False(t, ok)
+}
+
+func TestKissBOM_ApplyVEX(t *testing.T) {
+	vex, err := ParseVEX([]byte(testVEXStatements))
+	assert.NoError(t, err)
+
+	kissBOM := KissBOM{
+		Packages: []Package{
+			{Purl: "pkg:npm/lodash@4.17.15", Notes: "utility library"},
+			{Purl: "pkg:npm/express@4.18.2"},
+		},
+	}
+	assert.Equal(t, 1, kissBOM.ApplyVEX(vex))
+	assert.Equal(t, "utility library [vex: CVE-2020-8203 not_affected, CVE-2021-23337 affected]", kissBOM.Packages[0].Notes)
+	assert.Empty(t, kissBOM.Packages[1].Notes)
+}
+
+func TestVEX_OpenVEX(t *testing.T) {
+	vex, err := ParseVEX([]byte(testVEXStatements))
+	assert.NoError(t, err)
+
+	kissBOM := KissBOM{
+		Packages: []Package{
+			{Purl: "pkg:npm/lodash@4.17.21"},
+			{Purl: "pkg:npm/express@4.18.2"},
+		},
+	}
+	data, err := vex.OpenVEX(kissBOM, "2024-01-01T00:00:00Z")
+	assert.NoError(t, err)
+
+	var doc openVEXDocument
+	assert.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, OpenVEXContext, doc.Context)
+	assert.Contains(t, doc.ID, "https://openvex.dev/docs/public/kissbom-")
+	assert.Equal(t, "Security Team", doc.Author)
+	assert.Equal(t, "2024-01-01T00:00:00Z", doc.Timestamp)
+	assert.Len(t, doc.Statements, 1)
+	assert.Equal(t, "CVE-2020-8203", doc.Statements[0].Vulnerability.Name)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", doc.Statements[0].Products[0].ID)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", doc.Statements[0].Products[0].Identifiers.Purl)
+
+	again, err := vex.OpenVEX(kissBOM, "2024-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, data, again)
+}